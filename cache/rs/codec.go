@@ -0,0 +1,53 @@
+package rs
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/monaco-io/lib/codec"
+)
+
+// compressedPrefix 压缩值的前缀，格式为 "\x00" + 算法名 + "\x00" + 压缩数据
+// JSON 文本不会以 \x00 开头，因此可以与未压缩的值区分
+const compressedPrefix = "\x00"
+
+// marshalValue 将缓存值序列化为 JSON，compress 不为空时使用对应的 codec 压缩算法压缩并加上前缀
+func marshalValue[T any](val *T, compress string) (string, error) {
+	if val == nil {
+		return "", nil
+	}
+	b, err := json.Marshal(val)
+	if err != nil {
+		return "", err
+	}
+	if compress == "" {
+		return string(b), nil
+	}
+	if b, err = codec.Compress(compress, b); err != nil {
+		return "", fmt.Errorf("compress %s: %w", compress, err)
+	}
+	return compressedPrefix + compress + compressedPrefix + string(b), nil
+}
+
+// unmarshalValue 解析缓存值，带压缩前缀时按前缀中的算法解压
+// 开启压缩前写入的未压缩数据、切换算法前写入的数据仍然可以正常读取
+func unmarshalValue[T any](val string) (data *T, err error) {
+	if val == "" {
+		return nil, nil
+	}
+	b := []byte(val)
+	if rest, ok := strings.CutPrefix(val, compressedPrefix); ok {
+		name, payload, ok := strings.Cut(rest, compressedPrefix)
+		if !ok {
+			return nil, fmt.Errorf("invalid compressed value")
+		}
+		if b, err = codec.Decompress(name, []byte(payload)); err != nil {
+			return nil, fmt.Errorf("decompress %s: %w", name, err)
+		}
+	}
+	if err = json.Unmarshal(b, &data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
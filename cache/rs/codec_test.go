@@ -0,0 +1,34 @@
+package rs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/monaco-io/lib/codec"
+)
+
+func TestValueCodec(t *testing.T) {
+	// 以 0x78、0x80 等开头的未压缩值不会被误判为压缩数据
+	for _, legacy := range []string{"80", "789", `"x\u009c"`, `{"a":1}`} {
+		got, err := unmarshalValue[any](legacy)
+		if err != nil || got == nil {
+			t.Errorf("unmarshalValue(%q) = %v, %v", legacy, got, err)
+		}
+	}
+
+	val := strings.Repeat("cache ", 100)
+	for _, name := range []string{"", codec.CompressGZip, codec.CompressDeflate, codec.CompressLZW} {
+		str, err := marshalValue(&val, name)
+		if err != nil {
+			t.Fatalf("marshalValue(%s) error = %v", name, err)
+		}
+		// 读取时不依赖当前配置的算法
+		got, err := unmarshalValue[string](str)
+		if err != nil || *got != val {
+			t.Errorf("unmarshalValue(%s) = %v, %v", name, got, err)
+		}
+	}
+	if _, err := unmarshalValue[string](compressedPrefix + "gzip"); err == nil {
+		t.Error("unmarshalValue() expected error for truncated value")
+	}
+}
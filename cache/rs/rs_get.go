@@ -3,7 +3,6 @@ package rs
 import (
 	"context"
 	"database/sql"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -34,10 +33,11 @@ type Getter[T any] func() (*T, error)
 // JSON 结构包含与 Redis 缓存的连接、操作的 key、
 // 数据失效时间和构建缓存数据的 Builder 函数。
 type JSON[T any] struct {
-	Conn   ICache
-	Key    string
-	Expire time.Duration // 默认缓存时间 1 小时
-	Getter Getter[T]
+	Conn     ICache
+	Key      string
+	Expire   time.Duration // 默认缓存时间 1 小时
+	Getter   Getter[T]
+	Compress string // 压缩算法，见 codec.CompressGZip 等，为空不压缩
 }
 
 // Sugar 函数首先尝试从缓存中获得数据；如果数据不存在，
@@ -91,10 +91,7 @@ func (m *JSON[T]) get(ctx context.Context) (data *T, ok bool, err error) {
 		return
 	}
 	ok = true
-	if val == "" {
-		return
-	}
-	if err = json.Unmarshal([]byte(val), &data); err != nil {
+	if data, err = unmarshalValue[T](val); err != nil {
 		err = fmt.Errorf("Model.Get.JsonUnmarshal: %w", err)
 		return
 	}
@@ -106,13 +103,9 @@ func (m *JSON[T]) set(ctx context.Context, val *T) error {
 	if m.Expire == 0 {
 		return errors.New("cache key mush has expire time")
 	}
-	var str string
-	if val != nil {
-		b, err := json.Marshal(val)
-		if err != nil {
-			return fmt.Errorf("val can not format to json: %w", err)
-		}
-		str = string(b)
+	str, err := marshalValue(val, m.Compress)
+	if err != nil {
+		return fmt.Errorf("val can not format to json: %w", err)
 	}
 	if err := m.Conn.Set(ctx, m.Key, str, m.Expire).Err(); err != nil {
 		return fmt.Errorf("Model.Set: %w", err)
@@ -2,7 +2,6 @@ package rs
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -23,6 +22,8 @@ type MGetJson[T, K any] struct {
 	Expire  time.Duration // 过期时间
 	Getter  MGetter[T, K] // 回源方法，如果回源没有找到数据，缓存默认存空字符串
 
+	Compress string // 压缩算法，见 codec.CompressGZip 等，为空不压缩
+
 	keys []string
 }
 
@@ -100,17 +101,15 @@ func (m MGetJson[T, K]) get(ctx context.Context) (hits map[string]*T, miss map[s
 			miss[k] = m.KeysMap[k]
 			continue
 		}
-		var data *T
 		val, ok := v.(string)
 		if !ok {
 			err = fmt.Errorf("MGetJson val not string: %w", err)
 			return
 		}
-		if val != "" {
-			if err = json.Unmarshal([]byte(val), &data); err != nil {
-				err = fmt.Errorf("MGetJson JsonUnmarshal: %w", err)
-				return
-			}
+		data, uErr := unmarshalValue[T](val)
+		if uErr != nil {
+			err = fmt.Errorf("MGetJson JsonUnmarshal: %w", uErr)
+			return
 		}
 		hits[k] = data
 	}
@@ -123,13 +122,9 @@ func (m MGetJson[T, K]) set(ctx context.Context, data map[string]*T) error {
 	}
 	var pairs []string
 	for k, v := range data {
-		var str string
-		if v != nil {
-			b, err := json.Marshal(v)
-			if err != nil {
-				return fmt.Errorf("MGetJson.set val can not format to json: %w", err)
-			}
-			str = string(b)
+		str, err := marshalValue(v, m.Compress)
+		if err != nil {
+			return fmt.Errorf("MGetJson.set val can not format to json: %w", err)
 		}
 		pairs = append(pairs, k, str)
 	}
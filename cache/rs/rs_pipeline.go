@@ -2,7 +2,6 @@ package rs
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -25,6 +24,8 @@ type PipelineGetJson[T, K any] struct {
 	KeysMap map[string]K  // 缓存key map, value是每个key回源需要的参数
 	Expire  time.Duration // 过期时间
 	Getter  MGetter[T, K] // 回源方法，如果回源没有找到数据，缓存默认存空字符串
+
+	Compress string // 压缩算法，见 codec.CompressGZip 等，为空不压缩
 }
 
 var _ pipelineGetRs[any, any] = (*PipelineGetJson[any, any])(nil)
@@ -113,12 +114,10 @@ func (p PipelineGetJson[T, K]) get(ctx context.Context) (hits map[string]*T, mis
 			err = fmt.Errorf("PipelineGetJson result.Result(): %w", rErr)
 			return
 		}
-		var data *T
-		if resStr != "" {
-			if err = json.Unmarshal([]byte(resStr), &data); err != nil {
-				err = fmt.Errorf("PipelineGetJson json.Unmarshal: %w", err)
-				return
-			}
+		data, uErr := unmarshalValue[T](resStr)
+		if uErr != nil {
+			err = fmt.Errorf("PipelineGetJson json.Unmarshal: %w", uErr)
+			return
 		}
 		hits[k] = data
 	}
@@ -132,15 +131,11 @@ func (p PipelineGetJson[T, K]) set(ctx context.Context, data map[string]*T) erro
 	}
 	pipeline := p.Conn.Pipeline()
 	for k, v := range data {
-		var str string
-		if v != nil {
-			b, err := json.Marshal(v)
-			if err != nil {
-				return fmt.Errorf("PipelineGetJson.set val can not format to json: %w", err)
-			}
-			str = string(b)
+		str, err := marshalValue(v, p.Compress)
+		if err != nil {
+			return fmt.Errorf("PipelineGetJson.set val can not format to json: %w", err)
 		}
-		err := pipeline.Set(ctx, k, str, p.Expire).Err()
+		err = pipeline.Set(ctx, k, str, p.Expire).Err()
 		if err != nil {
 			return fmt.Errorf("PipelineGetJson.set pipeline.Set(k, str, p.Expire).Err(): %w", err)
 		}
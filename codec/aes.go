@@ -91,8 +91,36 @@ func (a *aesCipher) Decrypt(ciphertext []byte) ([]byte, error) {
 	}
 }
 
+// EncryptWithAAD 使用附加认证数据加密，仅 GCM 模式支持 aad
+// 解密时必须提供相同的 aad
+func (a *aesCipher) EncryptWithAAD(plaintext, aad []byte) ([]byte, error) {
+	if a.cipherType == GCM {
+		return a.encryptGCMWithAAD(plaintext, aad)
+	}
+	if len(aad) > 0 {
+		return nil, errors.New("additional authenticated data requires GCM mode")
+	}
+	return a.Encrypt(plaintext)
+}
+
+// DecryptWithAAD 使用附加认证数据解密，仅 GCM 模式支持 aad
+func (a *aesCipher) DecryptWithAAD(ciphertext, aad []byte) ([]byte, error) {
+	if a.cipherType == GCM {
+		return a.decryptGCMWithAAD(ciphertext, aad)
+	}
+	if len(aad) > 0 {
+		return nil, errors.New("additional authenticated data requires GCM mode")
+	}
+	return a.Decrypt(ciphertext)
+}
+
 // encryptGCM 使用AES-GCM加密
 func (a *aesCipher) encryptGCM(plaintext []byte) ([]byte, error) {
+	return a.encryptGCMWithAAD(plaintext, nil)
+}
+
+// encryptGCMWithAAD 使用AES-GCM加密，aad 参与认证但不加密
+func (a *aesCipher) encryptGCMWithAAD(plaintext, aad []byte) ([]byte, error) {
 	aesGCM, err := cipher.NewGCM(a.block)
 	if err != nil {
 		return nil, err
@@ -103,12 +131,17 @@ func (a *aesCipher) encryptGCM(plaintext []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	ciphertext := aesGCM.Seal(nonce, nonce, plaintext, nil)
+	ciphertext := aesGCM.Seal(nonce, nonce, plaintext, aad)
 	return ciphertext, nil
 }
 
 // decryptGCM 使用AES-GCM解密
 func (a *aesCipher) decryptGCM(ciphertext []byte) ([]byte, error) {
+	return a.decryptGCMWithAAD(ciphertext, nil)
+}
+
+// decryptGCMWithAAD 使用AES-GCM解密并校验 aad
+func (a *aesCipher) decryptGCMWithAAD(ciphertext, aad []byte) ([]byte, error) {
 	aesGCM, err := cipher.NewGCM(a.block)
 	if err != nil {
 		return nil, err
@@ -120,7 +153,7 @@ func (a *aesCipher) decryptGCM(ciphertext []byte) ([]byte, error) {
 	}
 
 	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plaintext, err := aesGCM.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := aesGCM.Open(nil, nonce, ciphertext, aad)
 	if err != nil {
 		return nil, err
 	}
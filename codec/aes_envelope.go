@@ -0,0 +1,200 @@
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+/**
+AES 信封格式，在密文前加上版本、加密模式和密钥 ID，便于密钥轮换：
+
+| magic 'A' 'E' (2) | version (1) | mode (1) | kid 长度 (1) | kid | aesCipher 密文 |
+
+GCM 模式下整个信封头和调用方提供的 aad 一起作为附加认证数据，篡改头部会导致解密失败。
+CBC/CTR 模式没有完整性校验，也不支持 aad。
+**/
+
+// AESEnvelopeVersion 当前信封格式版本
+const AESEnvelopeVersion byte = 1
+
+var aesEnvelopeMagic = [2]byte{'A', 'E'}
+
+var (
+	// ErrAESEnvelope 数据不是合法的 AES 信封
+	ErrAESEnvelope = errors.New("lib.codec:invalid aes envelope")
+	// ErrAESKeyNotFound 密钥环中没有信封对应的密钥
+	ErrAESKeyNotFound = errors.New("lib.codec:aes key not found")
+)
+
+// AESEnvelopeHeader AES 信封头
+type AESEnvelopeHeader struct {
+	Version byte
+	Mode    AESCipherType
+	KeyID   string
+}
+
+// Marshal 序列化信封头
+func (h AESEnvelopeHeader) Marshal() ([]byte, error) {
+	if len(h.KeyID) == 0 || len(h.KeyID) > 255 {
+		return nil, fmt.Errorf("invalid key id length: %d, must be between 1 and 255", len(h.KeyID))
+	}
+	b := make([]byte, 0, 5+len(h.KeyID))
+	b = append(b, aesEnvelopeMagic[:]...)
+	b = append(b, h.Version, byte(h.Mode), byte(len(h.KeyID)))
+	return append(b, h.KeyID...), nil
+}
+
+// ParseAESEnvelope 解析信封头，返回头部、头部原始字节和密文
+func ParseAESEnvelope(data []byte) (header *AESEnvelopeHeader, raw, ciphertext []byte, err error) {
+	if len(data) < 5 || data[0] != aesEnvelopeMagic[0] || data[1] != aesEnvelopeMagic[1] {
+		return nil, nil, nil, ErrAESEnvelope
+	}
+	if data[2] != AESEnvelopeVersion {
+		return nil, nil, nil, fmt.Errorf("%w: unsupported version %d", ErrAESEnvelope, data[2])
+	}
+	kidLen := int(data[4])
+	if kidLen == 0 || len(data) < 5+kidLen {
+		return nil, nil, nil, fmt.Errorf("%w: truncated key id", ErrAESEnvelope)
+	}
+	header = &AESEnvelopeHeader{
+		Version: data[2],
+		Mode:    AESCipherType(data[3]),
+		KeyID:   string(data[5 : 5+kidLen]),
+	}
+	return header, data[:5+kidLen], data[5+kidLen:], nil
+}
+
+// AESKeyring 密钥环，使用当前激活的密钥加密，使用任意已注册的密钥解密
+type AESKeyring struct {
+	mu     sync.RWMutex
+	keys   map[string]*aesCipher
+	active string
+}
+
+var _ CodecAES = (*AESKeyring)(nil)
+
+// NewAESKeyring 创建空的密钥环，第一个添加的密钥自动成为激活密钥
+func NewAESKeyring() *AESKeyring {
+	return &AESKeyring{keys: map[string]*aesCipher{}}
+}
+
+// Add 注册密钥，kid 长度为 1~255 字节
+func (k *AESKeyring) Add(kid, key string, cipherType AESCipherType) error {
+	if len(kid) == 0 || len(kid) > 255 {
+		return fmt.Errorf("invalid key id length: %d, must be between 1 and 255", len(kid))
+	}
+	c, err := NewAESCipher(key, cipherType)
+	if err != nil {
+		return err
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[kid] = c
+	if k.active == "" {
+		k.active = kid
+	}
+	return nil
+}
+
+// SetActive 设置加密使用的密钥
+func (k *AESKeyring) SetActive(kid string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if _, ok := k.keys[kid]; !ok {
+		return fmt.Errorf("%w: %s", ErrAESKeyNotFound, kid)
+	}
+	k.active = kid
+	return nil
+}
+
+// Rotate 注册新密钥并设为激活密钥，旧密钥保留用于解密
+func (k *AESKeyring) Rotate(kid, key string, cipherType AESCipherType) error {
+	if err := k.Add(kid, key, cipherType); err != nil {
+		return err
+	}
+	return k.SetActive(kid)
+}
+
+// Remove 移除不再使用的密钥，激活密钥不能移除
+func (k *AESKeyring) Remove(kid string) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if kid == k.active {
+		return fmt.Errorf("cannot remove active key: %s", kid)
+	}
+	delete(k.keys, kid)
+	return nil
+}
+
+// ActiveKeyID 当前激活的密钥 ID
+func (k *AESKeyring) ActiveKeyID() string {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.active
+}
+
+// KeyID 返回密文使用的密钥 ID，可用于判断是否需要重新加密
+func (k *AESKeyring) KeyID(data []byte) (string, error) {
+	header, _, _, err := ParseAESEnvelope(data)
+	if err != nil {
+		return "", err
+	}
+	return header.KeyID, nil
+}
+
+// Encrypt 使用激活密钥加密
+func (k *AESKeyring) Encrypt(plaintext []byte) ([]byte, error) {
+	return k.EncryptWithAAD(plaintext, nil)
+}
+
+// Decrypt 根据信封中的密钥 ID 解密
+func (k *AESKeyring) Decrypt(data []byte) ([]byte, error) {
+	return k.DecryptWithAAD(data, nil)
+}
+
+// EncryptWithAAD 使用激活密钥加密，aad 仅 GCM 模式支持
+func (k *AESKeyring) EncryptWithAAD(plaintext, aad []byte) ([]byte, error) {
+	k.mu.RLock()
+	kid, c := k.active, k.keys[k.active]
+	k.mu.RUnlock()
+	if c == nil {
+		return nil, fmt.Errorf("%w: no active key", ErrAESKeyNotFound)
+	}
+
+	header, err := AESEnvelopeHeader{Version: AESEnvelopeVersion, Mode: c.cipherType, KeyID: kid}.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := c.EncryptWithAAD(plaintext, envelopeAAD(c.cipherType, header, aad))
+	if err != nil {
+		return nil, err
+	}
+	return append(header, ciphertext...), nil
+}
+
+// DecryptWithAAD 根据信封中的密钥 ID 解密，aad 必须与加密时一致
+func (k *AESKeyring) DecryptWithAAD(data, aad []byte) ([]byte, error) {
+	header, raw, ciphertext, err := ParseAESEnvelope(data)
+	if err != nil {
+		return nil, err
+	}
+	k.mu.RLock()
+	c := k.keys[header.KeyID]
+	k.mu.RUnlock()
+	if c == nil {
+		return nil, fmt.Errorf("%w: %s", ErrAESKeyNotFound, header.KeyID)
+	}
+	if c.cipherType != header.Mode {
+		return nil, fmt.Errorf("%w: cipher mode mismatch for key %s", ErrAESEnvelope, header.KeyID)
+	}
+	return c.DecryptWithAAD(ciphertext, envelopeAAD(c.cipherType, raw, aad))
+}
+
+// envelopeAAD GCM 模式下将信封头绑定到认证数据中
+func envelopeAAD(mode AESCipherType, header, aad []byte) []byte {
+	if mode != GCM {
+		return aad
+	}
+	return append(append(make([]byte, 0, len(header)+len(aad)), header...), aad...)
+}
@@ -0,0 +1,129 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestAESCipher_AAD(t *testing.T) {
+	cipher, err := NewAESCipher("mysecretkey12345", GCM)
+	if err != nil {
+		t.Fatalf("Failed to create AES cipher: %v", err)
+	}
+
+	encrypted, err := cipher.EncryptWithAAD([]byte("secret"), []byte("user:42"))
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	decrypted, err := cipher.DecryptWithAAD(encrypted, []byte("user:42"))
+	if err != nil || string(decrypted) != "secret" {
+		t.Fatalf("DecryptWithAAD() = %q, %v", decrypted, err)
+	}
+	if _, err := cipher.DecryptWithAAD(encrypted, []byte("user:43")); err == nil {
+		t.Error("Expected error for mismatched AAD, got nil")
+	}
+
+	cbc, _ := NewAESCipher("mysecretkey12345", CBC)
+	if _, err := cbc.EncryptWithAAD([]byte("secret"), []byte("aad")); err == nil {
+		t.Error("Expected error for AAD in CBC mode, got nil")
+	}
+}
+
+func TestAESKeyring_Rotation(t *testing.T) {
+	keyring := NewAESKeyring()
+	if err := keyring.Add("2024-01", "mysecretkey12345", GCM); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	oldCiphertext, err := keyring.Encrypt([]byte("old data"))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	if err := keyring.Rotate("2024-07", "anothersecretkey0123456789abcdef", GCM); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	newCiphertext, err := keyring.Encrypt([]byte("new data"))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+
+	for kid, data := range map[string][]byte{"2024-01": oldCiphertext, "2024-07": newCiphertext} {
+		got, err := keyring.KeyID(data)
+		if err != nil || got != kid {
+			t.Errorf("KeyID() = %q, %v, want %q", got, err, kid)
+		}
+	}
+
+	for want, data := range map[string][]byte{"old data": oldCiphertext, "new data": newCiphertext} {
+		got, err := keyring.Decrypt(data)
+		if err != nil || string(got) != want {
+			t.Errorf("Decrypt() = %q, %v, want %q", got, err, want)
+		}
+	}
+
+	if err := keyring.Remove("2024-07"); err == nil {
+		t.Error("Remove() expected error for active key")
+	}
+	if err := keyring.Remove("2024-01"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if _, err := keyring.Decrypt(oldCiphertext); !errors.Is(err, ErrAESKeyNotFound) {
+		t.Errorf("Decrypt() error = %v, want ErrAESKeyNotFound", err)
+	}
+}
+
+func TestAESKeyring_Modes(t *testing.T) {
+	for _, mode := range []AESCipherType{GCM, CBC, CTR} {
+		t.Run(getCipherTypeName(mode), func(t *testing.T) {
+			keyring := NewAESKeyring()
+			if err := keyring.Add("k1", "mysecretkey12345", mode); err != nil {
+				t.Fatalf("Add() error = %v", err)
+			}
+			encrypted, err := keyring.Encrypt([]byte("payload"))
+			if err != nil {
+				t.Fatalf("Encrypt() error = %v", err)
+			}
+			header, _, _, err := ParseAESEnvelope(encrypted)
+			if err != nil {
+				t.Fatalf("ParseAESEnvelope() error = %v", err)
+			}
+			if header.Version != AESEnvelopeVersion || header.Mode != mode || header.KeyID != "k1" {
+				t.Errorf("ParseAESEnvelope() = %+v", header)
+			}
+			decrypted, err := keyring.Decrypt(encrypted)
+			if err != nil || !bytes.Equal(decrypted, []byte("payload")) {
+				t.Errorf("Decrypt() = %q, %v", decrypted, err)
+			}
+		})
+	}
+}
+
+func TestAESKeyring_TamperedHeader(t *testing.T) {
+	keyring := NewAESKeyring()
+	_ = keyring.Add("k1", "mysecretkey12345", GCM)
+	_ = keyring.Add("k2", "mysecretkey12345", GCM)
+
+	encrypted, err := keyring.EncryptWithAAD([]byte("payload"), []byte("ctx"))
+	if err != nil {
+		t.Fatalf("EncryptWithAAD() error = %v", err)
+	}
+	if _, err := keyring.DecryptWithAAD(encrypted, []byte("other")); err == nil {
+		t.Error("DecryptWithAAD() expected error for mismatched AAD")
+	}
+
+	// 同一把密钥，仅修改头部中的 kid，GCM 认证应失败
+	tampered := append([]byte{}, encrypted...)
+	tampered[6] = '2'
+	if _, err := keyring.DecryptWithAAD(tampered, []byte("ctx")); err == nil {
+		t.Error("DecryptWithAAD() expected error for tampered header")
+	}
+
+	if _, err := keyring.Decrypt([]byte("garbage")); !errors.Is(err, ErrAESEnvelope) {
+		t.Errorf("Decrypt() error = %v, want ErrAESEnvelope", err)
+	}
+	if _, err := NewAESKeyring().Encrypt([]byte("x")); !errors.Is(err, ErrAESKeyNotFound) {
+		t.Errorf("Encrypt() error = %v, want ErrAESKeyNotFound", err)
+	}
+}
@@ -0,0 +1,337 @@
+package codec
+
+import (
+	"bytes"
+	"crypto/hkdf"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/monaco-io/lib/typing/xopt"
+	"golang.org/x/crypto/scrypt"
+)
+
+/**
+基于口令派生密钥的 AES 加密，盐和派生参数写在密文头部，解密方只需要口令：
+
+| magic 'A' 'K' (2) | version (1) | kdf (1) | mode (1) | 密钥长度 (1) | 派生参数 | 盐长度 (1) | 盐 | aesCipher 密文 |
+
+派生参数：
+PBKDF2-SHA256：迭代次数 uint32
+scrypt：log2(N) uint8，r uint32，p uint32
+HKDF-SHA256：info 长度 uint16，info
+
+GCM 模式下头部作为附加认证数据，篡改参数会导致解密失败。
+**/
+
+// KDF 密钥派生算法
+type KDF byte
+
+const (
+	// KDFPBKDF2 PBKDF2-HMAC-SHA256，适用于口令
+	KDFPBKDF2 KDF = iota + 1
+	// KDFScrypt scrypt，适用于口令，内存困难
+	KDFScrypt
+	// KDFHKDF HKDF-SHA256，适用于本身已是高熵的主密钥，不适用于口令
+	KDFHKDF
+)
+
+// AESKDFVersion 当前口令加密格式版本
+const AESKDFVersion byte = 1
+
+var aesKDFMagic = [2]byte{'A', 'K'}
+
+// 派生参数上限，解密时参数来自密文头部，上限保证单次派生的开销可控：
+// PBKDF2 不超过 200 万次迭代，scrypt 内存 (128·r·N) 不超过 1GiB
+const (
+	maxPBKDF2Iterations = 2_000_000
+	maxScryptLogN       = 20
+	maxScryptR          = 16
+	maxScryptP          = 4
+	maxScryptMemory     = 1 << 30
+)
+
+// ErrAESKDFHeader 数据不是合法的口令加密格式
+var ErrAESKDFHeader = errors.New("lib.codec:invalid aes kdf header")
+
+type kdfConfig struct {
+	kdf        KDF
+	keyLen     int
+	saltLen    int
+	iterations int
+	scryptLogN int
+	scryptR    int
+	scryptP    int
+	info       string
+}
+
+func newKDFConfig(opts ...xopt.Option[kdfConfig]) kdfConfig {
+	cfg := kdfConfig{
+		kdf:        KDFPBKDF2,
+		keyLen:     32,
+		saltLen:    16,
+		iterations: 600_000,
+		scryptLogN: 15,
+		scryptR:    8,
+		scryptP:    1,
+	}
+	xopt.Apply(opts, &cfg)
+	return cfg
+}
+
+// WithKDF 指定密钥派生算法，默认为 KDFPBKDF2
+func WithKDF(kdf KDF) xopt.Option[kdfConfig] {
+	return func(cfg *kdfConfig) {
+		cfg.kdf = kdf
+	}
+}
+
+// WithKDFKeyLength 派生的 AES 密钥长度，16、24 或 32，默认 32
+func WithKDFKeyLength(n int) xopt.Option[kdfConfig] {
+	return func(cfg *kdfConfig) {
+		cfg.keyLen = n
+	}
+}
+
+// WithKDFSaltLength 随机盐长度，默认 16
+func WithKDFSaltLength(n int) xopt.Option[kdfConfig] {
+	return func(cfg *kdfConfig) {
+		cfg.saltLen = n
+	}
+}
+
+// WithPBKDF2Iterations PBKDF2 迭代次数，默认 600000
+func WithPBKDF2Iterations(n int) xopt.Option[kdfConfig] {
+	return func(cfg *kdfConfig) {
+		cfg.iterations = n
+	}
+}
+
+// WithScryptParams scrypt 参数，N = 2^logN，默认 logN=15, r=8, p=1
+func WithScryptParams(logN, r, p int) xopt.Option[kdfConfig] {
+	return func(cfg *kdfConfig) {
+		cfg.scryptLogN = logN
+		cfg.scryptR = r
+		cfg.scryptP = p
+	}
+}
+
+// WithHKDFInfo HKDF 的上下文信息，用于从同一主密钥派生不同用途的密钥
+func WithHKDFInfo(info string) xopt.Option[kdfConfig] {
+	return func(cfg *kdfConfig) {
+		cfg.info = info
+	}
+}
+
+func (cfg kdfConfig) validate() error {
+	if cfg.keyLen != 16 && cfg.keyLen != 24 && cfg.keyLen != 32 {
+		return fmt.Errorf("invalid key length: %d, must be 16, 24, or 32 bytes", cfg.keyLen)
+	}
+	if cfg.saltLen < 8 || cfg.saltLen > 255 {
+		return fmt.Errorf("invalid salt length: %d, must be between 8 and 255 bytes", cfg.saltLen)
+	}
+	switch cfg.kdf {
+	case KDFPBKDF2:
+		if cfg.iterations < 1 || cfg.iterations > maxPBKDF2Iterations {
+			return fmt.Errorf("invalid pbkdf2 iterations: %d", cfg.iterations)
+		}
+	case KDFScrypt:
+		if cfg.scryptLogN < 1 || cfg.scryptLogN > maxScryptLogN ||
+			cfg.scryptR < 1 || cfg.scryptR > maxScryptR ||
+			cfg.scryptP < 1 || cfg.scryptP > maxScryptP ||
+			128*cfg.scryptR<<cfg.scryptLogN > maxScryptMemory {
+			return fmt.Errorf("invalid scrypt params: logN=%d r=%d p=%d", cfg.scryptLogN, cfg.scryptR, cfg.scryptP)
+		}
+	case KDFHKDF:
+		if len(cfg.info) > 0xffff {
+			return fmt.Errorf("hkdf info too long: %d", len(cfg.info))
+		}
+	default:
+		return fmt.Errorf("unsupported kdf: %d", cfg.kdf)
+	}
+	return nil
+}
+
+func (cfg kdfConfig) derive(password string, salt []byte) ([]byte, error) {
+	switch cfg.kdf {
+	case KDFPBKDF2:
+		return pbkdf2.Key(sha256.New, password, salt, cfg.iterations, cfg.keyLen)
+	case KDFScrypt:
+		return scrypt.Key([]byte(password), salt, 1<<cfg.scryptLogN, cfg.scryptR, cfg.scryptP, cfg.keyLen)
+	case KDFHKDF:
+		return hkdf.Key(sha256.New, []byte(password), salt, cfg.info, cfg.keyLen)
+	default:
+		return nil, fmt.Errorf("unsupported kdf: %d", cfg.kdf)
+	}
+}
+
+// DeriveAESKey 使用指定的派生算法从口令和盐派生 AES 密钥
+func DeriveAESKey(password string, salt []byte, opts ...xopt.Option[kdfConfig]) ([]byte, error) {
+	cfg := newKDFConfig(opts...)
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg.derive(password, salt)
+}
+
+// passwordCipher 基于口令派生密钥的 AES 加密器
+type passwordCipher struct {
+	password   string
+	cipherType AESCipherType
+	cfg        kdfConfig
+	header     []byte
+	cipher     *aesCipher
+}
+
+var _ CodecAES = (*passwordCipher)(nil)
+
+// NewAESCipherWithPassword 创建基于口令的 AES 加密器
+// 创建时生成随机盐并派生一次密钥，同一加密器加密的数据共享同一个盐
+// 解密时从密文头部读取盐和参数重新派生密钥，只需要口令即可
+func NewAESCipherWithPassword(password string, cipherType AESCipherType, opts ...xopt.Option[kdfConfig]) (*passwordCipher, error) {
+	if password == "" {
+		return nil, errors.New("password cannot be empty")
+	}
+	cfg := newKDFConfig(opts...)
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, cfg.saltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	c, err := newKDFCipher(password, cipherType, cfg, salt)
+	if err != nil {
+		return nil, err
+	}
+	return &passwordCipher{
+		password:   password,
+		cipherType: cipherType,
+		cfg:        cfg,
+		header:     marshalKDFHeader(cipherType, cfg, salt),
+		cipher:     c,
+	}, nil
+}
+
+func newKDFCipher(password string, cipherType AESCipherType, cfg kdfConfig, salt []byte) (*aesCipher, error) {
+	key, err := cfg.derive(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	return NewAESCipher(string(key), cipherType)
+}
+
+// Encrypt 加密并在密文前写入派生参数
+func (p *passwordCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	ciphertext, err := p.cipher.EncryptWithAAD(plaintext, kdfAAD(p.cipherType, p.header))
+	if err != nil {
+		return nil, err
+	}
+	return append(append(make([]byte, 0, len(p.header)+len(ciphertext)), p.header...), ciphertext...), nil
+}
+
+// Decrypt 读取密文头部的派生参数并解密
+func (p *passwordCipher) Decrypt(data []byte) ([]byte, error) {
+	mode, cfg, salt, header, ciphertext, err := parseKDFHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	if mode != p.cipherType {
+		return nil, fmt.Errorf("%w: cipher mode mismatch", ErrAESKDFHeader)
+	}
+	c := p.cipher
+	if !bytes.Equal(header, p.header) {
+		if c, err = newKDFCipher(p.password, mode, cfg, salt); err != nil {
+			return nil, err
+		}
+	}
+	return c.DecryptWithAAD(ciphertext, kdfAAD(mode, header))
+}
+
+func kdfAAD(mode AESCipherType, header []byte) []byte {
+	if mode != GCM {
+		return nil
+	}
+	return header
+}
+
+func marshalKDFHeader(mode AESCipherType, cfg kdfConfig, salt []byte) []byte {
+	b := append([]byte{}, aesKDFMagic[:]...)
+	b = append(b, AESKDFVersion, byte(cfg.kdf), byte(mode), byte(cfg.keyLen))
+	switch cfg.kdf {
+	case KDFPBKDF2:
+		b = binary.BigEndian.AppendUint32(b, uint32(cfg.iterations))
+	case KDFScrypt:
+		b = append(b, byte(cfg.scryptLogN))
+		b = binary.BigEndian.AppendUint32(b, uint32(cfg.scryptR))
+		b = binary.BigEndian.AppendUint32(b, uint32(cfg.scryptP))
+	case KDFHKDF:
+		b = binary.BigEndian.AppendUint16(b, uint16(len(cfg.info)))
+		b = append(b, cfg.info...)
+	}
+	b = append(b, byte(len(salt)))
+	return append(b, salt...)
+}
+
+func parseKDFHeader(data []byte) (mode AESCipherType, cfg kdfConfig, salt, header, ciphertext []byte, err error) {
+	r := bytes.NewReader(data)
+	var fixed [6]byte
+	if _, err = io.ReadFull(r, fixed[:]); err != nil || fixed[0] != aesKDFMagic[0] || fixed[1] != aesKDFMagic[1] {
+		err = ErrAESKDFHeader
+		return
+	}
+	if fixed[2] != AESKDFVersion {
+		err = fmt.Errorf("%w: unsupported version %d", ErrAESKDFHeader, fixed[2])
+		return
+	}
+	cfg = kdfConfig{kdf: KDF(fixed[3]), keyLen: int(fixed[5])}
+	mode = AESCipherType(fixed[4])
+
+	var u32 uint32
+	switch cfg.kdf {
+	case KDFPBKDF2:
+		err = binary.Read(r, binary.BigEndian, &u32)
+		cfg.iterations = int(u32)
+	case KDFScrypt:
+		var logN byte
+		var rp [2]uint32
+		if logN, err = r.ReadByte(); err == nil {
+			err = binary.Read(r, binary.BigEndian, &rp)
+		}
+		cfg.scryptLogN, cfg.scryptR, cfg.scryptP = int(logN), int(rp[0]), int(rp[1])
+	case KDFHKDF:
+		var n uint16
+		if err = binary.Read(r, binary.BigEndian, &n); err == nil {
+			info := make([]byte, n)
+			_, err = io.ReadFull(r, info)
+			cfg.info = string(info)
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("%w: truncated params", ErrAESKDFHeader)
+		return
+	}
+
+	saltLen, err := r.ReadByte()
+	if err != nil {
+		err = fmt.Errorf("%w: truncated salt", ErrAESKDFHeader)
+		return
+	}
+	cfg.saltLen = int(saltLen)
+	salt = make([]byte, saltLen)
+	if _, err = io.ReadFull(r, salt); err != nil {
+		err = fmt.Errorf("%w: truncated salt", ErrAESKDFHeader)
+		return
+	}
+	if err = cfg.validate(); err != nil {
+		err = fmt.Errorf("%w: %w", ErrAESKDFHeader, err)
+		return
+	}
+	offset := len(data) - r.Len()
+	return mode, cfg, salt, data[:offset], data[offset:], nil
+}
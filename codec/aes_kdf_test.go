@@ -0,0 +1,140 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+func TestAESCipherWithPassword(t *testing.T) {
+	tests := []struct {
+		name string
+		mode AESCipherType
+		opts []xopt.Option[kdfConfig]
+	}{
+		{"PBKDF2-GCM", GCM, []xopt.Option[kdfConfig]{WithKDF(KDFPBKDF2), WithPBKDF2Iterations(1000)}},
+		{"PBKDF2-CBC", CBC, []xopt.Option[kdfConfig]{WithKDF(KDFPBKDF2), WithPBKDF2Iterations(1000), WithKDFKeyLength(16)}},
+		{"Scrypt-GCM", GCM, []xopt.Option[kdfConfig]{WithKDF(KDFScrypt), WithScryptParams(10, 8, 1)}},
+		{"HKDF-CTR", CTR, []xopt.Option[kdfConfig]{WithKDF(KDFHKDF), WithHKDFInfo("orders"), WithKDFKeyLength(24)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encryptor, err := NewAESCipherWithPassword("correct horse battery staple", tt.mode, tt.opts...)
+			if err != nil {
+				t.Fatalf("NewAESCipherWithPassword() error = %v", err)
+			}
+			encrypted, err := encryptor.Encrypt([]byte("top secret"))
+			if err != nil {
+				t.Fatalf("Encrypt() error = %v", err)
+			}
+
+			// 解密方只知道口令和模式，派生参数从密文头部读取
+			decryptor, err := NewAESCipherWithPassword("correct horse battery staple", tt.mode, WithPBKDF2Iterations(1000))
+			if err != nil {
+				t.Fatalf("NewAESCipherWithPassword() error = %v", err)
+			}
+			decrypted, err := decryptor.Decrypt(encrypted)
+			if err != nil {
+				t.Fatalf("Decrypt() error = %v", err)
+			}
+			if !bytes.Equal(decrypted, []byte("top secret")) {
+				t.Errorf("Decrypt() = %q", decrypted)
+			}
+
+			if tt.mode == GCM {
+				wrong, _ := NewAESCipherWithPassword("wrong password", tt.mode, WithPBKDF2Iterations(1000))
+				if _, err := wrong.Decrypt(encrypted); err == nil {
+					t.Error("Decrypt() expected error for wrong password")
+				}
+			}
+		})
+	}
+}
+
+func TestAESCipherWithPassword_TamperedHeader(t *testing.T) {
+	c, err := NewAESCipherWithPassword("password", GCM, WithPBKDF2Iterations(1000))
+	if err != nil {
+		t.Fatalf("NewAESCipherWithPassword() error = %v", err)
+	}
+	encrypted, _ := c.Encrypt([]byte("data"))
+
+	// 修改迭代次数，GCM 认证失败
+	tampered := append([]byte{}, encrypted...)
+	tampered[9] ^= 0x01
+	if _, err := c.Decrypt(tampered); err == nil {
+		t.Error("Decrypt() expected error for tampered params")
+	}
+
+	// 过大的迭代次数直接拒绝
+	huge := append([]byte{}, encrypted...)
+	huge[6] = 0xff
+	if _, err := c.Decrypt(huge); !errors.Is(err, ErrAESKDFHeader) {
+		t.Errorf("Decrypt() error = %v, want ErrAESKDFHeader", err)
+	}
+
+	if _, err := c.Decrypt([]byte("AK")); !errors.Is(err, ErrAESKDFHeader) {
+		t.Errorf("Decrypt() error = %v, want ErrAESKDFHeader", err)
+	}
+}
+
+func TestAESCipherWithPassword_OversizedParams(t *testing.T) {
+	c, err := NewAESCipherWithPassword("password", GCM, WithKDF(KDFScrypt), WithScryptParams(10, 8, 1))
+	if err != nil {
+		t.Fatalf("NewAESCipherWithPassword() error = %v", err)
+	}
+	encrypted, _ := c.Encrypt([]byte("data"))
+	salt := make([]byte, 16)
+	tests := []struct {
+		name string
+		cfg  kdfConfig
+	}{
+		{"pbkdf2 iterations", kdfConfig{kdf: KDFPBKDF2, keyLen: 32, iterations: maxPBKDF2Iterations + 1}},
+		{"scrypt logN", kdfConfig{kdf: KDFScrypt, keyLen: 32, scryptLogN: 22, scryptR: 8, scryptP: 1}},
+		{"scrypt r", kdfConfig{kdf: KDFScrypt, keyLen: 32, scryptLogN: 10, scryptR: 32, scryptP: 1}},
+		{"scrypt p", kdfConfig{kdf: KDFScrypt, keyLen: 32, scryptLogN: 10, scryptR: 8, scryptP: 16}},
+		// 单项不超限，但内存 128·16·2^20 = 2GiB 超限
+		{"scrypt memory", kdfConfig{kdf: KDFScrypt, keyLen: 32, scryptLogN: 20, scryptR: 16, scryptP: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 替换头部为攻击者构造的参数，应在派生密钥前拒绝
+			forged := append(marshalKDFHeader(GCM, tt.cfg, salt), encrypted[len(c.header):]...)
+			if _, err := c.Decrypt(forged); !errors.Is(err, ErrAESKDFHeader) {
+				t.Errorf("Decrypt() error = %v, want ErrAESKDFHeader", err)
+			}
+		})
+	}
+}
+
+func TestDeriveAESKey(t *testing.T) {
+	salt := []byte("0123456789abcdef")
+	k1, err := DeriveAESKey("password", salt, WithPBKDF2Iterations(1000))
+	if err != nil {
+		t.Fatalf("DeriveAESKey() error = %v", err)
+	}
+	k2, _ := DeriveAESKey("password", salt, WithPBKDF2Iterations(1000))
+	if len(k1) != 32 || !bytes.Equal(k1, k2) {
+		t.Errorf("DeriveAESKey() should be deterministic 32 bytes, got %d", len(k1))
+	}
+	if _, err := NewAESCipher(string(k1), GCM); err != nil {
+		t.Errorf("derived key should be usable by NewAESCipher: %v", err)
+	}
+
+	invalid := []xopt.Option[kdfConfig]{
+		WithKDFKeyLength(20),
+		WithKDFSaltLength(4),
+		WithPBKDF2Iterations(0),
+		WithKDF(KDF(99)),
+	}
+	for _, opt := range invalid {
+		if _, err := NewAESCipherWithPassword("password", GCM, opt); err == nil {
+			t.Error("NewAESCipherWithPassword() expected error for invalid option")
+		}
+	}
+	if _, err := NewAESCipherWithPassword("", GCM); err == nil {
+		t.Error("NewAESCipherWithPassword() expected error for empty password")
+	}
+}
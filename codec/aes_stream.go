@@ -0,0 +1,277 @@
+package codec
+
+import (
+	"bufio"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+/**
+分块流式 AES-GCM，适用于无法一次性载入内存的大文件：
+
+头部：| magic 'A' 'S' (2) | version (1) | 分块大小 uint32 (4) | nonce 前缀 (7) |
+数据：每个分块独立加密，非最后一块的明文长度固定为分块大小，密文多 16 字节认证标签
+
+分块 nonce = nonce 前缀 (7) || 分块序号 uint32 (4) || 最后一块标记 (1)
+头部作为每个分块的附加认证数据。
+序号保证分块不能被重排，最后一块标记保证密文不能被截断。
+**/
+
+// AESStreamVersion 当前流式加密格式版本
+const AESStreamVersion byte = 1
+
+const (
+	// DefaultAESStreamChunkSize 默认分块大小 64KB
+	DefaultAESStreamChunkSize = 64 * 1024
+	// MaxAESStreamChunkSize 分块大小上限 16MB
+	MaxAESStreamChunkSize = 16 * 1024 * 1024
+
+	aesStreamPrefixSize = 7
+	aesStreamHeaderSize = 2 + 1 + 4 + aesStreamPrefixSize
+)
+
+var aesStreamMagic = [2]byte{'A', 'S'}
+
+var (
+	// ErrAESStreamHeader 数据不是合法的流式加密格式
+	ErrAESStreamHeader = errors.New("lib.codec:invalid aes stream header")
+	// ErrAESStreamTruncated 密文被截断，缺少最后一块
+	ErrAESStreamTruncated = errors.New("lib.codec:aes stream truncated")
+	// ErrAESStreamAuth 分块认证失败，密文被篡改或密钥错误
+	ErrAESStreamAuth = errors.New("lib.codec:aes stream authentication failed")
+)
+
+type streamConfig struct {
+	chunkSize int
+}
+
+// WithChunkSize 设置流式加密的分块大小，默认 64KB
+func WithChunkSize(n int) xopt.Option[streamConfig] {
+	return func(cfg *streamConfig) {
+		cfg.chunkSize = n
+	}
+}
+
+// newStreamAEAD 使用与 NewAESCipher 相同的密钥规则创建 GCM
+func newStreamAEAD(key string) (cipher.AEAD, error) {
+	c, err := NewAESCipher(key, GCM)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(c.block)
+}
+
+// streamNonce 根据前缀、序号和最后一块标记生成分块 nonce
+func streamNonce(prefix []byte, counter uint32, final bool) []byte {
+	nonce := make([]byte, 0, 12)
+	nonce = append(nonce, prefix...)
+	nonce = binary.BigEndian.AppendUint32(nonce, counter)
+	if final {
+		return append(nonce, 1)
+	}
+	return append(nonce, 0)
+}
+
+// encryptWriter 流式加密写入器
+type encryptWriter struct {
+	w       io.Writer
+	aead    cipher.AEAD
+	header  []byte
+	prefix  []byte
+	counter uint32
+	buf     []byte
+	out     []byte
+	closed  bool
+	err     error
+}
+
+// NewEncryptWriter 创建流式加密写入器，密钥规则与 NewAESCipher 相同
+// 写入结束后必须调用 Close 写入最后一块，Close 不会关闭 w
+func NewEncryptWriter(w io.Writer, key string, opts ...xopt.Option[streamConfig]) (io.WriteCloser, error) {
+	cfg := streamConfig{chunkSize: DefaultAESStreamChunkSize}
+	xopt.Apply(opts, &cfg)
+	if cfg.chunkSize <= 0 || cfg.chunkSize > MaxAESStreamChunkSize {
+		return nil, fmt.Errorf("invalid chunk size: %d, must be between 1 and %d", cfg.chunkSize, MaxAESStreamChunkSize)
+	}
+	aead, err := newStreamAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, aesStreamHeaderSize)
+	header = append(header, aesStreamMagic[:]...)
+	header = append(header, AESStreamVersion)
+	header = binary.BigEndian.AppendUint32(header, uint32(cfg.chunkSize))
+	prefix := make([]byte, aesStreamPrefixSize)
+	if _, err := io.ReadFull(rand.Reader, prefix); err != nil {
+		return nil, err
+	}
+	header = append(header, prefix...)
+	if _, err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write header: %w", err)
+	}
+
+	return &encryptWriter{
+		w:      w,
+		aead:   aead,
+		header: header,
+		prefix: prefix,
+		buf:    make([]byte, 0, cfg.chunkSize),
+		out:    make([]byte, 0, cfg.chunkSize+aead.Overhead()),
+	}, nil
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.closed {
+		return 0, errors.New("write to closed encrypt writer")
+	}
+	if e.err != nil {
+		return 0, e.err
+	}
+	var written int
+	for len(p) > 0 {
+		// 缓冲区满且还有数据时才写出，保证最后一块在 Close 时写出
+		if len(e.buf) == cap(e.buf) {
+			if err := e.flush(false); err != nil {
+				return written, err
+			}
+		}
+		n := copy(e.buf[len(e.buf):cap(e.buf)], p)
+		e.buf = e.buf[:len(e.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (e *encryptWriter) flush(final bool) error {
+	if e.counter == ^uint32(0) && !final {
+		e.err = errors.New("aes stream too large: chunk counter overflow")
+		return e.err
+	}
+	e.out = e.aead.Seal(e.out[:0], streamNonce(e.prefix, e.counter, final), e.buf, e.header)
+	if _, err := e.w.Write(e.out); err != nil {
+		e.err = err
+		return err
+	}
+	e.counter++
+	e.buf = e.buf[:0]
+	return nil
+}
+
+// Close 写入最后一块
+func (e *encryptWriter) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	if e.err != nil {
+		return e.err
+	}
+	return e.flush(true)
+}
+
+// decryptReader 流式解密读取器
+type decryptReader struct {
+	r       *bufio.Reader
+	aead    cipher.AEAD
+	header  []byte
+	prefix  []byte
+	counter uint32
+	buf     []byte
+	out     []byte
+	plain   []byte
+	done    bool
+	err     error
+}
+
+// NewDecryptReader 创建流式解密读取器，密钥规则与 NewAESCipher 相同
+// 读到 io.EOF 时保证所有数据都已通过认证且没有被截断
+func NewDecryptReader(r io.Reader, key string) (io.Reader, error) {
+	aead, err := newStreamAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, aesStreamHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrAESStreamHeader, err)
+	}
+	if header[0] != aesStreamMagic[0] || header[1] != aesStreamMagic[1] {
+		return nil, ErrAESStreamHeader
+	}
+	if header[2] != AESStreamVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrAESStreamHeader, header[2])
+	}
+	chunkSize := binary.BigEndian.Uint32(header[3:7])
+	if chunkSize == 0 || chunkSize > MaxAESStreamChunkSize {
+		return nil, fmt.Errorf("%w: invalid chunk size %d", ErrAESStreamHeader, chunkSize)
+	}
+
+	return &decryptReader{
+		r:      bufio.NewReader(r),
+		aead:   aead,
+		header: header,
+		prefix: header[7:],
+		buf:    make([]byte, int(chunkSize)+aead.Overhead()),
+		out:    make([]byte, 0, chunkSize),
+	}, nil
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		if d.done {
+			return 0, io.EOF
+		}
+		d.err = d.readChunk()
+	}
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+func (d *decryptReader) readChunk() error {
+	n, err := io.ReadFull(d.r, d.buf)
+	final := false
+	switch {
+	case errors.Is(err, io.EOF):
+		// 上一块不是最后一块，但数据已经结束
+		return ErrAESStreamTruncated
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		final = true
+	case err != nil:
+		return err
+	default:
+		if _, perr := d.r.Peek(1); errors.Is(perr, io.EOF) {
+			final = true
+		} else if perr != nil {
+			return perr
+		}
+	}
+
+	plain, err := d.aead.Open(d.out[:0], streamNonce(d.prefix, d.counter, final), d.buf[:n], d.header)
+	if err != nil {
+		if final {
+			// 以非最后一块加密的分块被当作最后一块，说明后续数据被截断
+			if _, ferr := d.aead.Open(nil, streamNonce(d.prefix, d.counter, false), d.buf[:n], d.header); ferr == nil {
+				return ErrAESStreamTruncated
+			}
+		}
+		return fmt.Errorf("%w: chunk %d", ErrAESStreamAuth, d.counter)
+	}
+	d.plain = plain
+	d.counter++
+	d.done = final
+	if !final && d.counter == 0 {
+		return errors.New("aes stream too large: chunk counter overflow")
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+)
+
+func encryptStream(t *testing.T, key string, plaintext []byte, chunkSize int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewEncryptWriter(&buf, key, WithChunkSize(chunkSize))
+	if err != nil {
+		t.Fatalf("NewEncryptWriter() error = %v", err)
+	}
+	// 分多次写入，覆盖跨分块的情况
+	for len(plaintext) > 0 {
+		n := min(len(plaintext), 7)
+		if _, err := w.Write(plaintext[:n]); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		plaintext = plaintext[n:]
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func decryptStream(key string, ciphertext []byte) ([]byte, error) {
+	r, err := NewDecryptReader(bytes.NewReader(ciphertext), key)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(r)
+}
+
+func TestAESStream_RoundTrip(t *testing.T) {
+	key := "mysecretkey12345"
+	sizes := []int{0, 1, 15, 16, 17, 32, 100, 1000}
+
+	for _, size := range sizes {
+		t.Run(formatBytes(size), func(t *testing.T) {
+			plaintext := generateRandomBytes(size)
+			ciphertext := encryptStream(t, key, plaintext, 16)
+
+			got, err := decryptStream(key, ciphertext)
+			if err != nil {
+				t.Fatalf("decrypt error = %v", err)
+			}
+			if !bytes.Equal(got, plaintext) {
+				t.Errorf("decrypted data doesn't match, got %d bytes want %d", len(got), size)
+			}
+		})
+	}
+}
+
+func TestAESStream_Truncation(t *testing.T) {
+	key := "mysecretkey12345"
+	ciphertext := encryptStream(t, key, bytes.Repeat([]byte("x"), 64), 16)
+	chunk := 16 + 16
+
+	// 去掉最后一块：倒数第二块被当作最后一块
+	if _, err := decryptStream(key, ciphertext[:len(ciphertext)-chunk]); !errors.Is(err, ErrAESStreamTruncated) {
+		t.Errorf("decrypt error = %v, want ErrAESStreamTruncated", err)
+	}
+	// 只剩头部
+	if _, err := decryptStream(key, ciphertext[:aesStreamHeaderSize]); !errors.Is(err, ErrAESStreamTruncated) {
+		t.Errorf("decrypt error = %v, want ErrAESStreamTruncated", err)
+	}
+}
+
+func TestAESStream_Tampering(t *testing.T) {
+	key := "mysecretkey12345"
+	ciphertext := encryptStream(t, key, bytes.Repeat([]byte("y"), 64), 16)
+	chunk := 16 + 16
+
+	tampered := append([]byte{}, ciphertext...)
+	tampered[aesStreamHeaderSize+3] ^= 0xff
+	if _, err := decryptStream(key, tampered); !errors.Is(err, ErrAESStreamAuth) {
+		t.Errorf("decrypt error = %v, want ErrAESStreamAuth", err)
+	}
+
+	// 交换前两块
+	swapped := append([]byte{}, ciphertext[:aesStreamHeaderSize]...)
+	swapped = append(swapped, ciphertext[aesStreamHeaderSize+chunk:aesStreamHeaderSize+2*chunk]...)
+	swapped = append(swapped, ciphertext[aesStreamHeaderSize:aesStreamHeaderSize+chunk]...)
+	swapped = append(swapped, ciphertext[aesStreamHeaderSize+2*chunk:]...)
+	if _, err := decryptStream(key, swapped); !errors.Is(err, ErrAESStreamAuth) {
+		t.Errorf("decrypt error = %v, want ErrAESStreamAuth", err)
+	}
+
+	if _, err := decryptStream("anothersecretkey", ciphertext); !errors.Is(err, ErrAESStreamAuth) {
+		t.Errorf("decrypt error = %v, want ErrAESStreamAuth", err)
+	}
+	if _, err := decryptStream(key, []byte("not a stream header")); !errors.Is(err, ErrAESStreamHeader) {
+		t.Errorf("decrypt error = %v, want ErrAESStreamHeader", err)
+	}
+}
+
+func TestAESStream_InvalidOptions(t *testing.T) {
+	if _, err := NewEncryptWriter(io.Discard, "short"); err == nil {
+		t.Error("NewEncryptWriter() expected error for invalid key")
+	}
+	if _, err := NewEncryptWriter(io.Discard, "mysecretkey12345", WithChunkSize(0)); err == nil {
+		t.Error("NewEncryptWriter() expected error for invalid chunk size")
+	}
+}
+
+func BenchmarkAESStream_Encrypt(b *testing.B) {
+	data := generateRandomBytes(1 << 20)
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		w, _ := NewEncryptWriter(io.Discard, "mysecretkey12345")
+		_, _ = w.Write(data)
+		_ = w.Close()
+	}
+}
@@ -0,0 +1,205 @@
+package codec
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidSignature 签名校验失败
+	ErrInvalidSignature = errors.New("lib.codec:invalid signature")
+	// ErrUnsupportedKey 不支持的密钥类型
+	ErrUnsupportedKey = errors.New("lib.codec:unsupported key type")
+)
+
+const (
+	pemPrivateKey    = "PRIVATE KEY"
+	pemPublicKey     = "PUBLIC KEY"
+	pemRSAPrivateKey = "RSA PRIVATE KEY"
+	pemRSAPublicKey  = "RSA PUBLIC KEY"
+	pemECPrivateKey  = "EC PRIVATE KEY"
+	pemCertificate   = "CERTIFICATE"
+)
+
+// GenerateRSAKey 生成 RSA 私钥，bits 至少 2048
+func GenerateRSAKey(bits int) (*rsa.PrivateKey, error) {
+	if bits < 2048 {
+		return nil, fmt.Errorf("invalid rsa key size: %d, must be at least 2048 bits", bits)
+	}
+	return rsa.GenerateKey(rand.Reader, bits)
+}
+
+// GenerateECDSAKey 生成 ECDSA 私钥，curve 为空时使用 P-256
+func GenerateECDSAKey(curve elliptic.Curve) (*ecdsa.PrivateKey, error) {
+	if curve == nil {
+		curve = elliptic.P256()
+	}
+	return ecdsa.GenerateKey(curve, rand.Reader)
+}
+
+// GenerateEd25519Key 生成 Ed25519 私钥
+func GenerateEd25519Key() (ed25519.PrivateKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	return priv, err
+}
+
+// GenerateX25519Key 生成 X25519 私钥，用于 SealToPublicKey
+func GenerateX25519Key() (*ecdh.PrivateKey, error) {
+	return ecdh.X25519().GenerateKey(rand.Reader)
+}
+
+// MarshalPrivateKeyDER 将私钥编码为 PKCS#8 DER
+func MarshalPrivateKeyDER(key crypto.PrivateKey) ([]byte, error) {
+	return x509.MarshalPKCS8PrivateKey(key)
+}
+
+// MarshalPrivateKeyPEM 将私钥编码为 PKCS#8 PEM
+func MarshalPrivateKeyPEM(key crypto.PrivateKey) ([]byte, error) {
+	der, err := MarshalPrivateKeyDER(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemPrivateKey, Bytes: der}), nil
+}
+
+// MarshalPublicKeyDER 将公钥编码为 PKIX DER
+func MarshalPublicKeyDER(key crypto.PublicKey) ([]byte, error) {
+	return x509.MarshalPKIXPublicKey(key)
+}
+
+// MarshalPublicKeyPEM 将公钥编码为 PKIX PEM
+func MarshalPublicKeyPEM(key crypto.PublicKey) ([]byte, error) {
+	der, err := MarshalPublicKeyDER(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: pemPublicKey, Bytes: der}), nil
+}
+
+// ParsePrivateKey 解析 PEM 或 DER 格式的私钥
+// 支持 PKCS#8、PKCS#1 (RSA) 和 SEC1 (EC)
+func ParsePrivateKey(data []byte) (crypto.PrivateKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		switch block.Type {
+		case pemRSAPrivateKey:
+			return x509.ParsePKCS1PrivateKey(block.Bytes)
+		case pemECPrivateKey:
+			return x509.ParseECPrivateKey(block.Bytes)
+		case pemPrivateKey:
+			return x509.ParsePKCS8PrivateKey(block.Bytes)
+		default:
+			return nil, fmt.Errorf("%w: unexpected pem type %q", ErrUnsupportedKey, block.Type)
+		}
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(data); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(data); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParseECPrivateKey(data); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: failed to parse private key", ErrUnsupportedKey)
+}
+
+// ParsePublicKey 解析 PEM 或 DER 格式的公钥
+// 支持 PKIX、PKCS#1 (RSA) 和证书
+func ParsePublicKey(data []byte) (crypto.PublicKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		switch block.Type {
+		case pemPublicKey:
+			return x509.ParsePKIXPublicKey(block.Bytes)
+		case pemRSAPublicKey:
+			return x509.ParsePKCS1PublicKey(block.Bytes)
+		case pemCertificate:
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			return cert.PublicKey, nil
+		default:
+			return nil, fmt.Errorf("%w: unexpected pem type %q", ErrUnsupportedKey, block.Type)
+		}
+	}
+	if key, err := x509.ParsePKIXPublicKey(data); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(data); err == nil {
+		return key, nil
+	}
+	if cert, err := x509.ParseCertificate(data); err == nil {
+		return cert.PublicKey, nil
+	}
+	return nil, fmt.Errorf("%w: failed to parse public key", ErrUnsupportedKey)
+}
+
+// ecdsaHash 根据曲线选择摘要算法
+func ecdsaHash(curve elliptic.Curve, data []byte) []byte {
+	switch curve.Params().BitSize {
+	case 384:
+		sum := sha512.Sum384(data)
+		return sum[:]
+	case 521:
+		sum := sha512.Sum512(data)
+		return sum[:]
+	}
+	sum := sha256.Sum256(data)
+	return sum[:]
+}
+
+// Sign 使用私钥签名
+// RSA 使用 PKCS#1 v1.5 + SHA-256；ECDSA 使用 ASN.1 编码，摘要算法与曲线匹配；Ed25519 直接签名原文
+func Sign(key crypto.PrivateKey, data []byte) ([]byte, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256(data)
+		return rsa.SignPKCS1v15(rand.Reader, k, crypto.SHA256, digest[:])
+	case *ecdsa.PrivateKey:
+		return ecdsa.SignASN1(rand.Reader, k, ecdsaHash(k.Curve, data))
+	case ed25519.PrivateKey:
+		return ed25519.Sign(k, data), nil
+	}
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+}
+
+// Verify 使用公钥校验签名，算法与 Sign 一致
+func Verify(key crypto.PublicKey, data, signature []byte) error {
+	var ok bool
+	switch k := key.(type) {
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+		ok = rsa.VerifyPKCS1v15(k, crypto.SHA256, digest[:], signature) == nil
+	case *ecdsa.PublicKey:
+		ok = ecdsa.VerifyASN1(k, ecdsaHash(k.Curve, data), signature)
+	case ed25519.PublicKey:
+		ok = len(k) == ed25519.PublicKeySize && ed25519.Verify(k, data, signature)
+	default:
+		return fmt.Errorf("%w: %T", ErrUnsupportedKey, key)
+	}
+	if !ok {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// RSAEncryptOAEP 使用 RSA-OAEP (SHA-256) 加密，label 可为空
+func RSAEncryptOAEP(pub *rsa.PublicKey, plaintext, label []byte) ([]byte, error) {
+	return rsa.EncryptOAEP(sha256.New(), rand.Reader, pub, plaintext, label)
+}
+
+// RSADecryptOAEP 使用 RSA-OAEP (SHA-256) 解密，label 必须与加密时一致
+func RSADecryptOAEP(priv *rsa.PrivateKey, ciphertext, label []byte) ([]byte, error) {
+	return rsa.DecryptOAEP(sha256.New(), rand.Reader, priv, ciphertext, label)
+}
@@ -0,0 +1,162 @@
+package codec
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+)
+
+func TestAsymmetricKeys(t *testing.T) {
+	rsaKey, err := GenerateRSAKey(2048)
+	if err != nil {
+		t.Fatalf("GenerateRSAKey() error = %v", err)
+	}
+	p256, _ := GenerateECDSAKey(nil)
+	p384, _ := GenerateECDSAKey(elliptic.P384())
+	edKey, _ := GenerateEd25519Key()
+
+	keys := map[string]crypto.Signer{"RSA": rsaKey, "P-256": p256, "P-384": p384, "Ed25519": edKey}
+	for name, key := range keys {
+		t.Run(name, func(t *testing.T) {
+			privPEM, err := MarshalPrivateKeyPEM(key)
+			if err != nil {
+				t.Fatalf("MarshalPrivateKeyPEM() error = %v", err)
+			}
+			pubPEM, err := MarshalPublicKeyPEM(key.Public())
+			if err != nil {
+				t.Fatalf("MarshalPublicKeyPEM() error = %v", err)
+			}
+			pubDER, _ := MarshalPublicKeyDER(key.Public())
+
+			priv, err := ParsePrivateKey(privPEM)
+			if err != nil {
+				t.Fatalf("ParsePrivateKey() error = %v", err)
+			}
+			pub, err := ParsePublicKey(pubPEM)
+			if err != nil {
+				t.Fatalf("ParsePublicKey() error = %v", err)
+			}
+			pubFromDER, err := ParsePublicKey(pubDER)
+			if err != nil {
+				t.Fatalf("ParsePublicKey(DER) error = %v", err)
+			}
+			if !pub.(interface{ Equal(crypto.PublicKey) bool }).Equal(pubFromDER) {
+				t.Error("PEM and DER public keys differ")
+			}
+
+			data := []byte("message to sign")
+			sig, err := Sign(priv, data)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			if err := Verify(pub, data, sig); err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+			if err := Verify(pub, []byte("other message"), sig); !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify() error = %v, want ErrInvalidSignature", err)
+			}
+		})
+	}
+
+	if _, err := GenerateRSAKey(1024); err == nil {
+		t.Error("GenerateRSAKey(1024) expected error")
+	}
+}
+
+func TestParseLegacyKeys(t *testing.T) {
+	rsaKey, _ := GenerateRSAKey(2048)
+	ecKey, _ := GenerateECDSAKey(nil)
+	ecDER, _ := x509.MarshalECPrivateKey(ecKey)
+
+	inputs := map[string][]byte{
+		"PKCS1 PEM": pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}),
+		"PKCS1 DER": x509.MarshalPKCS1PrivateKey(rsaKey),
+		"SEC1 PEM":  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}),
+		"SEC1 DER":  ecDER,
+	}
+	for name, data := range inputs {
+		if _, err := ParsePrivateKey(data); err != nil {
+			t.Errorf("ParsePrivateKey(%s) error = %v", name, err)
+		}
+	}
+
+	rsaPub := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)})
+	if _, err := ParsePublicKey(rsaPub); err != nil {
+		t.Errorf("ParsePublicKey(PKCS1) error = %v", err)
+	}
+
+	if _, err := ParsePrivateKey([]byte("garbage")); !errors.Is(err, ErrUnsupportedKey) {
+		t.Errorf("ParsePrivateKey() error = %v, want ErrUnsupportedKey", err)
+	}
+	if _, err := ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "FOO", Bytes: []byte{1}})); !errors.Is(err, ErrUnsupportedKey) {
+		t.Errorf("ParsePublicKey() error = %v, want ErrUnsupportedKey", err)
+	}
+}
+
+func TestRSAOAEP(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ciphertext, err := RSAEncryptOAEP(&key.PublicKey, []byte("secret"), []byte("label"))
+	if err != nil {
+		t.Fatalf("RSAEncryptOAEP() error = %v", err)
+	}
+	plaintext, err := RSADecryptOAEP(key, ciphertext, []byte("label"))
+	if err != nil || string(plaintext) != "secret" {
+		t.Errorf("RSADecryptOAEP() = %q, %v", plaintext, err)
+	}
+	if _, err := RSADecryptOAEP(key, ciphertext, []byte("other")); err == nil {
+		t.Error("RSADecryptOAEP() expected error for wrong label")
+	}
+}
+
+func TestSealToPublicKey(t *testing.T) {
+	recipient, err := GenerateX25519Key()
+	if err != nil {
+		t.Fatalf("GenerateX25519Key() error = %v", err)
+	}
+
+	// 公钥可以通过 PEM 交换
+	pubPEM, err := MarshalPublicKeyPEM(recipient.PublicKey())
+	if err != nil {
+		t.Fatalf("MarshalPublicKeyPEM() error = %v", err)
+	}
+	pub, err := ParsePublicKey(pubPEM)
+	if err != nil {
+		t.Fatalf("ParsePublicKey() error = %v", err)
+	}
+
+	for _, size := range []int{0, 1, 1000} {
+		plaintext := generateRandomBytes(size)
+		sealed, err := SealToPublicKey(pub.(*ecdh.PublicKey), plaintext)
+		if err != nil {
+			t.Fatalf("SealToPublicKey() error = %v", err)
+		}
+		opened, err := OpenWithPrivateKey(recipient, sealed)
+		if err != nil {
+			t.Fatalf("OpenWithPrivateKey() error = %v", err)
+		}
+		if !bytes.Equal(opened, plaintext) {
+			t.Errorf("OpenWithPrivateKey() = %x, want %x", opened, plaintext)
+		}
+	}
+
+	sealed, _ := SealToPublicKey(recipient.PublicKey(), []byte("payload"))
+	other, _ := GenerateX25519Key()
+	if _, err := OpenWithPrivateKey(other, sealed); err == nil {
+		t.Error("OpenWithPrivateKey() expected error for wrong key")
+	}
+	tampered := append([]byte{}, sealed...)
+	tampered[0] ^= 0x01
+	if _, err := OpenWithPrivateKey(recipient, tampered); err == nil {
+		t.Error("OpenWithPrivateKey() expected error for tampered ephemeral key")
+	}
+	if _, err := OpenWithPrivateKey(recipient, sealed[:10]); !errors.Is(err, ErrSealedData) {
+		t.Errorf("OpenWithPrivateKey() error = %v, want ErrSealedData", err)
+	}
+}
@@ -1,6 +1,10 @@
 package codec
 
-import "encoding/base32"
+import (
+	"encoding/base32"
+	"io"
+	"strings"
+)
 
 func Base32Encode(data []byte) string {
 	return base32.StdEncoding.EncodeToString(data)
@@ -9,3 +13,117 @@ func Base32Encode(data []byte) string {
 func Base32Decode(encoded string) ([]byte, error) {
 	return base32.StdEncoding.DecodeString(encoded)
 }
+
+// CrockfordAlphabet Crockford Base32 字母表，去掉了容易混淆的 I、L、O、U
+const CrockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+var crockfordEncoding = &crockford{base32.NewEncoding(CrockfordAlphabet).WithPadding(base32.NoPadding)}
+
+// crockfordDigits 字符到 5 位值的解码表，已包含大小写和易混淆字符的规范化
+var crockfordDigits = func() (table [256]byte) {
+	for i := range table {
+		table[i] = invalidDigit
+		if r := crockfordNormalize(rune(i)); r >= 0 && r < 0x80 {
+			if d := strings.IndexByte(CrockfordAlphabet, byte(r)); d >= 0 {
+				table[i] = byte(d)
+			}
+		}
+	}
+	return
+}()
+
+// CrockfordDigit 返回单个字符对应的 5 位值，忽略大小写并将 O 视为 0、I/L 视为 1
+func CrockfordDigit(c byte) (byte, bool) {
+	d := crockfordDigits[c]
+	return d, d != invalidDigit
+}
+
+// crockford Crockford Base32 编码，无填充
+// 解码时忽略大小写和连字符，并将 O 视为 0、I/L 视为 1，便于人工输入
+type crockford struct {
+	enc *base32.Encoding
+}
+
+func (c *crockford) Name() string {
+	return EncodingBase32Crockford
+}
+
+func (c *crockford) EncodeToString(data []byte) string {
+	return c.enc.EncodeToString(data)
+}
+
+func (c *crockford) DecodeString(s string) ([]byte, error) {
+	return c.enc.DecodeString(strings.Map(crockfordNormalize, s))
+}
+
+func (c *crockford) NewEncoder(w io.Writer) io.WriteCloser {
+	return base32.NewEncoder(c.enc, w)
+}
+
+func (c *crockford) NewDecoder(r io.Reader) io.Reader {
+	return base32.NewDecoder(c.enc, &crockfordReader{r: r})
+}
+
+// crockfordNormalize 将人工输入的字符规范化，返回 -1 表示丢弃
+func crockfordNormalize(r rune) rune {
+	switch r {
+	case '-':
+		return -1
+	case 'O', 'o':
+		return '0'
+	case 'I', 'i', 'L', 'l':
+		return '1'
+	}
+	if r >= 'a' && r <= 'z' {
+		return r - 'a' + 'A'
+	}
+	return r
+}
+
+// crockfordReader 流式规范化输入
+// 标准库无填充 base32 解码器把每次读到的数据当作完整输入，因此除结尾外按 8 字符分组返回
+// 凑够一组即返回，不等待读满 p
+type crockfordReader struct {
+	r   io.Reader
+	buf []byte
+	err error
+}
+
+func (c *crockfordReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for {
+		if n := min(len(c.buf), len(p)) / 8 * 8; n > 0 {
+			copy(p, c.buf[:n])
+			c.buf = c.buf[n:]
+			return n, nil
+		}
+		if c.err != nil {
+			n := copy(p, c.buf)
+			c.buf = c.buf[n:]
+			if len(c.buf) > 0 {
+				return n, nil
+			}
+			return n, c.err
+		}
+		var chunk [512]byte
+		n, err := c.r.Read(chunk[:])
+		for _, b := range chunk[:n] {
+			if r := crockfordNormalize(rune(b)); r >= 0 {
+				c.buf = append(c.buf, byte(r))
+			}
+		}
+		c.err = err
+	}
+}
+
+// CrockfordBase32Encode Crockford Base32 编码
+func CrockfordBase32Encode(data []byte) string {
+	return crockfordEncoding.EncodeToString(data)
+}
+
+// CrockfordBase32Decode Crockford Base32 解码，忽略大小写和连字符
+func CrockfordBase32Decode(encoded string) ([]byte, error) {
+	return crockfordEncoding.DecodeString(encoded)
+}
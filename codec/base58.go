@@ -0,0 +1,127 @@
+package codec
+
+import (
+	"fmt"
+	"math"
+)
+
+// invalidDigit 解码表中的非法字符标记
+const invalidDigit = 0xff
+
+// radixEncoding 基于大数进制转换的编码，前导零字节编码为字母表第一个字符
+// 复杂度为 O(n²)，适用于短 token，不支持流式
+type radixEncoding struct {
+	name   string
+	encode string
+	decode [256]byte
+}
+
+func newRadixEncoding(name, alphabet string) *radixEncoding {
+	e := &radixEncoding{name: name, encode: alphabet}
+	for i := range e.decode {
+		e.decode[i] = invalidDigit
+	}
+	for i := 0; i < len(alphabet); i++ {
+		e.decode[alphabet[i]] = byte(i)
+	}
+	return e
+}
+
+var (
+	// base58Encoding Bitcoin 字母表
+	base58Encoding = newRadixEncoding(EncodingBase58, "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz")
+	base62Encoding = newRadixEncoding(EncodingBase62, "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz")
+)
+
+func (e *radixEncoding) Name() string {
+	return e.name
+}
+
+func (e *radixEncoding) EncodeToString(data []byte) string {
+	base := len(e.encode)
+	zeros := 0
+	for zeros < len(data) && data[zeros] == 0 {
+		zeros++
+	}
+
+	size := int(float64(len(data)-zeros)*math.Log(256)/math.Log(float64(base))) + 1
+	buf := make([]byte, size)
+	high := size - 1
+	for _, b := range data[zeros:] {
+		carry := int(b)
+		j := size - 1
+		for ; j > high || carry != 0; j-- {
+			carry += 256 * int(buf[j])
+			buf[j] = byte(carry % base)
+			carry /= base
+		}
+		high = j
+	}
+
+	start := 0
+	for start < size && buf[start] == 0 {
+		start++
+	}
+	out := make([]byte, zeros+size-start)
+	for i := 0; i < zeros; i++ {
+		out[i] = e.encode[0]
+	}
+	for i, d := range buf[start:] {
+		out[zeros+i] = e.encode[d]
+	}
+	return string(out)
+}
+
+func (e *radixEncoding) DecodeString(s string) ([]byte, error) {
+	base := len(e.encode)
+	zeros := 0
+	for zeros < len(s) && s[zeros] == e.encode[0] {
+		zeros++
+	}
+
+	size := int(float64(len(s)-zeros)*math.Log(float64(base))/math.Log(256)) + 1
+	buf := make([]byte, size)
+	high := size - 1
+	for i := zeros; i < len(s); i++ {
+		d := e.decode[s[i]]
+		if d == invalidDigit {
+			return nil, fmt.Errorf("illegal %s data at input byte %d", e.name, i)
+		}
+		carry := int(d)
+		j := size - 1
+		for ; j > high || carry != 0; j-- {
+			carry += base * int(buf[j])
+			buf[j] = byte(carry)
+			carry >>= 8
+		}
+		high = j
+	}
+
+	start := 0
+	for start < size && buf[start] == 0 {
+		start++
+	}
+	out := make([]byte, zeros+size-start)
+	copy(out[zeros:], buf[start:])
+	return out, nil
+}
+
+// Base58Encode Base58 编码 (Bitcoin 字母表)
+func Base58Encode(data []byte) string {
+	return base58Encoding.EncodeToString(data)
+}
+
+// Base58Decode Base58 解码 (Bitcoin 字母表)
+func Base58Decode(encoded string) ([]byte, error) {
+	return base58Encoding.DecodeString(encoded)
+}
+
+// Base62Encode Base62 编码，字母表为 0-9A-Za-z
+func Base62Encode(data []byte) string {
+	return base62Encoding.EncodeToString(data)
+}
+
+// Base62Decode Base62 解码
+func Base62Decode(encoded string) ([]byte, error) {
+	return base62Encoding.DecodeString(encoded)
+}
@@ -11,3 +11,33 @@ func Base64Encode(data []byte) string {
 func Base64Decode(encoded string) ([]byte, error) {
 	return base64.StdEncoding.DecodeString(encoded)
 }
+
+// Base64URLEncode URL 安全的 base64 编码，带填充
+func Base64URLEncode(data []byte) string {
+	return base64.URLEncoding.EncodeToString(data)
+}
+
+// Base64URLDecode URL 安全的 base64 解码，带填充
+func Base64URLDecode(encoded string) ([]byte, error) {
+	return base64.URLEncoding.DecodeString(encoded)
+}
+
+// Base64RawEncode 无填充的标准 base64 编码
+func Base64RawEncode(data []byte) string {
+	return base64.RawStdEncoding.EncodeToString(data)
+}
+
+// Base64RawDecode 无填充的标准 base64 解码
+func Base64RawDecode(encoded string) ([]byte, error) {
+	return base64.RawStdEncoding.DecodeString(encoded)
+}
+
+// Base64RawURLEncode 无填充、URL 安全的 base64 编码，常用于 token
+func Base64RawURLEncode(data []byte) string {
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Base64RawURLDecode 无填充、URL 安全的 base64 解码
+func Base64RawURLDecode(encoded string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(encoded)
+}
@@ -0,0 +1,271 @@
+package codec
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/lzw"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+const (
+	CompressGZip    = "gzip"
+	CompressDeflate = "deflate"
+	CompressZlib    = "zlib"
+	CompressLZW     = "lzw"
+)
+
+// DefaultCompression 使用算法默认的压缩级别
+const DefaultCompression = flate.DefaultCompression
+
+// ErrUnknownCompressor 未注册的压缩算法
+var ErrUnknownCompressor = errors.New("lib.codec:unknown compressor")
+
+// Compressor 压缩算法
+type Compressor interface {
+	// Name 算法名称，如 gzip、deflate、zlib、lzw
+	Name() string
+	// Encoding HTTP Content-Encoding 标识，没有对应标识时为空
+	Encoding() string
+	// NewWriter 创建压缩写入器，level 为 DefaultCompression 时使用默认级别
+	NewWriter(w io.Writer, level int) (io.WriteCloser, error)
+	// NewReader 创建解压读取器
+	NewReader(r io.Reader) (io.ReadCloser, error)
+	// Sniff 根据魔数判断数据是否为该格式，没有魔数的格式总是返回 false
+	Sniff(data []byte) bool
+}
+
+var compressors = struct {
+	sync.RWMutex
+	names      []string
+	byName     map[string]Compressor
+	byEncoding map[string]Compressor
+}{
+	byName:     map[string]Compressor{},
+	byEncoding: map[string]Compressor{},
+}
+
+func init() {
+	RegisterCompressor(gzipCompressor{})
+	RegisterCompressor(zlibCompressor{})
+	RegisterCompressor(deflateCompressor{})
+	RegisterCompressor(lzwCompressor{})
+}
+
+// RegisterCompressor 注册压缩算法，同名算法会被覆盖
+// DetectCompressor 按注册顺序探测
+func RegisterCompressor(c Compressor) {
+	compressors.Lock()
+	defer compressors.Unlock()
+	name := strings.ToLower(c.Name())
+	if _, ok := compressors.byName[name]; !ok {
+		compressors.names = append(compressors.names, name)
+	}
+	compressors.byName[name] = c
+	if encoding := strings.ToLower(c.Encoding()); encoding != "" {
+		compressors.byEncoding[encoding] = c
+	}
+}
+
+// GetCompressor 按名称查找压缩算法
+func GetCompressor(name string) (Compressor, bool) {
+	compressors.RLock()
+	defer compressors.RUnlock()
+	c, ok := compressors.byName[strings.ToLower(strings.TrimSpace(name))]
+	return c, ok
+}
+
+// GetCompressorByEncoding 按 HTTP Content-Encoding 标识查找压缩算法
+func GetCompressorByEncoding(token string) (Compressor, bool) {
+	token = strings.ToLower(strings.TrimSpace(token))
+	if token == "x-gzip" {
+		token = "gzip"
+	}
+	compressors.RLock()
+	defer compressors.RUnlock()
+	c, ok := compressors.byEncoding[token]
+	return c, ok
+}
+
+// Compressors 返回所有已注册的压缩算法，按注册顺序排列
+func Compressors() []Compressor {
+	compressors.RLock()
+	defer compressors.RUnlock()
+	list := make([]Compressor, 0, len(compressors.names))
+	for _, name := range compressors.names {
+		list = append(list, compressors.byName[name])
+	}
+	return list
+}
+
+// DetectCompressor 根据数据内容探测压缩算法
+func DetectCompressor(data []byte) (Compressor, bool) {
+	for _, c := range Compressors() {
+		if c.Sniff(data) {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+func mustCompressor(name string) (Compressor, error) {
+	c, ok := GetCompressor(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownCompressor, name)
+	}
+	return c, nil
+}
+
+// NewCompressWriter 使用指定算法创建压缩写入器
+func NewCompressWriter(name string, w io.Writer, level ...int) (io.WriteCloser, error) {
+	c, err := mustCompressor(name)
+	if err != nil {
+		return nil, err
+	}
+	compressionLevel := DefaultCompression
+	if len(level) > 0 {
+		compressionLevel = level[0]
+	}
+	return c.NewWriter(w, compressionLevel)
+}
+
+// NewDecompressReader 使用指定算法创建解压读取器，支持 WithMaxSize 限制解压后的大小
+func NewDecompressReader(name string, r io.Reader, opts ...xopt.Option[decodeConfig]) (io.ReadCloser, error) {
+	c, err := mustCompressor(name)
+	if err != nil {
+		return nil, err
+	}
+	if c.Name() == CompressGZip {
+		return NewGZipReader(r, opts...)
+	}
+	cfg := newDecodeConfig(opts...)
+	dr, err := c.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s reader: %w", c.Name(), err)
+	}
+	return &decompressReader{
+		Reader: &limitReader{r: dr, limit: cfg.maxSize},
+		closer: dr,
+	}, nil
+}
+
+// Compress 使用指定算法压缩数据
+func Compress(name string, data []byte, level ...int) ([]byte, error) {
+	if data == nil {
+		return nil, fmt.Errorf("input cannot be nil")
+	}
+	var buf bytes.Buffer
+	w, err := NewCompressWriter(name, &buf, level...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		_ = w.Close()
+		return nil, fmt.Errorf("failed to write data: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close %s writer: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress 使用指定算法解压数据，支持 WithMaxSize 限制解压后的大小
+func Decompress(name string, data []byte, opts ...xopt.Option[decodeConfig]) ([]byte, error) {
+	if data == nil {
+		return nil, fmt.Errorf("input cannot be nil")
+	}
+	if len(data) == 0 {
+		return []byte{}, nil
+	}
+	r, err := NewDecompressReader(name, bytes.NewReader(data), opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = r.Close() }()
+
+	var buf bytes.Buffer
+	if _, err := io.Copy(&buf, r); err != nil {
+		return nil, fmt.Errorf("failed to decompress data: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// IsZlibData 检查数据是否为 zlib 格式
+func IsZlibData(data []byte) bool {
+	if len(data) < 2 {
+		return false
+	}
+	// CMF: 低 4 位为压缩方法 8(deflate)，高 4 位为窗口大小 <= 7
+	// CMF*256 + FLG 必须是 31 的倍数
+	cmf, flg := data[0], data[1]
+	return cmf&0x0f == 8 && cmf>>4 <= 7 && (uint16(cmf)<<8|uint16(flg))%31 == 0
+}
+
+func checkLevel(level int) error {
+	if level < flate.HuffmanOnly || level > flate.BestCompression {
+		return fmt.Errorf("invalid compression level: %d, must be between %d and %d",
+			level, flate.HuffmanOnly, flate.BestCompression)
+	}
+	return nil
+}
+
+type gzipCompressor struct{}
+
+func (gzipCompressor) Name() string     { return CompressGZip }
+func (gzipCompressor) Encoding() string { return "gzip" }
+func (gzipCompressor) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	return NewGZipWriter(w, level)
+}
+func (gzipCompressor) NewReader(r io.Reader) (io.ReadCloser, error) { return gzip.NewReader(r) }
+func (gzipCompressor) Sniff(data []byte) bool                       { return IsGzipData(data) }
+
+// zlibCompressor 对应 HTTP 的 deflate 编码（RFC 9110 规定其为 zlib 格式）
+type zlibCompressor struct{}
+
+func (zlibCompressor) Name() string     { return CompressZlib }
+func (zlibCompressor) Encoding() string { return "deflate" }
+func (zlibCompressor) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	return zlib.NewWriterLevel(w, level)
+}
+func (zlibCompressor) NewReader(r io.Reader) (io.ReadCloser, error) { return zlib.NewReader(r) }
+func (zlibCompressor) Sniff(data []byte) bool                       { return IsZlibData(data) }
+
+// deflateCompressor 原始 deflate 数据，没有对应的 HTTP 编码，也没有魔数无法探测
+type deflateCompressor struct{}
+
+func (deflateCompressor) Name() string     { return CompressDeflate }
+func (deflateCompressor) Encoding() string { return "" }
+func (deflateCompressor) NewWriter(w io.Writer, level int) (io.WriteCloser, error) {
+	if err := checkLevel(level); err != nil {
+		return nil, err
+	}
+	return flate.NewWriter(w, level)
+}
+func (deflateCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(r), nil
+}
+func (deflateCompressor) Sniff([]byte) bool { return false }
+
+// lzwCompressor LZW 不支持压缩级别，level 参数被忽略
+// 与 Unix compress(.Z) 格式不兼容，因此没有对应的 HTTP 编码，也没有魔数无法探测
+type lzwCompressor struct{}
+
+func (lzwCompressor) Name() string     { return CompressLZW }
+func (lzwCompressor) Encoding() string { return "" }
+func (lzwCompressor) NewWriter(w io.Writer, _ int) (io.WriteCloser, error) {
+	return lzw.NewWriter(w, lzw.LSB, 8), nil
+}
+func (lzwCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return lzw.NewReader(r, lzw.LSB, 8), nil
+}
+func (lzwCompressor) Sniff([]byte) bool { return false }
@@ -0,0 +1,101 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCompressRoundTrip(t *testing.T) {
+	input := []byte(strings.Repeat("registry compression ", 500))
+
+	for _, name := range []string{CompressGZip, CompressZlib, CompressDeflate, CompressLZW} {
+		t.Run(name, func(t *testing.T) {
+			compressed, err := Compress(name, input)
+			if err != nil {
+				t.Fatalf("Compress() error = %v", err)
+			}
+			if len(compressed) >= len(input) {
+				t.Errorf("Compress() did not reduce size: %d >= %d", len(compressed), len(input))
+			}
+
+			got, err := Decompress(name, compressed)
+			if err != nil {
+				t.Fatalf("Decompress() error = %v", err)
+			}
+			if !bytes.Equal(got, input) {
+				t.Error("Decompress() result doesn't match original")
+			}
+
+			// deflate、lzw 没有魔数，不参与探测
+			if c, ok := DetectCompressor(compressed); (name == CompressGZip || name == CompressZlib) && (!ok || c.Name() != name) {
+				t.Errorf("DetectCompressor() = %v, %v, want %s", c, ok, name)
+			}
+
+			_, err = Decompress(name, compressed, WithMaxSize(100))
+			if !errors.Is(err, ErrDecompressTooLarge) {
+				t.Errorf("Decompress() error = %v, want ErrDecompressTooLarge", err)
+			}
+		})
+	}
+}
+
+func TestCompressUnknown(t *testing.T) {
+	if _, err := Compress("brotli", []byte("x")); !errors.Is(err, ErrUnknownCompressor) {
+		t.Errorf("Compress() error = %v, want ErrUnknownCompressor", err)
+	}
+	if _, err := Decompress("brotli", []byte("x")); !errors.Is(err, ErrUnknownCompressor) {
+		t.Errorf("Decompress() error = %v, want ErrUnknownCompressor", err)
+	}
+}
+
+func TestCompressInvalidLevel(t *testing.T) {
+	for _, name := range []string{CompressGZip, CompressZlib, CompressDeflate} {
+		if _, err := Compress(name, []byte("x"), 42); err == nil {
+			t.Errorf("Compress(%s) expected error for invalid level", name)
+		}
+	}
+}
+
+func TestGetCompressorByEncoding(t *testing.T) {
+	tests := []struct {
+		token string
+		want  string
+		ok    bool
+	}{
+		{"gzip", CompressGZip, true},
+		{"x-gzip", CompressGZip, true},
+		{" GZIP ", CompressGZip, true},
+		{"deflate", CompressZlib, true},
+		{"br", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.token, func(t *testing.T) {
+			c, ok := GetCompressorByEncoding(tt.token)
+			if ok != tt.ok {
+				t.Fatalf("GetCompressorByEncoding() ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && c.Name() != tt.want {
+				t.Errorf("GetCompressorByEncoding() = %s, want %s", c.Name(), tt.want)
+			}
+		})
+	}
+}
+
+func TestCompressSniff(t *testing.T) {
+	plain := []byte(`{"id":1,"name":"plain json"}`)
+	if c, ok := DetectCompressor(plain); ok {
+		t.Errorf("DetectCompressor() detected %s for plain data", c.Name())
+	}
+	if IsZlibData([]byte{0x78}) || !IsZlibData([]byte{0x78, 0x9c}) {
+		t.Error("IsZlibData() magic check failed")
+	}
+	for _, name := range []string{CompressDeflate, CompressLZW} {
+		if c, _ := GetCompressor(name); c.Sniff(plain) {
+			t.Errorf("%s Sniff() = true", name)
+		}
+	}
+}
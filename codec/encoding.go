@@ -0,0 +1,219 @@
+package codec
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// 内置编码名称
+const (
+	EncodingHex             = "hex"
+	EncodingBase32          = "base32"
+	EncodingBase32Crockford = "base32crockford"
+	EncodingBase58          = "base58"
+	EncodingBase62          = "base62"
+	EncodingBase64          = "base64"
+	EncodingBase64URL       = "base64url"
+	EncodingBase64Raw       = "base64raw"
+	EncodingBase64RawURL    = "base64rawurl"
+)
+
+// ErrUnknownEncoding 未注册的编码
+var ErrUnknownEncoding = errors.New("lib.codec:unknown encoding")
+
+// Encoding 文本编码
+type Encoding interface {
+	Name() string
+	EncodeToString(data []byte) string
+	DecodeString(s string) ([]byte, error)
+}
+
+// StreamEncoding 支持流式编解码的文本编码
+// NewEncoder 返回的 writer 必须 Close 以写出剩余数据，Close 不会关闭 w
+type StreamEncoding interface {
+	Encoding
+	NewEncoder(w io.Writer) io.WriteCloser
+	NewDecoder(r io.Reader) io.Reader
+}
+
+var (
+	encodingMu    sync.RWMutex
+	encodings     = map[string]Encoding{}
+	encodingOrder []string
+)
+
+func init() {
+	for _, e := range []Encoding{
+		hexEncoding{},
+		&stdEncoding{EncodingBase32, base32.StdEncoding, nil},
+		crockfordEncoding,
+		base58Encoding,
+		base62Encoding,
+		&stdEncoding{EncodingBase64, nil, base64.StdEncoding},
+		&stdEncoding{EncodingBase64URL, nil, base64.URLEncoding},
+		&stdEncoding{EncodingBase64Raw, nil, base64.RawStdEncoding},
+		&stdEncoding{EncodingBase64RawURL, nil, base64.RawURLEncoding},
+	} {
+		RegisterEncoding(e)
+	}
+}
+
+// RegisterEncoding 注册编码，同名编码会被覆盖
+func RegisterEncoding(e Encoding) {
+	encodingMu.Lock()
+	defer encodingMu.Unlock()
+	if _, ok := encodings[e.Name()]; !ok {
+		encodingOrder = append(encodingOrder, e.Name())
+	}
+	encodings[e.Name()] = e
+}
+
+// GetEncoding 根据名称获取编码
+func GetEncoding(name string) (Encoding, error) {
+	encodingMu.RLock()
+	defer encodingMu.RUnlock()
+	if e, ok := encodings[name]; ok {
+		return e, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownEncoding, name)
+}
+
+// Encodings 返回已注册的编码名称，按注册顺序排列
+func Encodings() []string {
+	encodingMu.RLock()
+	defer encodingMu.RUnlock()
+	return append([]string(nil), encodingOrder...)
+}
+
+// Encode 使用指定编码编码数据
+func Encode(name string, data []byte) (string, error) {
+	e, err := GetEncoding(name)
+	if err != nil {
+		return "", err
+	}
+	return e.EncodeToString(data), nil
+}
+
+// Decode 使用指定编码解码数据
+func Decode(name, s string) ([]byte, error) {
+	e, err := GetEncoding(name)
+	if err != nil {
+		return nil, err
+	}
+	return e.DecodeString(s)
+}
+
+// NewEncoder 创建指定编码的流式编码器，编码不支持流式时返回错误
+func NewEncoder(name string, w io.Writer) (io.WriteCloser, error) {
+	e, err := getStreamEncoding(name)
+	if err != nil {
+		return nil, err
+	}
+	return e.NewEncoder(w), nil
+}
+
+// NewDecoder 创建指定编码的流式解码器，编码不支持流式时返回错误
+func NewDecoder(name string, r io.Reader) (io.Reader, error) {
+	e, err := getStreamEncoding(name)
+	if err != nil {
+		return nil, err
+	}
+	return e.NewDecoder(r), nil
+}
+
+func getStreamEncoding(name string) (StreamEncoding, error) {
+	e, err := GetEncoding(name)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := e.(StreamEncoding)
+	if !ok {
+		return nil, fmt.Errorf("encoding %s does not support streaming", name)
+	}
+	return s, nil
+}
+
+// stdEncoding 标准库 base32/base64 编码
+type stdEncoding struct {
+	name string
+	b32  *base32.Encoding
+	b64  *base64.Encoding
+}
+
+func (e *stdEncoding) Name() string {
+	return e.name
+}
+
+func (e *stdEncoding) EncodeToString(data []byte) string {
+	if e.b32 != nil {
+		return e.b32.EncodeToString(data)
+	}
+	return e.b64.EncodeToString(data)
+}
+
+func (e *stdEncoding) DecodeString(s string) ([]byte, error) {
+	if e.b32 != nil {
+		return e.b32.DecodeString(s)
+	}
+	return e.b64.DecodeString(s)
+}
+
+func (e *stdEncoding) NewEncoder(w io.Writer) io.WriteCloser {
+	if e.b32 != nil {
+		return base32.NewEncoder(e.b32, w)
+	}
+	return base64.NewEncoder(e.b64, w)
+}
+
+func (e *stdEncoding) NewDecoder(r io.Reader) io.Reader {
+	if e.b32 != nil {
+		return base32.NewDecoder(e.b32, r)
+	}
+	return base64.NewDecoder(e.b64, r)
+}
+
+// hexEncoding 小写十六进制编码
+type hexEncoding struct{}
+
+func (hexEncoding) Name() string {
+	return EncodingHex
+}
+
+func (hexEncoding) EncodeToString(data []byte) string {
+	return hex.EncodeToString(data)
+}
+
+func (hexEncoding) DecodeString(s string) ([]byte, error) {
+	return hex.DecodeString(s)
+}
+
+func (hexEncoding) NewEncoder(w io.Writer) io.WriteCloser {
+	return nopWriteCloser{hex.NewEncoder(w)}
+}
+
+func (hexEncoding) NewDecoder(r io.Reader) io.Reader {
+	return hex.NewDecoder(r)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// HexEncode 十六进制编码
+func HexEncode(data []byte) string {
+	return hex.EncodeToString(data)
+}
+
+// HexDecode 十六进制解码
+func HexDecode(encoded string) ([]byte, error) {
+	return hex.DecodeString(encoded)
+}
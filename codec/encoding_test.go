@@ -0,0 +1,164 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBase58(t *testing.T) {
+	testCases := []struct {
+		input    []byte
+		expected string
+	}{
+		{[]byte{}, ""},
+		{[]byte{0}, "1"},
+		{[]byte{0, 0, 0x28, 0x7f, 0xb4, 0xcd}, "11233QC4"},
+		{[]byte("Hello World!"), "2NEpo7TZRRrLZSi2U"},
+		{[]byte("The quick brown fox jumps over the lazy dog"), "7DdiPPYtxLjCD3wA1po2rvZHTDYjkZYiEtazrfiwJcwnKCizhGFhBGHeRdx"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.expected, func(t *testing.T) {
+			if got := Base58Encode(tc.input); got != tc.expected {
+				t.Errorf("Base58Encode(%x) = %q, want %q", tc.input, got, tc.expected)
+			}
+			got, err := Base58Decode(tc.expected)
+			if err != nil || !bytes.Equal(got, tc.input) {
+				t.Errorf("Base58Decode(%q) = %x, %v", tc.expected, got, err)
+			}
+		})
+	}
+
+	if _, err := Base58Decode("0OIl"); err == nil {
+		t.Error("Base58Decode() expected error for characters outside the alphabet")
+	}
+}
+
+func TestBase62(t *testing.T) {
+	for _, size := range []int{0, 1, 16, 100} {
+		data := generateRandomBytes(size)
+		encoded := Base62Encode(data)
+		decoded, err := Base62Decode(encoded)
+		if err != nil || !bytes.Equal(decoded, data) {
+			t.Errorf("Base62 round trip failed for %d bytes: %v", size, err)
+		}
+	}
+	if got := Base62Encode([]byte{0, 0, 61}); got != "00z" {
+		t.Errorf("Base62Encode() = %q, want %q", got, "00z")
+	}
+	if _, err := Base62Decode("abc-"); err == nil {
+		t.Error("Base62Decode() expected error for invalid character")
+	}
+}
+
+func TestCrockfordBase32(t *testing.T) {
+	encoded := CrockfordBase32Encode([]byte("hello"))
+	if encoded != "D1JPRV3F" {
+		t.Errorf("CrockfordBase32Encode() = %q, want %q", encoded, "D1JPRV3F")
+	}
+	// 人工输入：小写、连字符、易混淆字符
+	for _, input := range []string{"D1JPRV3F", "d1jp-rv3f", "DIJPRV3F", "dljprv3f"} {
+		got, err := CrockfordBase32Decode(input)
+		if err != nil || string(got) != "hello" {
+			t.Errorf("CrockfordBase32Decode(%q) = %q, %v", input, got, err)
+		}
+	}
+	if _, err := CrockfordBase32Decode("D1JPRU3F"); err == nil {
+		t.Error("CrockfordBase32Decode() expected error for U")
+	}
+}
+
+func TestEncodingRegistry(t *testing.T) {
+	data := []byte("\xfb\xff binary?data")
+	for _, name := range Encodings() {
+		t.Run(name, func(t *testing.T) {
+			encoded, err := Encode(name, data)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			decoded, err := Decode(name, encoded)
+			if err != nil || !bytes.Equal(decoded, data) {
+				t.Errorf("Decode(%q) = %q, %v", encoded, decoded, err)
+			}
+
+			e, _ := GetEncoding(name)
+			if _, ok := e.(StreamEncoding); !ok {
+				return
+			}
+			var buf bytes.Buffer
+			w, _ := NewEncoder(name, &buf)
+			for _, b := range data {
+				w.Write([]byte{b})
+			}
+			w.Close()
+			if buf.String() != encoded {
+				t.Errorf("stream encoded = %q, want %q", buf.String(), encoded)
+			}
+			r, _ := NewDecoder(name, iotestOneByteReader(strings.NewReader(encoded)))
+			streamed, err := io.ReadAll(r)
+			if err != nil || !bytes.Equal(streamed, data) {
+				t.Errorf("stream decoded = %q, %v", streamed, err)
+			}
+		})
+	}
+
+	if got, _ := Encode(EncodingBase64RawURL, []byte{0xfb, 0xff}); got != "-_8" {
+		t.Errorf("base64rawurl = %q, want %q", got, "-_8")
+	}
+	if _, err := Encode("base91", data); !errors.Is(err, ErrUnknownEncoding) {
+		t.Errorf("Encode() error = %v, want ErrUnknownEncoding", err)
+	}
+	if _, err := NewEncoder(EncodingBase58, io.Discard); err == nil {
+		t.Error("NewEncoder() expected error for non-streaming encoding")
+	}
+}
+
+func TestCrockfordStreamDecoder(t *testing.T) {
+	r, _ := NewDecoder(EncodingBase32Crockford, strings.NewReader("d1jp-----rv3f"))
+	got, err := io.ReadAll(r)
+	if err != nil || string(got) != "hello" {
+		t.Errorf("stream decoded = %q, %v", got, err)
+	}
+}
+
+func TestCrockfordStreamDecoderPartial(t *testing.T) {
+	// 上游暂无更多数据时，已读到的完整分组应立即返回
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	go func() { _, _ = pw.Write([]byte("d1jp-rv3f")) }()
+	r, _ := NewDecoder(EncodingBase32Crockford, pr)
+	done := make(chan string, 1)
+	go func() {
+		buf := make([]byte, 64)
+		n, _ := r.Read(buf)
+		done <- string(buf[:n])
+	}()
+	select {
+	case got := <-done:
+		if got != "hello" {
+			t.Errorf("Read() = %q, want hello", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Read() blocked waiting to fill the buffer")
+	}
+}
+
+// iotestOneByteReader 每次只返回一个字节，覆盖流式解码的边界
+func iotestOneByteReader(r io.Reader) io.Reader {
+	return &oneByteReader{r}
+}
+
+type oneByteReader struct {
+	r io.Reader
+}
+
+func (o *oneByteReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return o.r.Read(p[:1])
+}
@@ -2,9 +2,10 @@ package codec
 
 import (
 	"bytes"
-	"compress/gzip"
 	"fmt"
 	"io"
+
+	"github.com/monaco-io/lib/typing/xopt"
 )
 
 // GZipEncode 压缩数据到 gzip 格式
@@ -16,20 +17,9 @@ func GZipEncode(input []byte, level ...int) ([]byte, error) {
 
 	var buf bytes.Buffer
 
-	// 设置压缩级别，默认为 DefaultCompression
-	compressionLevel := gzip.DefaultCompression
-	if len(level) > 0 {
-		compressionLevel = level[0]
-		// 验证压缩级别的有效性
-		if compressionLevel < gzip.HuffmanOnly || compressionLevel > gzip.BestCompression {
-			return nil, fmt.Errorf("invalid compression level: %d, must be between %d and %d",
-				compressionLevel, gzip.HuffmanOnly, gzip.BestCompression)
-		}
-	}
-
-	w, err := gzip.NewWriterLevel(&buf, compressionLevel)
+	w, err := NewGZipWriter(&buf, level...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create gzip writer: %w", err)
+		return nil, err
 	}
 
 	// 使用 defer 确保 writer 被正确关闭
@@ -53,7 +43,8 @@ func GZipEncode(input []byte, level ...int) ([]byte, error) {
 }
 
 // GZipDecode 解压 gzip 格式的数据
-func GZipDecode(input []byte) ([]byte, error) {
+// 支持 WithMaxSize 限制解压后的大小，防止解压炸弹
+func GZipDecode(input []byte, opts ...xopt.Option[decodeConfig]) ([]byte, error) {
 	if input == nil {
 		return nil, fmt.Errorf("input cannot be nil")
 	}
@@ -62,10 +53,9 @@ func GZipDecode(input []byte) ([]byte, error) {
 		return []byte{}, nil
 	}
 
-	r := bytes.NewReader(input)
-	gr, err := gzip.NewReader(r)
+	gr, err := NewGZipReader(bytes.NewReader(input), opts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+		return nil, err
 	}
 
 	defer func() {
@@ -89,8 +79,8 @@ func GzipEncodeString(input string, level ...int) ([]byte, error) {
 }
 
 // GzipDecodeString 解压 gzip 数据到字符串
-func GzipDecodeString(input []byte) (string, error) {
-	data, err := GZipDecode(input)
+func GzipDecodeString(input []byte, opts ...xopt.Option[decodeConfig]) (string, error) {
+	data, err := GZipDecode(input, opts...)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,196 @@
+package codec
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+// ErrDecompressTooLarge 解压后的数据超过了设定的上限
+var ErrDecompressTooLarge = errors.New("lib.codec:decompressed size exceeds limit")
+
+// DecompressLimitError 解压数据超过 WithMaxSize 上限时返回的错误
+// 可以通过 errors.Is(err, ErrDecompressTooLarge) 判断
+type DecompressLimitError struct {
+	Limit int64
+}
+
+func (e *DecompressLimitError) Error() string {
+	return fmt.Sprintf("lib.codec:decompressed size exceeds limit of %d bytes", e.Limit)
+}
+
+func (e *DecompressLimitError) Unwrap() error {
+	return ErrDecompressTooLarge
+}
+
+type decodeConfig struct {
+	maxSize     int64
+	multistream bool
+}
+
+// WithMaxSize 限制解压后的最大字节数，<=0 表示不限制
+func WithMaxSize(n int64) xopt.Option[decodeConfig] {
+	return func(cfg *decodeConfig) {
+		cfg.maxSize = n
+	}
+}
+
+// WithMultistream 是否将连续拼接的多个 gzip member 当作一个整体解压，默认开启
+func WithMultistream(enable bool) xopt.Option[decodeConfig] {
+	return func(cfg *decodeConfig) {
+		cfg.multistream = enable
+	}
+}
+
+func newDecodeConfig(opts ...xopt.Option[decodeConfig]) decodeConfig {
+	cfg := decodeConfig{multistream: true}
+	xopt.Apply(opts, &cfg)
+	return cfg
+}
+
+// limitReader 读取超过 limit 字节时返回 DecompressLimitError
+// 超限后之后的每次读取都返回同一个错误
+type limitReader struct {
+	r     io.Reader
+	limit int64
+	n     int64
+	err   error
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	if l.limit <= 0 {
+		return l.r.Read(p)
+	}
+	// 多读一个字节用于判断是否超限
+	if remain := l.limit - l.n + 1; int64(len(p)) > remain {
+		p = p[:remain]
+	}
+	n, err := l.r.Read(p)
+	l.n += int64(n)
+	if l.n > l.limit {
+		l.err = &DecompressLimitError{Limit: l.limit}
+		return n - int(l.n-l.limit), l.err
+	}
+	return n, err
+}
+
+// decompressReader 组合限流读取和底层解压器的关闭
+type decompressReader struct {
+	io.Reader
+	closer io.Closer
+}
+
+func (d *decompressReader) Close() error {
+	return d.closer.Close()
+}
+
+// NewGZipWriter 创建 gzip 写入器，支持可选的压缩级别配置
+// 调用方必须在写入结束后 Close 以刷新数据
+func NewGZipWriter(w io.Writer, level ...int) (*gzip.Writer, error) {
+	// 设置压缩级别，默认为 DefaultCompression
+	compressionLevel := gzip.DefaultCompression
+	if len(level) > 0 {
+		compressionLevel = level[0]
+		// 验证压缩级别的有效性
+		if compressionLevel < gzip.HuffmanOnly || compressionLevel > gzip.BestCompression {
+			return nil, fmt.Errorf("invalid compression level: %d, must be between %d and %d",
+				compressionLevel, gzip.HuffmanOnly, gzip.BestCompression)
+		}
+	}
+
+	gw, err := gzip.NewWriterLevel(w, compressionLevel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gzip writer: %w", err)
+	}
+	return gw, nil
+}
+
+// NewGZipReader 创建 gzip 流式读取器
+// 支持 WithMaxSize 限制解压后的大小，WithMultistream 控制多 member 的处理方式
+func NewGZipReader(r io.Reader, opts ...xopt.Option[decodeConfig]) (io.ReadCloser, error) {
+	cfg := newDecodeConfig(opts...)
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	gr.Multistream(cfg.multistream)
+	return &decompressReader{
+		Reader: &limitReader{r: gr, limit: cfg.maxSize},
+		closer: gr,
+	}, nil
+}
+
+// GZipEncodeStream 将 src 的数据流式压缩写入 dst，返回读取的原始字节数
+func GZipEncodeStream(dst io.Writer, src io.Reader, level ...int) (int64, error) {
+	gw, err := NewGZipWriter(dst, level...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := io.Copy(gw, src)
+	if err != nil {
+		_ = gw.Close()
+		return n, fmt.Errorf("failed to write data: %w", err)
+	}
+	if err := gw.Close(); err != nil {
+		return n, fmt.Errorf("failed to close gzip writer: %w", err)
+	}
+	return n, nil
+}
+
+// GZipDecodeStream 将 src 中的 gzip 数据流式解压写入 dst，返回解压后的字节数
+func GZipDecodeStream(dst io.Writer, src io.Reader, opts ...xopt.Option[decodeConfig]) (int64, error) {
+	gr, err := NewGZipReader(src, opts...)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = gr.Close() }()
+
+	n, err := io.Copy(dst, gr)
+	if err != nil {
+		return n, fmt.Errorf("failed to decompress data: %w", err)
+	}
+	return n, nil
+}
+
+// GZipDecodeMembers 逐个解压拼接在一起的多个 gzip member
+// WithMaxSize 限制的是所有 member 解压后的总大小
+func GZipDecodeMembers(input []byte, opts ...xopt.Option[decodeConfig]) ([][]byte, error) {
+	if input == nil {
+		return nil, fmt.Errorf("input cannot be nil")
+	}
+	cfg := newDecodeConfig(opts...)
+
+	br := bytes.NewReader(input)
+	gr, err := gzip.NewReader(br)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer func() { _ = gr.Close() }()
+
+	var (
+		members [][]byte
+		limit   = &limitReader{r: gr, limit: cfg.maxSize}
+	)
+	for {
+		gr.Multistream(false)
+		var buf bytes.Buffer
+		if _, err := io.Copy(&buf, limit); err != nil {
+			return nil, fmt.Errorf("failed to decompress member %d: %w", len(members), err)
+		}
+		members = append(members, buf.Bytes())
+
+		if err := gr.Reset(br); err != nil {
+			if errors.Is(err, io.EOF) {
+				return members, nil
+			}
+			return nil, fmt.Errorf("failed to read member %d header: %w", len(members), err)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestGZipStreamRoundTrip(t *testing.T) {
+	input := strings.Repeat("streaming gzip data ", 4096)
+
+	var compressed bytes.Buffer
+	n, err := GZipEncodeStream(&compressed, strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("GZipEncodeStream() error = %v", err)
+	}
+	if n != int64(len(input)) {
+		t.Errorf("GZipEncodeStream() read = %d, want %d", n, len(input))
+	}
+	if !IsGzipData(compressed.Bytes()) {
+		t.Fatal("GZipEncodeStream() output is not gzip data")
+	}
+
+	var decompressed bytes.Buffer
+	n, err = GZipDecodeStream(&decompressed, &compressed)
+	if err != nil {
+		t.Fatalf("GZipDecodeStream() error = %v", err)
+	}
+	if n != int64(len(input)) || decompressed.String() != input {
+		t.Errorf("GZipDecodeStream() mismatch, got %d bytes", n)
+	}
+}
+
+func TestGZipEncodeStreamInvalidLevel(t *testing.T) {
+	if _, err := GZipEncodeStream(io.Discard, strings.NewReader("x"), 100); err == nil {
+		t.Error("GZipEncodeStream() expected error for invalid level")
+	}
+}
+
+func TestGZipDecodeMaxSize(t *testing.T) {
+	// 1MB 的重复数据压缩后只有几 KB，模拟解压炸弹
+	bomb, err := GZipEncode(bytes.Repeat([]byte{0}, 1<<20))
+	if err != nil {
+		t.Fatalf("GZipEncode() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		maxSize int64
+		wantErr bool
+	}{
+		{"unlimited", 0, false},
+		{"exact limit", 1 << 20, false},
+		{"below limit", 1<<20 - 1, true},
+		{"small limit", 1024, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GZipDecode(bomb, WithMaxSize(tt.maxSize))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GZipDecode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if !errors.Is(err, ErrDecompressTooLarge) {
+					t.Errorf("GZipDecode() error = %v, want ErrDecompressTooLarge", err)
+				}
+				var limitErr *DecompressLimitError
+				if !errors.As(err, &limitErr) || limitErr.Limit != tt.maxSize {
+					t.Errorf("GZipDecode() error = %v, want DecompressLimitError{%d}", err, tt.maxSize)
+				}
+				return
+			}
+			if len(got) != 1<<20 {
+				t.Errorf("GZipDecode() len = %d, want %d", len(got), 1<<20)
+			}
+		})
+	}
+}
+
+func TestGZipReaderMaxSize(t *testing.T) {
+	compressed, _ := GZipEncode(bytes.Repeat([]byte("a"), 10000))
+
+	r, err := NewGZipReader(bytes.NewReader(compressed), WithMaxSize(100))
+	if err != nil {
+		t.Fatalf("NewGZipReader() error = %v", err)
+	}
+	defer r.Close()
+
+	got, err := io.ReadAll(r)
+	if !errors.Is(err, ErrDecompressTooLarge) {
+		t.Fatalf("ReadAll() error = %v, want ErrDecompressTooLarge", err)
+	}
+	if len(got) != 100 {
+		t.Errorf("ReadAll() len = %d, want 100", len(got))
+	}
+	// 超限后继续读取仍返回 0 和同一个错误
+	for range 3 {
+		if n, err := r.Read(make([]byte, 16)); n != 0 || !errors.Is(err, ErrDecompressTooLarge) {
+			t.Fatalf("Read() after limit = %d, %v", n, err)
+		}
+	}
+}
+
+func TestGZipMultiMember(t *testing.T) {
+	first, _ := GZipEncode([]byte("hello, "))
+	second, _ := GZipEncode([]byte("world"))
+	input := append(append([]byte{}, first...), second...)
+
+	t.Run("multistream", func(t *testing.T) {
+		got, err := GZipDecode(input)
+		if err != nil {
+			t.Fatalf("GZipDecode() error = %v", err)
+		}
+		if string(got) != "hello, world" {
+			t.Errorf("GZipDecode() = %q, want %q", got, "hello, world")
+		}
+	})
+
+	t.Run("single member", func(t *testing.T) {
+		got, err := GZipDecode(input, WithMultistream(false))
+		if err != nil {
+			t.Fatalf("GZipDecode() error = %v", err)
+		}
+		if string(got) != "hello, " {
+			t.Errorf("GZipDecode() = %q, want %q", got, "hello, ")
+		}
+	})
+
+	t.Run("members", func(t *testing.T) {
+		members, err := GZipDecodeMembers(input)
+		if err != nil {
+			t.Fatalf("GZipDecodeMembers() error = %v", err)
+		}
+		if len(members) != 2 || string(members[0]) != "hello, " || string(members[1]) != "world" {
+			t.Errorf("GZipDecodeMembers() = %q", members)
+		}
+	})
+
+	t.Run("members total limit", func(t *testing.T) {
+		_, err := GZipDecodeMembers(input, WithMaxSize(10))
+		if !errors.Is(err, ErrDecompressTooLarge) {
+			t.Errorf("GZipDecodeMembers() error = %v, want ErrDecompressTooLarge", err)
+		}
+	})
+}
@@ -0,0 +1,311 @@
+package codec
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+	"hash/fnv"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/cespare/xxhash/v2"
+)
+
+// HashAlgorithm 哈希算法名称
+type HashAlgorithm string
+
+const (
+	HashMD5    HashAlgorithm = "md5"
+	HashSHA1   HashAlgorithm = "sha1"
+	HashSHA224 HashAlgorithm = "sha224"
+	HashSHA256 HashAlgorithm = "sha256"
+	HashSHA384 HashAlgorithm = "sha384"
+	HashSHA512 HashAlgorithm = "sha512"
+
+	// 非加密哈希，只适用于校验和分片，不能用于签名
+	HashCRC32    HashAlgorithm = "crc32"
+	HashCRC64    HashAlgorithm = "crc64"
+	HashFNV32a   HashAlgorithm = "fnv32a"
+	HashFNV64a   HashAlgorithm = "fnv64a"
+	HashXXHash64 HashAlgorithm = "xxhash64"
+)
+
+// ErrUnknownHash 未知的哈希算法
+var ErrUnknownHash = errors.New("lib.codec:unknown hash algorithm")
+
+type hashEntry struct {
+	new    func() hash.Hash
+	crypto bool
+}
+
+var (
+	hashMu sync.RWMutex
+	hashes = map[HashAlgorithm]hashEntry{
+		HashMD5:      {md5.New, true},
+		HashSHA1:     {sha1.New, true},
+		HashSHA224:   {sha256.New224, true},
+		HashSHA256:   {sha256.New, true},
+		HashSHA384:   {sha512.New384, true},
+		HashSHA512:   {sha512.New, true},
+		HashCRC32:    {func() hash.Hash { return crc32.NewIEEE() }, false},
+		HashCRC64:    {func() hash.Hash { return crc64.New(crc64Table) }, false},
+		HashFNV32a:   {func() hash.Hash { return fnv.New32a() }, false},
+		HashFNV64a:   {func() hash.Hash { return fnv.New64a() }, false},
+		HashXXHash64: {func() hash.Hash { return xxhash.New() }, false},
+	}
+)
+
+var crc64Table = crc64.MakeTable(crc64.ECMA)
+
+// RegisterHash 注册哈希算法，crypto 表示是否可以用于 HMAC，同名算法会被覆盖
+func RegisterHash(alg HashAlgorithm, new func() hash.Hash, crypto bool) {
+	hashMu.Lock()
+	defer hashMu.Unlock()
+	hashes[alg] = hashEntry{new: new, crypto: crypto}
+}
+
+// newHash 根据算法名称返回哈希构造函数
+func newHash(alg HashAlgorithm) (func() hash.Hash, error) {
+	hashMu.RLock()
+	defer hashMu.RUnlock()
+	if e, ok := hashes[alg]; ok {
+		return e.new, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownHash, alg)
+}
+
+func isCryptoHash(alg HashAlgorithm) bool {
+	hashMu.RLock()
+	defer hashMu.RUnlock()
+	return hashes[alg].crypto
+}
+
+// NewHash 创建指定算法的哈希
+func NewHash(alg HashAlgorithm) (hash.Hash, error) {
+	h, err := newHash(alg)
+	if err != nil {
+		return nil, err
+	}
+	return h(), nil
+}
+
+// Hash 计算数据的哈希值
+func Hash(alg HashAlgorithm, data []byte) (string, error) {
+	h, err := NewHash(alg)
+	if err != nil {
+		return "", err
+	}
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashReader 流式计算 reader 的哈希值
+func HashReader(alg HashAlgorithm, r io.Reader) (string, error) {
+	h, err := NewHash(alg)
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// HashFile 流式计算文件的哈希值
+func HashFile(alg HashAlgorithm, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return HashReader(alg, f)
+}
+
+// MD5Reader 流式计算MD5哈希值
+func MD5Reader(r io.Reader) (string, error) {
+	return HashReader(HashMD5, r)
+}
+
+// MD5File 流式计算文件的MD5哈希值
+func MD5File(path string) (string, error) {
+	return HashFile(HashMD5, path)
+}
+
+// SHA1Reader 流式计算SHA1哈希值
+func SHA1Reader(r io.Reader) (string, error) {
+	return HashReader(HashSHA1, r)
+}
+
+// SHA1File 流式计算文件的SHA1哈希值
+func SHA1File(path string) (string, error) {
+	return HashFile(HashSHA1, path)
+}
+
+// SHA256Reader 流式计算SHA256哈希值
+func SHA256Reader(r io.Reader) (string, error) {
+	return HashReader(HashSHA256, r)
+}
+
+// SHA256File 流式计算文件的SHA256哈希值
+func SHA256File(path string) (string, error) {
+	return HashFile(HashSHA256, path)
+}
+
+// SHA512Reader 流式计算SHA512哈希值
+func SHA512Reader(r io.Reader) (string, error) {
+	return HashReader(HashSHA512, r)
+}
+
+// SHA512File 流式计算文件的SHA512哈希值
+func SHA512File(path string) (string, error) {
+	return HashFile(HashSHA512, path)
+}
+
+// CRC32 计算数据的CRC32 (IEEE)校验值
+func CRC32(data []byte) string {
+	s, _ := Hash(HashCRC32, data)
+	return s
+}
+
+// CRC64 计算数据的CRC64 (ECMA)校验值
+func CRC64(data []byte) string {
+	s, _ := Hash(HashCRC64, data)
+	return s
+}
+
+// FNV32a 计算数据的FNV-1a 32位哈希值
+func FNV32a(data []byte) string {
+	s, _ := Hash(HashFNV32a, data)
+	return s
+}
+
+// FNV64a 计算数据的FNV-1a 64位哈希值
+func FNV64a(data []byte) string {
+	s, _ := Hash(HashFNV64a, data)
+	return s
+}
+
+// XXHash64 计算数据的xxHash64哈希值
+func XXHash64(data []byte) string {
+	s, _ := Hash(HashXXHash64, data)
+	return s
+}
+
+// MultiHasher 一次读取同时计算多种哈希
+type MultiHasher struct {
+	algs   []HashAlgorithm
+	hashes []hash.Hash
+	w      io.Writer
+}
+
+// NewMultiHasher 创建多算法哈希，algs 为空时计算 MD5、SHA1 和 SHA256
+func NewMultiHasher(algs ...HashAlgorithm) (*MultiHasher, error) {
+	if len(algs) == 0 {
+		algs = []HashAlgorithm{HashMD5, HashSHA1, HashSHA256}
+	}
+	m := &MultiHasher{algs: algs}
+	writers := make([]io.Writer, 0, len(algs))
+	for _, alg := range algs {
+		h, err := NewHash(alg)
+		if err != nil {
+			return nil, err
+		}
+		m.hashes = append(m.hashes, h)
+		writers = append(writers, h)
+	}
+	m.w = io.MultiWriter(writers...)
+	return m, nil
+}
+
+// Write 实现 io.Writer，hash.Hash 的写入不会返回错误
+func (m *MultiHasher) Write(p []byte) (int, error) {
+	return m.w.Write(p)
+}
+
+// Sum 返回指定算法的哈希值
+func (m *MultiHasher) Sum(alg HashAlgorithm) string {
+	for i, a := range m.algs {
+		if a == alg {
+			return hex.EncodeToString(m.hashes[i].Sum(nil))
+		}
+	}
+	return ""
+}
+
+// Sums 返回所有算法的哈希值
+func (m *MultiHasher) Sums() map[HashAlgorithm]string {
+	sums := make(map[HashAlgorithm]string, len(m.algs))
+	for i, alg := range m.algs {
+		sums[alg] = hex.EncodeToString(m.hashes[i].Sum(nil))
+	}
+	return sums
+}
+
+// Reset 重置所有哈希
+func (m *MultiHasher) Reset() {
+	for _, h := range m.hashes {
+		h.Reset()
+	}
+}
+
+// HashReaderMulti 一次读取 reader 计算多种哈希
+func HashReaderMulti(r io.Reader, algs ...HashAlgorithm) (map[HashAlgorithm]string, error) {
+	m, err := NewMultiHasher(algs...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(m, r); err != nil {
+		return nil, err
+	}
+	return m.Sums(), nil
+}
+
+// HashWriter 写入目标的同时计算哈希，适用于边保存上传文件边计算校验值
+type HashWriter struct {
+	*MultiHasher
+	w       io.Writer
+	written int64
+}
+
+// NewHashWriter 创建哈希写入器，algs 为空时计算 MD5、SHA1 和 SHA256
+func NewHashWriter(w io.Writer, algs ...HashAlgorithm) (*HashWriter, error) {
+	m, err := NewMultiHasher(algs...)
+	if err != nil {
+		return nil, err
+	}
+	return &HashWriter{MultiHasher: m, w: w}, nil
+}
+
+// Write 写入目标并计算哈希，只有成功写入目标的数据参与计算
+func (h *HashWriter) Write(p []byte) (int, error) {
+	n, err := h.w.Write(p)
+	h.MultiHasher.Write(p[:n])
+	h.written += int64(n)
+	return n, err
+}
+
+// Written 已写入的字节数
+func (h *HashWriter) Written() int64 {
+	return h.written
+}
+
+// CopyWithHash 复制数据并同时计算哈希
+func CopyWithHash(dst io.Writer, src io.Reader, algs ...HashAlgorithm) (int64, map[HashAlgorithm]string, error) {
+	hw, err := NewHashWriter(dst, algs...)
+	if err != nil {
+		return 0, nil, err
+	}
+	n, err := io.Copy(hw, src)
+	if err != nil {
+		return n, nil, err
+	}
+	return n, hw.Sums(), nil
+}
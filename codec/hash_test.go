@@ -0,0 +1,126 @@
+package codec
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHashReader(t *testing.T) {
+	input := "The quick brown fox jumps over the lazy dog"
+	testCases := []struct {
+		alg      HashAlgorithm
+		expected string
+	}{
+		{HashMD5, "9e107d9d372bb6826bd81d3542a419d6"},
+		{HashSHA1, "2fd4e1c67a2d28fced849ee1bb76e7391b93eb12"},
+		{HashSHA256, "d7a8fbb307d7809469ca9abcb0082e4f8d5651e46d3cdb762d02d0bf37c9e592"},
+		{HashCRC32, "414fa339"},
+		{HashFNV32a, "048fff90"},
+		{HashXXHash64, "0b242d361fda71bc"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.alg), func(t *testing.T) {
+			got, err := HashReader(tc.alg, strings.NewReader(input))
+			if err != nil {
+				t.Fatalf("HashReader() error = %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("HashReader(%s) = %q, want %q", tc.alg, got, tc.expected)
+			}
+			if sum, _ := Hash(tc.alg, []byte(input)); sum != got {
+				t.Errorf("Hash(%s) = %q, want %q", tc.alg, sum, got)
+			}
+		})
+	}
+
+	// 与 []byte 版本一致
+	data := []byte(input)
+	if got, _ := SHA256Reader(bytes.NewReader(data)); got != SHA256(data) {
+		t.Errorf("SHA256Reader() = %q, want %q", got, SHA256(data))
+	}
+	if CRC64(data) == "" || FNV64a(data) == "" || XXHash64(data) == "" {
+		t.Error("non-cryptographic hash returned empty string")
+	}
+
+	if _, err := HashReader("md4", strings.NewReader(input)); !errors.Is(err, ErrUnknownHash) {
+		t.Errorf("HashReader() error = %v, want ErrUnknownHash", err)
+	}
+	if _, err := HMAC(HashCRC32, []byte("key"), data); !errors.Is(err, ErrUnknownHash) {
+		t.Errorf("HMAC() error = %v, want ErrUnknownHash for non-cryptographic hash", err)
+	}
+}
+
+func TestHashFile(t *testing.T) {
+	data := generateRandomBytes(100000)
+	path := filepath.Join(t.TempDir(), "data.bin")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := SHA256File(path)
+	if err != nil || got != SHA256(data) {
+		t.Errorf("SHA256File() = %q, %v", got, err)
+	}
+	if got, _ := MD5File(path); got != MD5(data) {
+		t.Errorf("MD5File() = %q", got)
+	}
+	if _, err := HashFile(HashSHA256, filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("HashFile() expected error for missing file")
+	}
+}
+
+func TestMultiHasher(t *testing.T) {
+	data := generateRandomBytes(10000)
+
+	sums, err := HashReaderMulti(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("HashReaderMulti() error = %v", err)
+	}
+	want := map[HashAlgorithm]string{HashMD5: MD5(data), HashSHA1: SHA1(data), HashSHA256: SHA256(data)}
+	for alg, sum := range want {
+		if sums[alg] != sum {
+			t.Errorf("sums[%s] = %q, want %q", alg, sums[alg], sum)
+		}
+	}
+
+	m, _ := NewMultiHasher(HashSHA512, HashCRC32)
+	m.Write(data)
+	if m.Sum(HashSHA512) != SHA512(data) || m.Sum(HashCRC32) != CRC32(data) || m.Sum(HashMD5) != "" {
+		t.Error("MultiHasher.Sum() mismatch")
+	}
+	m.Reset()
+	if m.Sum(HashSHA512) != SHA512(nil) {
+		t.Error("MultiHasher.Reset() did not reset")
+	}
+
+	if _, err := NewMultiHasher(HashSHA256, "unknown"); !errors.Is(err, ErrUnknownHash) {
+		t.Errorf("NewMultiHasher() error = %v, want ErrUnknownHash", err)
+	}
+}
+
+func TestHashWriter(t *testing.T) {
+	data := generateRandomBytes(50000)
+	var dst bytes.Buffer
+
+	n, sums, err := CopyWithHash(&dst, bytes.NewReader(data), HashSHA256, HashXXHash64)
+	if err != nil {
+		t.Fatalf("CopyWithHash() error = %v", err)
+	}
+	if n != int64(len(data)) || !bytes.Equal(dst.Bytes(), data) {
+		t.Errorf("CopyWithHash() copied %d bytes", n)
+	}
+	if sums[HashSHA256] != SHA256(data) || sums[HashXXHash64] != XXHash64(data) {
+		t.Errorf("CopyWithHash() sums = %v", sums)
+	}
+
+	hw, _ := NewHashWriter(&dst)
+	hw.Write([]byte("abc"))
+	if hw.Written() != 3 || hw.Sum(HashMD5) != MD5String("abc") {
+		t.Errorf("HashWriter written = %d, md5 = %q", hw.Written(), hw.Sum(HashMD5))
+	}
+}
@@ -0,0 +1,321 @@
+package codec
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+/**
+Hashids 兼容的短 ID 编码，将非负整数序列编码为不连续、URL 安全的字符串：
+
+- 字母表按 salt 洗牌，不同 salt 编码结果不同
+- 首字符为 lottery，决定后续每个数字使用的字母表
+- 分隔符 (seps) 分隔多个数字，守卫字符 (guards) 用于补足最小长度
+- 解码后重新编码并比较，被篡改的字符串无法通过校验
+
+这是混淆而不是加密，不能用于保护敏感数据。
+**/
+
+const (
+	// DefaultHashIDAlphabet 默认字母表
+	DefaultHashIDAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ1234567890"
+
+	hashIDSeps           = "cfhistuCFHISTU"
+	hashIDMinAlphabetLen = 16
+	hashIDSepDiv         = 3.5
+	hashIDGuardDiv       = 12
+)
+
+// 字符类别，与 geohash 编码表相同的查表方式，invalidDigit 表示不属于任何类别
+const (
+	hashIDAlphabet byte = iota
+	hashIDSep
+	hashIDGuard
+)
+
+// ErrHashIDInvalid 字符串不是合法的 HashID
+var ErrHashIDInvalid = errors.New("lib.codec:invalid hash id")
+
+type hashIDConfig struct {
+	salt      string
+	minLength int
+	alphabet  string
+}
+
+// WithHashIDSalt 设置 salt
+func WithHashIDSalt(salt string) xopt.Option[hashIDConfig] {
+	return func(cfg *hashIDConfig) {
+		cfg.salt = salt
+	}
+}
+
+// WithHashIDMinLength 设置编码结果的最小长度
+func WithHashIDMinLength(n int) xopt.Option[hashIDConfig] {
+	return func(cfg *hashIDConfig) {
+		cfg.minLength = n
+	}
+}
+
+// WithHashIDAlphabet 设置字母表，至少 16 个不重复的 ASCII 字符，不能包含空格
+func WithHashIDAlphabet(alphabet string) xopt.Option[hashIDConfig] {
+	return func(cfg *hashIDConfig) {
+		cfg.alphabet = alphabet
+	}
+}
+
+// HashID Hashids 编码器，创建后可并发使用
+type HashID struct {
+	salt      []byte
+	minLength int
+	alphabet  []byte
+	seps      []byte
+	guards    []byte
+	class     [256]byte
+}
+
+// NewHashID 创建 HashID 编码器
+func NewHashID(opts ...xopt.Option[hashIDConfig]) (*HashID, error) {
+	cfg := hashIDConfig{alphabet: DefaultHashIDAlphabet}
+	xopt.Apply(opts, &cfg)
+	if cfg.minLength < 0 {
+		return nil, fmt.Errorf("invalid min length: %d", cfg.minLength)
+	}
+
+	var alphabet []byte
+	var seen [256]bool
+	for i := 0; i < len(cfg.alphabet); i++ {
+		c := cfg.alphabet[i]
+		if c == ' ' || c >= 0x80 {
+			return nil, fmt.Errorf("invalid alphabet character %q", c)
+		}
+		if !seen[c] {
+			seen[c] = true
+			alphabet = append(alphabet, c)
+		}
+	}
+	if len(alphabet) < hashIDMinAlphabetLen {
+		return nil, fmt.Errorf("alphabet must contain at least %d unique characters", hashIDMinAlphabetLen)
+	}
+
+	// 分隔符只取字母表中存在的字符，并从字母表中移除
+	var seps []byte
+	for i := 0; i < len(hashIDSeps); i++ {
+		if seen[hashIDSeps[i]] {
+			seps = append(seps, hashIDSeps[i])
+		}
+	}
+	alphabet = removeBytes(alphabet, seps)
+	salt := []byte(cfg.salt)
+	seps = hashIDShuffle(seps, salt)
+
+	if len(seps) == 0 || float64(len(alphabet))/float64(len(seps)) > hashIDSepDiv {
+		sepsLen := int(math.Ceil(float64(len(alphabet)) / hashIDSepDiv))
+		if sepsLen == 1 {
+			sepsLen++
+		}
+		if sepsLen > len(seps) {
+			diff := sepsLen - len(seps)
+			seps = append(seps, alphabet[:diff]...)
+			alphabet = alphabet[diff:]
+		} else {
+			seps = seps[:sepsLen]
+		}
+	}
+	alphabet = hashIDShuffle(alphabet, salt)
+
+	guardCount := int(math.Ceil(float64(len(alphabet)) / hashIDGuardDiv))
+	var guards []byte
+	if len(alphabet) < 3 {
+		guards, seps = seps[:guardCount], seps[guardCount:]
+	} else {
+		guards, alphabet = alphabet[:guardCount], alphabet[guardCount:]
+	}
+
+	h := &HashID{
+		salt:      salt,
+		minLength: cfg.minLength,
+		alphabet:  alphabet,
+		seps:      seps,
+		guards:    guards,
+	}
+	for i := range h.class {
+		h.class[i] = invalidDigit
+	}
+	for _, c := range alphabet {
+		h.class[c] = hashIDAlphabet
+	}
+	for _, c := range seps {
+		h.class[c] = hashIDSep
+	}
+	for _, c := range guards {
+		h.class[c] = hashIDGuard
+	}
+	return h, nil
+}
+
+// Encode 编码非负整数序列
+func (h *HashID) Encode(numbers ...int64) (string, error) {
+	if len(numbers) == 0 {
+		return "", errors.New("no numbers to encode")
+	}
+	for _, n := range numbers {
+		if n < 0 {
+			return "", fmt.Errorf("negative number %d cannot be encoded", n)
+		}
+	}
+	return h.encode(numbers), nil
+}
+
+// EncodeX 编码非负整数序列，出错时返回空字符串
+func (h *HashID) EncodeX(numbers ...int64) string {
+	s, _ := h.Encode(numbers...)
+	return s
+}
+
+func (h *HashID) encode(numbers []int64) string {
+	var numbersHash int64
+	for i, n := range numbers {
+		numbersHash += n % int64(i+100)
+	}
+
+	alphabet := append([]byte(nil), h.alphabet...)
+	lottery := alphabet[numbersHash%int64(len(alphabet))]
+	ret := []byte{lottery}
+	buffer := make([]byte, 0, 1+len(h.salt)+len(alphabet))
+
+	for i, n := range numbers {
+		buffer = append(append(append(buffer[:0], lottery), h.salt...), alphabet...)
+		alphabet = hashIDShuffle(alphabet, buffer[:len(alphabet)])
+		last := hashIDToAlphabet(n, alphabet)
+		ret = append(ret, last...)
+		if i+1 < len(numbers) {
+			n %= int64(last[0]) + int64(i)
+			ret = append(ret, h.seps[n%int64(len(h.seps))])
+		}
+	}
+
+	if len(ret) < h.minLength {
+		guard := h.guards[(numbersHash+int64(ret[0]))%int64(len(h.guards))]
+		ret = append([]byte{guard}, ret...)
+		if len(ret) < h.minLength {
+			guard = h.guards[(numbersHash+int64(ret[2]))%int64(len(h.guards))]
+			ret = append(ret, guard)
+		}
+	}
+
+	half := len(alphabet) / 2
+	for len(ret) < h.minLength {
+		alphabet = hashIDShuffle(alphabet, append([]byte(nil), alphabet...))
+		padded := make([]byte, 0, len(ret)+len(alphabet))
+		padded = append(padded, alphabet[half:]...)
+		padded = append(padded, ret...)
+		padded = append(padded, alphabet[:half]...)
+		ret = padded
+		if excess := len(ret) - h.minLength; excess > 0 {
+			ret = ret[excess/2 : excess/2+h.minLength]
+		}
+	}
+	return string(ret)
+}
+
+// Decode 解码字符串，被篡改或使用不同 salt 生成的字符串返回 ErrHashIDInvalid
+func (h *HashID) Decode(id string) ([]int64, error) {
+	for i := 0; i < len(id); i++ {
+		if h.class[id[i]] == invalidDigit {
+			return nil, fmt.Errorf("%w: illegal character %q", ErrHashIDInvalid, id[i])
+		}
+	}
+
+	// 去掉守卫字符补足的部分：守卫字符两侧分为 2 或 3 段时取中间一段
+	parts := strings.Split(strings.Map(func(r rune) rune {
+		if h.class[byte(r)] == hashIDGuard {
+			return ' '
+		}
+		return r
+	}, id), " ")
+	breakdown := parts[0]
+	if len(parts) == 2 || len(parts) == 3 {
+		breakdown = parts[1]
+	}
+	if breakdown == "" {
+		return nil, ErrHashIDInvalid
+	}
+
+	lottery := breakdown[0]
+	alphabet := append([]byte(nil), h.alphabet...)
+	buffer := make([]byte, 0, 1+len(h.salt)+len(alphabet))
+	var numbers []int64
+	for sub := range strings.FieldsFuncSeq(breakdown[1:], func(r rune) bool { return h.class[byte(r)] == hashIDSep }) {
+		buffer = append(append(append(buffer[:0], lottery), h.salt...), alphabet...)
+		alphabet = hashIDShuffle(alphabet, buffer[:len(alphabet)])
+		n, ok := hashIDFromAlphabet(sub, alphabet)
+		if !ok {
+			return nil, ErrHashIDInvalid
+		}
+		numbers = append(numbers, n)
+	}
+	if len(numbers) == 0 || h.encode(numbers) != id {
+		return nil, ErrHashIDInvalid
+	}
+	return numbers, nil
+}
+
+// hashIDShuffle Hashids 一致性洗牌，原地修改并返回 alphabet
+func hashIDShuffle(alphabet, salt []byte) []byte {
+	if len(salt) == 0 {
+		return alphabet
+	}
+	for i, v, p := len(alphabet)-1, 0, 0; i > 0; i, v = i-1, v+1 {
+		v %= len(salt)
+		c := int(salt[v])
+		p += c
+		j := (c + v + p) % i
+		alphabet[i], alphabet[j] = alphabet[j], alphabet[i]
+	}
+	return alphabet
+}
+
+func hashIDToAlphabet(n int64, alphabet []byte) []byte {
+	base := int64(len(alphabet))
+	var buf [64]byte
+	i := len(buf)
+	for {
+		i--
+		buf[i] = alphabet[n%base]
+		n /= base
+		if n == 0 {
+			break
+		}
+	}
+	return append([]byte(nil), buf[i:]...)
+}
+
+func hashIDFromAlphabet(s string, alphabet []byte) (int64, bool) {
+	if s == "" {
+		return 0, false
+	}
+	base := int64(len(alphabet))
+	var n int64
+	for i := 0; i < len(s); i++ {
+		idx := strings.IndexByte(string(alphabet), s[i])
+		if idx < 0 || n > (math.MaxInt64-int64(idx))/base {
+			return 0, false
+		}
+		n = n*base + int64(idx)
+	}
+	return n, true
+}
+
+func removeBytes(s, remove []byte) []byte {
+	out := s[:0]
+	for _, c := range s {
+		if !strings.ContainsRune(string(remove), rune(c)) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
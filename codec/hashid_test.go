@@ -0,0 +1,111 @@
+package codec
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestHashID_Vectors(t *testing.T) {
+	// 与 Hashids 参考实现的结果一致
+	h, err := NewHashID(WithHashIDSalt("this is my salt"))
+	if err != nil {
+		t.Fatalf("NewHashID() error = %v", err)
+	}
+	testCases := []struct {
+		numbers  []int64
+		expected string
+	}{
+		{[]int64{12345}, "NkK9"},
+		{[]int64{683, 94108, 123, 5}, "aBMswoO2UB3Sj"},
+	}
+	for _, tc := range testCases {
+		got, err := h.Encode(tc.numbers...)
+		if err != nil || got != tc.expected {
+			t.Errorf("Encode(%v) = %q, %v, want %q", tc.numbers, got, err, tc.expected)
+		}
+		decoded, err := h.Decode(tc.expected)
+		if err != nil || !slices.Equal(decoded, tc.numbers) {
+			t.Errorf("Decode(%q) = %v, %v", tc.expected, decoded, err)
+		}
+	}
+
+	padded, _ := NewHashID(WithHashIDSalt("this is my salt"), WithHashIDMinLength(8))
+	if got := padded.EncodeX(1); got != "gB0NV05e" {
+		t.Errorf("Encode(1) with min length = %q, want %q", got, "gB0NV05e")
+	}
+}
+
+func TestHashID_RoundTrip(t *testing.T) {
+	for _, minLength := range []int{0, 5, 10, 32} {
+		h, err := NewHashID(WithHashIDSalt("orders"), WithHashIDMinLength(minLength))
+		if err != nil {
+			t.Fatalf("NewHashID() error = %v", err)
+		}
+		inputs := [][]int64{{0}, {1}, {2}, {1, 2, 3}, {math.MaxInt64}, {42, 0, math.MaxInt64}}
+		for _, numbers := range inputs {
+			id, err := h.Encode(numbers...)
+			if err != nil {
+				t.Fatalf("Encode(%v) error = %v", numbers, err)
+			}
+			if len(id) < minLength {
+				t.Errorf("Encode(%v) = %q, shorter than %d", numbers, id, minLength)
+			}
+			got, err := h.Decode(id)
+			if err != nil || !slices.Equal(got, numbers) {
+				t.Errorf("Decode(%q) = %v, %v, want %v", id, got, err, numbers)
+			}
+		}
+	}
+
+	// 不同 salt 编码结果不同
+	a, _ := NewHashID(WithHashIDSalt("orders"))
+	b, _ := NewHashID(WithHashIDSalt("users"))
+	if a.EncodeX(1) == b.EncodeX(1) {
+		t.Error("Encode() should depend on salt")
+	}
+}
+
+func TestHashID_Invalid(t *testing.T) {
+	h, _ := NewHashID(WithHashIDSalt("orders"), WithHashIDMinLength(8))
+	id := h.EncodeX(12345)
+
+	other, _ := NewHashID(WithHashIDSalt("users"), WithHashIDMinLength(8))
+	if got, err := other.Decode(id); !errors.Is(err, ErrHashIDInvalid) {
+		t.Errorf("Decode() with other salt = %v, %v, want ErrHashIDInvalid", got, err)
+	}
+
+	tampered := []byte(id)
+	for i := range tampered {
+		orig := tampered[i]
+		for _, c := range []byte("aZ3") {
+			if c == orig {
+				continue
+			}
+			tampered[i] = c
+			if got, err := h.Decode(string(tampered)); err == nil {
+				t.Errorf("Decode(%q) = %v, want error", tampered, got)
+			}
+		}
+		tampered[i] = orig
+	}
+
+	for _, id := range []string{"", "!!!", "a-b"} {
+		if _, err := h.Decode(id); !errors.Is(err, ErrHashIDInvalid) {
+			t.Errorf("Decode(%q) error = %v, want ErrHashIDInvalid", id, err)
+		}
+	}
+	if _, err := h.Encode(-1); err == nil {
+		t.Error("Encode(-1) expected error")
+	}
+	if _, err := h.Encode(); err == nil {
+		t.Error("Encode() expected error for empty input")
+	}
+	if _, err := NewHashID(WithHashIDAlphabet("abc")); err == nil {
+		t.Error("NewHashID() expected error for short alphabet")
+	}
+	if _, err := NewHashID(WithHashIDAlphabet("abcdefghij klmnopqrst")); err == nil {
+		t.Error("NewHashID() expected error for alphabet with space")
+	}
+}
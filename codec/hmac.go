@@ -0,0 +1,88 @@
+package codec
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+)
+
+// HMAC 使用指定算法计算数据的 HMAC，算法必须是加密哈希
+func HMAC(alg HashAlgorithm, key, data []byte) ([]byte, error) {
+	h, err := newHash(alg)
+	if err != nil {
+		return nil, err
+	}
+	if !isCryptoHash(alg) {
+		return nil, fmt.Errorf("%w: %s is not a cryptographic hash", ErrUnknownHash, alg)
+	}
+	mac := hmac.New(h, key)
+	mac.Write(data)
+	return mac.Sum(nil), nil
+}
+
+// VerifyHMAC 使用常量时间比较校验 HMAC
+func VerifyHMAC(alg HashAlgorithm, key, data, sum []byte) bool {
+	expected, err := HMAC(alg, key, data)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(expected, sum)
+}
+
+// verifyHMACHex 校验十六进制编码的 HMAC
+func verifyHMACHex(alg HashAlgorithm, key, data []byte, signature string) bool {
+	sum, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	return VerifyHMAC(alg, key, data, sum)
+}
+
+func hmacHex(alg HashAlgorithm, key, data []byte) (string, error) {
+	sum, err := HMAC(alg, key, data)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum), nil
+}
+
+// stdHMACHex 直接使用标准库哈希计算，不受 RegisterHash 覆盖影响，不会出错
+func stdHMACHex(h func() hash.Hash, key, data []byte) string {
+	mac := hmac.New(h, key)
+	mac.Write(data)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HMACSHA1 计算数据的HMAC-SHA1值
+func HMACSHA1(key, data []byte) string {
+	return stdHMACHex(sha1.New, key, data)
+}
+
+// VerifyHMACSHA1 校验十六进制编码的HMAC-SHA1值
+func VerifyHMACSHA1(key, data []byte, signature string) bool {
+	return verifyHMACHex(HashSHA1, key, data, signature)
+}
+
+// HMACSHA256 计算数据的HMAC-SHA256值
+func HMACSHA256(key, data []byte) string {
+	return stdHMACHex(sha256.New, key, data)
+}
+
+// VerifyHMACSHA256 校验十六进制编码的HMAC-SHA256值
+func VerifyHMACSHA256(key, data []byte, signature string) bool {
+	return verifyHMACHex(HashSHA256, key, data, signature)
+}
+
+// HMACSHA512 计算数据的HMAC-SHA512值
+func HMACSHA512(key, data []byte) string {
+	return stdHMACHex(sha512.New, key, data)
+}
+
+// VerifyHMACSHA512 校验十六进制编码的HMAC-SHA512值
+func VerifyHMACSHA512(key, data []byte, signature string) bool {
+	return verifyHMACHex(HashSHA512, key, data, signature)
+}
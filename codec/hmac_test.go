@@ -0,0 +1,43 @@
+package codec
+
+import (
+	"testing"
+)
+
+func TestHMAC(t *testing.T) {
+	// RFC 4231 测试用例 2
+	key := []byte("Jefe")
+	data := []byte("what do ya want for nothing?")
+
+	testCases := []struct {
+		name     string
+		sign     func(key, data []byte) string
+		verify   func(key, data []byte, signature string) bool
+		expected string
+	}{
+		{"SHA1", HMACSHA1, VerifyHMACSHA1, "effcdf6ae5eb2fa2d27416d5f184df9c259a7c79"},
+		{"SHA256", HMACSHA256, VerifyHMACSHA256, "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"},
+		{"SHA512", HMACSHA512, VerifyHMACSHA512, "164b7a7bfcf819e2e395fbe73b56e0a387bd64222e831fd610270cd7ea2505549758bf75c05a994a6d034f65f8f0e6fdcaeab1a34d4a6b4b636e070a38bce737"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.sign(key, data); got != tc.expected {
+				t.Errorf("HMAC%s() = %q, want %q", tc.name, got, tc.expected)
+			}
+			if !tc.verify(key, data, tc.expected) {
+				t.Errorf("VerifyHMAC%s() = false, want true", tc.name)
+			}
+			if tc.verify([]byte("other"), data, tc.expected) {
+				t.Errorf("VerifyHMAC%s() with wrong key = true", tc.name)
+			}
+			if tc.verify(key, data, "not hex") {
+				t.Errorf("VerifyHMAC%s() with invalid signature = true", tc.name)
+			}
+		})
+	}
+
+	if _, err := HMAC("md4", key, data); err == nil {
+		t.Error("HMAC() expected error for unknown algorithm")
+	}
+}
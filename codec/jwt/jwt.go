@@ -0,0 +1,307 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/monaco-io/lib/typing/xjson"
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+// Algorithm JWS 签名算法
+type Algorithm string
+
+const (
+	HS256 Algorithm = "HS256"
+	HS384 Algorithm = "HS384"
+	HS512 Algorithm = "HS512"
+	RS256 Algorithm = "RS256"
+	ES256 Algorithm = "ES256"
+	EdDSA Algorithm = "EdDSA"
+)
+
+var (
+	ErrTokenMalformed       = errors.New("lib.codec.jwt:token is malformed")
+	ErrTokenSignature       = errors.New("lib.codec.jwt:token signature is invalid")
+	ErrTokenExpired         = errors.New("lib.codec.jwt:token is expired")
+	ErrTokenNotValidYet     = errors.New("lib.codec.jwt:token is not valid yet")
+	ErrTokenUsedBeforeIssue = errors.New("lib.codec.jwt:token used before issued")
+	ErrTokenIssuer          = errors.New("lib.codec.jwt:token has invalid issuer")
+	ErrTokenAudience        = errors.New("lib.codec.jwt:token has invalid audience")
+	ErrTokenRequiredClaim   = errors.New("lib.codec.jwt:token is missing required claim")
+	ErrUnsupportedAlgorithm = errors.New("lib.codec.jwt:unsupported algorithm")
+	ErrInvalidKey           = errors.New("lib.codec.jwt:invalid key")
+	ErrKeyNotFound          = errors.New("lib.codec.jwt:key not found")
+	ErrInvalidJWKS          = errors.New("lib.codec.jwt:invalid jwks")
+)
+
+// Header JOSE 头
+type Header struct {
+	Algorithm Algorithm `json:"alg"`
+	Type      string    `json:"typ,omitempty"`
+	KeyID     string    `json:"kid,omitempty"`
+}
+
+// Token 解析并校验通过的 token
+type Token[T Claims] struct {
+	Raw    string
+	Header Header
+	Claims T
+}
+
+// Sign 使用密钥签发 token，头部的 alg 和 kid 取自密钥
+func Sign(claims any, key *Key) (string, error) {
+	if err := key.validate(); err != nil {
+		return "", err
+	}
+	header, err := xjson.Marshal(Header{Algorithm: key.Algorithm, Type: "JWT", KeyID: key.ID})
+	if err != nil {
+		return "", err
+	}
+	payload, err := xjson.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signingInput := encodeSegment(header) + "." + encodeSegment(payload)
+	sig, err := sign(key, []byte(signingInput))
+	if err != nil {
+		return "", err
+	}
+	return signingInput + "." + encodeSegment(sig), nil
+}
+
+type parseConfig struct {
+	algorithms []Algorithm
+	issuer     string
+	audience   string
+	leeway     time.Duration
+	now        func() time.Time
+	requireExp bool
+}
+
+// WithAlgorithms 限制允许的算法，默认只要求与密钥算法一致
+func WithAlgorithms(algs ...Algorithm) xopt.Option[parseConfig] {
+	return func(cfg *parseConfig) {
+		cfg.algorithms = algs
+	}
+}
+
+// WithIssuer 校验 iss
+func WithIssuer(iss string) xopt.Option[parseConfig] {
+	return func(cfg *parseConfig) {
+		cfg.issuer = iss
+	}
+}
+
+// WithAudience 校验 aud 包含指定受众
+func WithAudience(aud string) xopt.Option[parseConfig] {
+	return func(cfg *parseConfig) {
+		cfg.audience = aud
+	}
+}
+
+// WithLeeway 设置校验 exp/nbf/iat 时允许的时钟偏差
+func WithLeeway(d time.Duration) xopt.Option[parseConfig] {
+	return func(cfg *parseConfig) {
+		cfg.leeway = d
+	}
+}
+
+// WithTimeFunc 设置时间函数，便于测试
+func WithTimeFunc(now func() time.Time) xopt.Option[parseConfig] {
+	return func(cfg *parseConfig) {
+		cfg.now = now
+	}
+}
+
+// WithExpirationRequired 要求 token 必须包含 exp
+func WithExpirationRequired() xopt.Option[parseConfig] {
+	return func(cfg *parseConfig) {
+		cfg.requireExp = true
+	}
+}
+
+// Parse 解析 token，根据头部 kid 从密钥集合中选择密钥验签，并校验注册声明
+func Parse[T Claims](token string, keys *KeySet, opts ...xopt.Option[parseConfig]) (*Token[T], error) {
+	cfg := parseConfig{now: time.Now}
+	xopt.Apply(opts, &cfg)
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: token must have 3 segments", ErrTokenMalformed)
+	}
+	headerBytes, err := decodeSegment(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: header: %w", ErrTokenMalformed, err)
+	}
+	var header Header
+	if err := xjson.Unmarshal(headerBytes, &header); err != nil {
+		return nil, fmt.Errorf("%w: header: %w", ErrTokenMalformed, err)
+	}
+	sig, err := decodeSegment(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature: %w", ErrTokenMalformed, err)
+	}
+
+	// 算法必须与密钥一致，防止算法混淆攻击
+	if len(cfg.algorithms) > 0 && !slices.Contains(cfg.algorithms, header.Algorithm) {
+		return nil, fmt.Errorf("%w: %q is not allowed", ErrUnsupportedAlgorithm, header.Algorithm)
+	}
+	key, err := keys.Lookup(header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if header.Algorithm != key.Algorithm {
+		return nil, fmt.Errorf("%w: header alg %q does not match key alg %q", ErrTokenSignature, header.Algorithm, key.Algorithm)
+	}
+	if err := verify(key, []byte(parts[0]+"."+parts[1]), sig); err != nil {
+		return nil, err
+	}
+
+	payload, err := decodeSegment(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: payload: %w", ErrTokenMalformed, err)
+	}
+	var claims T
+	if err := xjson.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("%w: payload: %w", ErrTokenMalformed, err)
+	}
+	if err := cfg.validate(claims.Registered()); err != nil {
+		return nil, err
+	}
+	return &Token[T]{Raw: token, Header: header, Claims: claims}, nil
+}
+
+func (cfg *parseConfig) validate(c RegisteredClaims) error {
+	now := cfg.now()
+	if c.ExpiresAt == nil {
+		if cfg.requireExp {
+			return fmt.Errorf("%w: exp", ErrTokenRequiredClaim)
+		}
+	} else if !now.Before(c.ExpiresAt.Add(cfg.leeway)) {
+		return ErrTokenExpired
+	}
+	if c.NotBefore != nil && now.Add(cfg.leeway).Before(c.NotBefore.Time) {
+		return ErrTokenNotValidYet
+	}
+	if c.IssuedAt != nil && now.Add(cfg.leeway).Before(c.IssuedAt.Time) {
+		return ErrTokenUsedBeforeIssue
+	}
+	if cfg.issuer != "" && c.Issuer != cfg.issuer {
+		return fmt.Errorf("%w: %q", ErrTokenIssuer, c.Issuer)
+	}
+	if cfg.audience != "" && !c.Audience.Contains(cfg.audience) {
+		return fmt.Errorf("%w: %v", ErrTokenAudience, []string(c.Audience))
+	}
+	return nil
+}
+
+func hmacHash(alg Algorithm) func() hash.Hash {
+	switch alg {
+	case HS384:
+		return sha512.New384
+	case HS512:
+		return sha512.New
+	}
+	return sha256.New
+}
+
+func sign(key *Key, input []byte) ([]byte, error) {
+	switch key.Algorithm {
+	case HS256, HS384, HS512:
+		secret, _ := key.key.([]byte)
+		mac := hmac.New(hmacHash(key.Algorithm), secret)
+		mac.Write(input)
+		return mac.Sum(nil), nil
+	case RS256:
+		priv, ok := key.key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: RS256 signing requires *rsa.PrivateKey", ErrInvalidKey)
+		}
+		digest := sha256.Sum256(input)
+		return rsa.SignPKCS1v15(rand.Reader, priv, crypto.SHA256, digest[:])
+	case ES256:
+		priv, ok := key.key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: ES256 signing requires *ecdsa.PrivateKey", ErrInvalidKey)
+		}
+		digest := sha256.Sum256(input)
+		r, s, err := ecdsa.Sign(rand.Reader, priv, digest[:])
+		if err != nil {
+			return nil, err
+		}
+		// JWS 使用定长 r||s 格式
+		sig := make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+		return sig, nil
+	case EdDSA:
+		priv, ok := key.key.(ed25519.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: EdDSA signing requires ed25519.PrivateKey", ErrInvalidKey)
+		}
+		return ed25519.Sign(priv, input), nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, key.Algorithm)
+}
+
+func verify(key *Key, input, sig []byte) error {
+	// Key{} 字面量等未经 NewKey 校验的密钥，空的 HMAC 密钥会让任何人都能伪造签名
+	if err := key.validate(); err != nil {
+		return err
+	}
+	key = key.Public()
+	switch key.Algorithm {
+	case HS256, HS384, HS512:
+		expected, err := sign(key, input)
+		if err != nil {
+			return err
+		}
+		if !hmac.Equal(expected, sig) {
+			return ErrTokenSignature
+		}
+	case RS256:
+		pub, _ := key.key.(*rsa.PublicKey)
+		digest := sha256.Sum256(input)
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) != nil {
+			return ErrTokenSignature
+		}
+	case ES256:
+		pub, _ := key.key.(*ecdsa.PublicKey)
+		if len(sig) != 64 {
+			return ErrTokenSignature
+		}
+		digest := sha256.Sum256(input)
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return ErrTokenSignature
+		}
+	case EdDSA:
+		pub, _ := key.key.(ed25519.PublicKey)
+		if !ed25519.Verify(pub, input, sig) {
+			return ErrTokenSignature
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, key.Algorithm)
+	}
+	return nil
+}
+
+func encodeSegment(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,212 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+type userClaims struct {
+	RegisteredClaims
+	Name  string   `json:"name"`
+	Roles []string `json:"roles"`
+}
+
+func testKeys(t *testing.T) []*Key {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	var keys []*Key
+	for _, k := range []struct {
+		alg Algorithm
+		key any
+	}{
+		{HS256, []byte("secret-256")},
+		{HS384, []byte("secret-384")},
+		{HS512, []byte("secret-512")},
+		{RS256, rsaKey},
+		{ES256, ecKey},
+		{EdDSA, edKey},
+	} {
+		key, err := NewKey(string(k.alg), k.alg, k.key)
+		if err != nil {
+			t.Fatalf("NewKey(%s) error = %v", k.alg, err)
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func TestSignParse(t *testing.T) {
+	now := time.Now()
+	claims := userClaims{
+		RegisteredClaims: RegisteredClaims{
+			Issuer:    "auth",
+			Subject:   "42",
+			Audience:  Audience{"api"},
+			ExpiresAt: NewNumericDate(now.Add(time.Hour)),
+			IssuedAt:  NewNumericDate(now),
+		},
+		Name:  "alice",
+		Roles: []string{"admin"},
+	}
+
+	keys := testKeys(t)
+	// 验签方只持有公钥
+	public := NewKeySet()
+	for _, k := range keys {
+		public.Add(k.Public())
+	}
+
+	for _, key := range keys {
+		t.Run(string(key.Algorithm), func(t *testing.T) {
+			token, err := Sign(claims, key)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			parsed, err := Parse[userClaims](token, public, WithIssuer("auth"), WithAudience("api"))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if parsed.Header.KeyID != key.ID || parsed.Claims.Name != "alice" || parsed.Claims.Subject != "42" {
+				t.Errorf("Parse() = %+v", parsed)
+			}
+
+			// 篡改 payload
+			parts := strings.Split(token, ".")
+			forged, _ := Sign(userClaims{Name: "mallory"}, &Key{ID: key.ID, Algorithm: HS256, key: []byte("x")})
+			tampered := parts[0] + "." + strings.Split(forged, ".")[1] + "." + parts[2]
+			if _, err := Parse[userClaims](tampered, public); !errors.Is(err, ErrTokenSignature) {
+				t.Errorf("Parse() tampered error = %v, want ErrTokenSignature", err)
+			}
+		})
+	}
+
+	if _, err := Sign(claims, keys[3].Public()); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Sign() with public key error = %v, want ErrInvalidKey", err)
+	}
+}
+
+func TestParseAlgorithmConfusion(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	key, _ := NewKey("k1", RS256, rsaKey)
+	keys := NewKeySet(key.Public())
+
+	// 使用 HS256 伪造 kid 相同的 token
+	forged, _ := Sign(RegisteredClaims{Subject: "x"}, &Key{ID: "k1", Algorithm: HS256, key: []byte("guess")})
+	if _, err := Parse[RegisteredClaims](forged, keys); !errors.Is(err, ErrTokenSignature) {
+		t.Errorf("Parse() error = %v, want ErrTokenSignature", err)
+	}
+
+	token, _ := Sign(RegisteredClaims{Subject: "x"}, key)
+	if _, err := Parse[RegisteredClaims](token, keys, WithAlgorithms(ES256)); !errors.Is(err, ErrUnsupportedAlgorithm) {
+		t.Errorf("Parse() error = %v, want ErrUnsupportedAlgorithm", err)
+	}
+
+	none := "eyJhbGciOiJub25lIn0.eyJzdWIiOiJ4In0."
+	if _, err := Parse[RegisteredClaims](none, keys); err == nil {
+		t.Error("Parse() expected error for alg none")
+	}
+}
+
+func TestParseValidation(t *testing.T) {
+	key, _ := NewKey("k1", HS256, []byte("secret"))
+	keys := NewKeySet(key)
+	now := time.Unix(1700000000, 0)
+	at := func(d time.Duration) *NumericDate { return NewNumericDate(now.Add(d)) }
+	clock := WithTimeFunc(func() time.Time { return now })
+
+	tests := []struct {
+		name   string
+		claims RegisteredClaims
+		opts   []xopt.Option[parseConfig]
+		want   error
+	}{
+		{"valid", RegisteredClaims{ExpiresAt: at(time.Minute)}, nil, nil},
+		{"expired", RegisteredClaims{ExpiresAt: at(-time.Minute)}, nil, ErrTokenExpired},
+		{"expired within leeway", RegisteredClaims{ExpiresAt: at(-time.Minute)}, []xopt.Option[parseConfig]{WithLeeway(2 * time.Minute)}, nil},
+		{"not before", RegisteredClaims{NotBefore: at(time.Minute)}, nil, ErrTokenNotValidYet},
+		{"issued in future", RegisteredClaims{IssuedAt: at(time.Minute)}, nil, ErrTokenUsedBeforeIssue},
+		{"issued in future within leeway", RegisteredClaims{IssuedAt: at(time.Minute)}, []xopt.Option[parseConfig]{WithLeeway(time.Minute)}, nil},
+		{"issuer", RegisteredClaims{Issuer: "other"}, []xopt.Option[parseConfig]{WithIssuer("auth")}, ErrTokenIssuer},
+		{"audience", RegisteredClaims{Audience: Audience{"a", "b"}}, []xopt.Option[parseConfig]{WithAudience("c")}, ErrTokenAudience},
+		{"audience list", RegisteredClaims{Audience: Audience{"a", "b"}}, []xopt.Option[parseConfig]{WithAudience("b")}, nil},
+		{"exp required", RegisteredClaims{}, []xopt.Option[parseConfig]{WithExpirationRequired()}, ErrTokenRequiredClaim},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := Sign(tt.claims, key)
+			if err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+			_, err = Parse[RegisteredClaims](token, keys, append(tt.opts, clock)...)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	for _, token := range []string{"", "a.b", "!!.e30.", "e30.e30.sig"} {
+		if _, err := Parse[RegisteredClaims](token, keys); err == nil {
+			t.Errorf("Parse(%q) expected error", token)
+		}
+	}
+	other, _ := Sign(RegisteredClaims{}, &Key{ID: "k2", Algorithm: HS256, key: []byte("secret")})
+	if _, err := Parse[RegisteredClaims](other, keys); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Parse() error = %v, want ErrKeyNotFound", err)
+	}
+}
+
+func TestNewKeyInvalid(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	small, _ := rsa.GenerateKey(rand.Reader, 1024)
+	for _, k := range []struct {
+		alg Algorithm
+		key any
+	}{
+		{HS256, []byte{}},
+		{HS256, "string"},
+		{RS256, small},
+		{ES256, ecKey},
+		{EdDSA, []byte("short")},
+		{"none", []byte("x")},
+	} {
+		if _, err := NewKey("k", k.alg, k.key); err == nil {
+			t.Errorf("NewKey(%s, %T) expected error", k.alg, k.key)
+		}
+	}
+}
+
+func TestNilKey(t *testing.T) {
+	signer, _ := NewKey("k", HS256, []byte("secret"))
+	token, err := Sign(RegisteredClaims{Subject: "s"}, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Parse[RegisteredClaims](token, nil); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Parse(nil KeySet) error = %v, want ErrInvalidKey", err)
+	}
+	// 未经 NewKey 构造的空密钥不能用于验证，否则任何人都能伪造 HMAC 签名
+	if _, err := Parse[RegisteredClaims](token, NewKeySet(&Key{ID: "k", Algorithm: HS256})); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Parse(Key{}) error = %v, want ErrInvalidKey", err)
+	}
+	for _, k := range []*Key{nil, {Algorithm: HS256}, {Algorithm: RS256}, {Algorithm: ES256, key: (*ecdsa.PublicKey)(nil)}} {
+		if _, err := Sign(RegisteredClaims{}, k); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Sign(%v) error = %v, want ErrInvalidKey", k, err)
+		}
+	}
+}
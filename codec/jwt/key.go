@@ -0,0 +1,334 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/monaco-io/lib/typing/xjson"
+)
+
+// Key 带 kid 和算法的密钥
+//
+// HS* 使用 []byte；RS256 使用 *rsa.PrivateKey/*rsa.PublicKey；
+// ES256 使用 *ecdsa.PrivateKey/*ecdsa.PublicKey (P-256)；EdDSA 使用 ed25519.PrivateKey/ed25519.PublicKey
+type Key struct {
+	ID        string
+	Algorithm Algorithm
+	key       any
+}
+
+// NewKey 创建密钥并校验密钥类型与算法是否匹配
+func NewKey(kid string, alg Algorithm, key any) (*Key, error) {
+	k := &Key{ID: kid, Algorithm: alg, key: key}
+	if err := k.validate(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+func (k *Key) validate() error {
+	if k == nil || k.key == nil {
+		return fmt.Errorf("%w: nil key", ErrInvalidKey)
+	}
+	switch k.Algorithm {
+	case HS256, HS384, HS512:
+		if b, ok := k.key.([]byte); !ok || len(b) == 0 {
+			return fmt.Errorf("%w: %s requires non-empty []byte key", ErrInvalidKey, k.Algorithm)
+		}
+	case RS256:
+		switch key := k.key.(type) {
+		case *rsa.PrivateKey:
+			if key == nil || key.N == nil || key.N.BitLen() < 2048 {
+				return fmt.Errorf("%w: rsa key must be at least 2048 bits", ErrInvalidKey)
+			}
+		case *rsa.PublicKey:
+			if key == nil || key.N == nil || key.N.BitLen() < 2048 {
+				return fmt.Errorf("%w: rsa key must be at least 2048 bits", ErrInvalidKey)
+			}
+		default:
+			return fmt.Errorf("%w: %s requires rsa key, got %T", ErrInvalidKey, k.Algorithm, k.key)
+		}
+	case ES256:
+		var curve elliptic.Curve
+		switch key := k.key.(type) {
+		case *ecdsa.PrivateKey:
+			if key != nil {
+				curve = key.Curve
+			}
+		case *ecdsa.PublicKey:
+			if key != nil {
+				curve = key.Curve
+			}
+		default:
+			return fmt.Errorf("%w: %s requires ecdsa key, got %T", ErrInvalidKey, k.Algorithm, k.key)
+		}
+		if curve != elliptic.P256() {
+			return fmt.Errorf("%w: %s requires P-256 curve", ErrInvalidKey, k.Algorithm)
+		}
+	case EdDSA:
+		switch key := k.key.(type) {
+		case ed25519.PrivateKey:
+			if len(key) != ed25519.PrivateKeySize {
+				return fmt.Errorf("%w: invalid ed25519 private key size", ErrInvalidKey)
+			}
+		case ed25519.PublicKey:
+			if len(key) != ed25519.PublicKeySize {
+				return fmt.Errorf("%w: invalid ed25519 public key size", ErrInvalidKey)
+			}
+		default:
+			return fmt.Errorf("%w: %s requires ed25519 key, got %T", ErrInvalidKey, k.Algorithm, k.key)
+		}
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedAlgorithm, k.Algorithm)
+	}
+	return nil
+}
+
+// Public 返回只能用于验签的公钥，HS* 密钥原样返回
+func (k *Key) Public() *Key {
+	switch key := k.key.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey, ed25519.PrivateKey:
+		return &Key{ID: k.ID, Algorithm: k.Algorithm, key: key.(crypto.Signer).Public()}
+	}
+	return k
+}
+
+// KeySet 按 kid 管理的密钥集合
+type KeySet struct {
+	mu   sync.RWMutex
+	keys map[string]*Key
+}
+
+// NewKeySet 创建密钥集合
+func NewKeySet(keys ...*Key) *KeySet {
+	ks := &KeySet{keys: make(map[string]*Key, len(keys))}
+	for _, k := range keys {
+		ks.Add(k)
+	}
+	return ks
+}
+
+// Add 添加或替换密钥，nil 被忽略
+func (ks *KeySet) Add(k *Key) {
+	if k == nil {
+		return
+	}
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if ks.keys == nil {
+		ks.keys = make(map[string]*Key)
+	}
+	ks.keys[k.ID] = k
+}
+
+// Remove 移除密钥
+func (ks *KeySet) Remove(kid string) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	delete(ks.keys, kid)
+}
+
+// Lookup 根据 kid 查找密钥，token 没有 kid 且集合中只有一个密钥时返回该密钥
+func (ks *KeySet) Lookup(kid string) (*Key, error) {
+	if ks == nil {
+		return nil, fmt.Errorf("%w: nil key set", ErrInvalidKey)
+	}
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	if k, ok := ks.keys[kid]; ok {
+		return k, nil
+	}
+	if kid == "" && len(ks.keys) == 1 {
+		for _, k := range ks.keys {
+			return k, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrKeyNotFound, kid)
+}
+
+// Len 密钥数量
+func (ks *KeySet) Len() int {
+	if ks == nil {
+		return 0
+	}
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	return len(ks.keys)
+}
+
+// errUnsupportedJWK 不支持的 kty 或曲线，解析 JWKS 时跳过
+var errUnsupportedJWK = errors.New("unsupported jwk")
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	Crv string `json:"crv"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	D   string `json:"d"`
+	K   string `json:"k"`
+}
+
+// ParseJWKS 解析本地 JWKS 文档，支持 RSA、EC P-256、OKP Ed25519 和 oct 密钥
+// use 不是 sig 的密钥，以及不支持的 kty、alg、crv 会被忽略，格式错误的密钥返回 ErrInvalidJWKS
+func ParseJWKS(data []byte) (*KeySet, error) {
+	var doc struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := xjson.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidJWKS, err)
+	}
+	ks := NewKeySet()
+	for i, j := range doc.Keys {
+		if j.Use != "" && j.Use != "sig" {
+			continue
+		}
+		k, err := j.key()
+		if errors.Is(err, errUnsupportedJWK) || errors.Is(err, ErrUnsupportedAlgorithm) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: key %d (%s): %w", ErrInvalidJWKS, i, j.Kid, err)
+		}
+		ks.Add(k)
+	}
+	return ks, nil
+}
+
+func (j jwk) key() (*Key, error) {
+	var (
+		alg Algorithm
+		key any
+		err error
+	)
+	switch j.Kty {
+	case "RSA":
+		alg = RS256
+		key, err = j.rsaKey()
+	case "EC":
+		alg = ES256
+		key, err = j.ecKey()
+	case "OKP":
+		alg = EdDSA
+		key, err = j.okpKey()
+	case "oct":
+		alg = HS256
+		key, err = decodeSegment(j.K)
+	default:
+		return nil, fmt.Errorf("%w: kty %q", errUnsupportedJWK, j.Kty)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if j.Alg != "" {
+		alg = Algorithm(j.Alg)
+	}
+	return NewKey(j.Kid, alg, key)
+}
+
+func (j jwk) rsaKey() (any, error) {
+	n, err := decodeBigInt(j.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := decodeBigInt(j.E)
+	if err != nil {
+		return nil, err
+	}
+	if !e.IsInt64() || e.Int64() > 1<<31-1 {
+		return nil, errors.New("rsa exponent too large")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (j jwk) ecKey() (any, error) {
+	if j.Crv != "P-256" {
+		return nil, fmt.Errorf("%w: crv %q", errUnsupportedJWK, j.Crv)
+	}
+	x, err := decodeSegment(j.X)
+	if err != nil {
+		return nil, err
+	}
+	y, err := decodeSegment(j.Y)
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != 32 || len(y) != 32 {
+		return nil, errors.New("invalid P-256 coordinate length")
+	}
+	// 未压缩点格式，同时校验点是否在曲线上
+	pub, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), append(append([]byte{4}, x...), y...))
+	if err != nil {
+		return nil, err
+	}
+	if j.D == "" {
+		return pub, nil
+	}
+	d, err := decodeSegment(j.D)
+	if err != nil {
+		return nil, err
+	}
+	priv, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), d)
+	if err != nil {
+		return nil, err
+	}
+	if !priv.PublicKey.Equal(pub) {
+		return nil, errors.New("ec private key does not match public key")
+	}
+	return priv, nil
+}
+
+func (j jwk) okpKey() (any, error) {
+	if j.Crv != "Ed25519" {
+		return nil, fmt.Errorf("%w: crv %q", errUnsupportedJWK, j.Crv)
+	}
+	x, err := decodeSegment(j.X)
+	if err != nil {
+		return nil, err
+	}
+	if len(x) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid ed25519 public key size")
+	}
+	if j.D == "" {
+		return ed25519.PublicKey(x), nil
+	}
+	d, err := decodeSegment(j.D)
+	if err != nil {
+		return nil, err
+	}
+	if len(d) != ed25519.SeedSize {
+		return nil, errors.New("invalid ed25519 seed size")
+	}
+	priv := ed25519.NewKeyFromSeed(d)
+	if !priv.Public().(ed25519.PublicKey).Equal(ed25519.PublicKey(x)) {
+		return nil, errors.New("ed25519 private key does not match public key")
+	}
+	return priv, nil
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := decodeSegment(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty integer")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
+
+func decodeSegment(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
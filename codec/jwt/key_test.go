@@ -0,0 +1,109 @@
+package jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestParseJWKS_RFC7515(t *testing.T) {
+	// RFC 7515 附录 A.1
+	doc := `{"keys":[{"kty":"oct","kid":"hmac","k":"AyM1SysPpbyDfgZld3umj1qzKObwVMkoqQ-EstJQLr_T-1qS0gZH75aKtMN3Yj0iPS4hcgUuTwjAzZr1Z9CAow"}]}`
+	keys, err := ParseJWKS([]byte(doc))
+	if err != nil {
+		t.Fatalf("ParseJWKS() error = %v", err)
+	}
+	token := "eyJ0eXAiOiJKV1QiLA0KICJhbGciOiJIUzI1NiJ9" +
+		".eyJpc3MiOiJqb2UiLA0KICJleHAiOjEzMDA4MTkzODAsDQogImh0dHA6Ly9leGFtcGxlLmNvbS9pc19yb290Ijp0cnVlfQ" +
+		".dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+	at := func(sec int64) func() time.Time { return func() time.Time { return time.Unix(sec, 0) } }
+	parsed, err := Parse[RegisteredClaims](token, keys, WithIssuer("joe"), WithTimeFunc(at(1300819379)))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if parsed.Claims.ExpiresAt.Unix() != 1300819380 {
+		t.Errorf("exp = %v", parsed.Claims.ExpiresAt)
+	}
+	if _, err := Parse[RegisteredClaims](token, keys, WithTimeFunc(at(1300819380))); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("Parse() error = %v, want ErrTokenExpired", err)
+	}
+}
+
+func TestParseJWKS(t *testing.T) {
+	enc := base64.RawURLEncoding.EncodeToString
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	edPub, edKey, _ := ed25519.GenerateKey(rand.Reader)
+	ecPub, _ := ecKey.PublicKey.Bytes()
+
+	doc := fmt.Sprintf(`{"keys":[
+		{"kty":"RSA","kid":"rsa","use":"sig","alg":"RS256","n":%q,"e":"AQAB"},
+		{"kty":"EC","kid":"ec","crv":"P-256","x":%q,"y":%q},
+		{"kty":"OKP","kid":"ed","crv":"Ed25519","x":%q},
+		{"kty":"RSA","kid":"enc","use":"enc","n":"AQAB","e":"AQAB"},
+		{"kty":"EC","kid":"p384","crv":"P-384","x":"AA","y":"AA"},
+		{"kty":"RSA","kid":"ps","alg":"PS256","n":"AQAB","e":"AQAB"},
+		{"kty":"foo","kid":"foo"}
+	]}`, enc(rsaKey.N.Bytes()), enc(ecPub[1:33]), enc(ecPub[33:]), enc(edPub))
+
+	keys, err := ParseJWKS([]byte(doc))
+	if err != nil {
+		t.Fatalf("ParseJWKS() error = %v", err)
+	}
+	if keys.Len() != 3 {
+		t.Errorf("Len() = %d, want 3 (use=enc and unsupported keys ignored)", keys.Len())
+	}
+
+	for kid, priv := range map[string]any{"rsa": rsaKey, "ec": ecKey, "ed": edKey} {
+		pub, err := keys.Lookup(kid)
+		if err != nil {
+			t.Fatalf("Lookup(%s) error = %v", kid, err)
+		}
+		signer, _ := NewKey(kid, pub.Algorithm, priv)
+		token, err := Sign(RegisteredClaims{Subject: kid}, signer)
+		if err != nil {
+			t.Fatalf("Sign() error = %v", err)
+		}
+		parsed, err := Parse[RegisteredClaims](token, keys)
+		if err != nil || parsed.Claims.Subject != kid {
+			t.Errorf("Parse(%s) = %v, %v", kid, parsed, err)
+		}
+	}
+
+	invalid := []string{
+		`not json`,
+		`{"keys":[{"kty":"EC","crv":"P-256","x":"` + enc(make([]byte, 32)) + `","y":"` + enc(make([]byte, 32)) + `"}]}`,
+		`{"keys":[{"kty":"OKP","crv":"Ed25519","x":"AAAA"}]}`,
+		`{"keys":[{"kty":"RSA","n":"!","e":"AQAB"}]}`,
+	}
+	for _, doc := range invalid {
+		if _, err := ParseJWKS([]byte(doc)); !errors.Is(err, ErrInvalidJWKS) {
+			t.Errorf("ParseJWKS(%s) error = %v, want ErrInvalidJWKS", doc, err)
+		}
+	}
+}
+
+func TestAudienceJSON(t *testing.T) {
+	var c RegisteredClaims
+	for _, payload := range []string{`{"aud":"api"}`, `{"aud":["api","web"]}`} {
+		keys := NewKeySet(&Key{ID: "k", Algorithm: HS256, key: []byte("s")})
+		token := "eyJhbGciOiJIUzI1NiIsImtpZCI6ImsifQ." + base64.RawURLEncoding.EncodeToString([]byte(payload))
+		sig, _ := sign(&Key{Algorithm: HS256, key: []byte("s")}, []byte(token))
+		parsed, err := Parse[RegisteredClaims](token+"."+base64.RawURLEncoding.EncodeToString(sig), keys, WithAudience("api"))
+		if err != nil {
+			t.Fatalf("Parse(%s) error = %v", payload, err)
+		}
+		c = parsed.Claims
+	}
+	if len(c.Audience) != 2 {
+		t.Errorf("Audience = %v", c.Audience)
+	}
+}
@@ -0,0 +1,302 @@
+// Package otp 实现 RFC 4226 (HOTP) 和 RFC 6238 (TOTP) 一次性密码
+package otp
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/monaco-io/lib/codec"
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+// DefaultSecretSize 默认密钥长度 20 字节 (160 位)，与 HMAC-SHA1 输出长度一致
+const DefaultSecretSize = 20
+
+var (
+	// ErrInvalidCode 验证码错误
+	ErrInvalidCode = errors.New("lib.codec.otp:invalid code")
+	// ErrCodeReplayed 验证码已经使用过
+	ErrCodeReplayed = errors.New("lib.codec.otp:code already used")
+	// ErrInvalidSecret 密钥不是合法的 base32
+	ErrInvalidSecret = errors.New("lib.codec.otp:invalid secret")
+)
+
+type config struct {
+	digits    int
+	period    time.Duration
+	algorithm codec.HashAlgorithm
+	skew      uint
+	now       func() time.Time
+	guard     ReplayGuard
+}
+
+func newConfig(opts ...xopt.Option[config]) (config, error) {
+	cfg := config{
+		digits:    6,
+		period:    30 * time.Second,
+		algorithm: codec.HashSHA1,
+		skew:      1,
+		now:       time.Now,
+	}
+	xopt.Apply(opts, &cfg)
+	if cfg.digits < 6 || cfg.digits > 8 {
+		return cfg, fmt.Errorf("invalid digits: %d, must be between 6 and 8", cfg.digits)
+	}
+	if cfg.period < time.Second {
+		return cfg, fmt.Errorf("invalid period: %s, must be at least 1s", cfg.period)
+	}
+	switch cfg.algorithm {
+	case codec.HashSHA1, codec.HashSHA256, codec.HashSHA512:
+	default:
+		return cfg, fmt.Errorf("unsupported algorithm: %s", cfg.algorithm)
+	}
+	return cfg, nil
+}
+
+// WithDigits 设置验证码位数，默认 6 位
+func WithDigits(digits int) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.digits = digits
+	}
+}
+
+// WithPeriod 设置 TOTP 时间步长，默认 30 秒
+func WithPeriod(period time.Duration) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.period = period
+	}
+}
+
+// WithAlgorithm 设置 HMAC 算法，支持 sha1、sha256、sha512，默认 sha1
+func WithAlgorithm(alg codec.HashAlgorithm) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.algorithm = alg
+	}
+}
+
+// WithSkew 设置校验窗口，TOTP 前后各允许 skew 个时间步，HOTP 向后查找 skew 个计数，默认 1
+func WithSkew(skew uint) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.skew = skew
+	}
+}
+
+// WithClock 设置时间函数，便于测试
+func WithClock(now func() time.Time) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.now = now
+	}
+}
+
+// WithReplayGuard 设置防重放存储，校验成功的验证码在有效期内不能再次使用
+func WithReplayGuard(guard ReplayGuard) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.guard = guard
+	}
+}
+
+// GenerateSecret 生成随机密钥，返回无填充的 base32 字符串，size 为 0 时使用 20 字节
+func GenerateSecret(size int) (string, error) {
+	if size == 0 {
+		size = DefaultSecretSize
+	}
+	if size < 10 {
+		return "", fmt.Errorf("invalid secret size: %d, must be at least 10 bytes", size)
+	}
+	b := make([]byte, size)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(codec.Base32Encode(b), "="), nil
+}
+
+// decodeSecret 解码 base32 密钥，忽略空格、大小写和缺失的填充
+func decodeSecret(secret string) ([]byte, error) {
+	s := strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	if pad := len(s) % 8; pad != 0 {
+		s += strings.Repeat("=", 8-pad)
+	}
+	key, err := codec.Base32Decode(s)
+	if err != nil || len(key) == 0 {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSecret, err)
+	}
+	return key, nil
+}
+
+// generate 计算 RFC 4226 动态截断后的验证码
+func generate(key []byte, counter uint64, cfg config) (string, error) {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	sum, err := codec.HMAC(cfg.algorithm, key, msg[:])
+	if err != nil {
+		return "", err
+	}
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	mod := uint32(1)
+	for i := 0; i < cfg.digits; i++ {
+		mod *= 10
+	}
+	code := strconv.FormatUint(uint64(value%mod), 10)
+	return strings.Repeat("0", cfg.digits-len(code)) + code, nil
+}
+
+// HOTP 计算指定计数器的验证码
+func HOTP(secret string, counter uint64, opts ...xopt.Option[config]) (string, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return "", err
+	}
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return generate(key, counter, cfg)
+}
+
+// VerifyHOTP 从 counter 开始向后查找 skew 个计数校验验证码
+// 成功时返回下一次应使用的计数器，调用方需要保存
+func VerifyHOTP(secret, code string, counter uint64, opts ...xopt.Option[config]) (uint64, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return counter, err
+	}
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return counter, err
+	}
+	for i := uint64(0); i <= uint64(cfg.skew); i++ {
+		if ok, err := match(key, code, counter+i, cfg); err != nil {
+			return counter, err
+		} else if ok {
+			return counter + i + 1, nil
+		}
+	}
+	return counter, ErrInvalidCode
+}
+
+// TOTP 计算当前时间的验证码
+func TOTP(secret string, opts ...xopt.Option[config]) (string, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return "", err
+	}
+	return TOTPAt(secret, cfg.now(), opts...)
+}
+
+// TOTPAt 计算指定时间的验证码
+func TOTPAt(secret string, t time.Time, opts ...xopt.Option[config]) (string, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return "", err
+	}
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return generate(key, timeStep(t, cfg.period), cfg)
+}
+
+// VerifyTOTP 校验当前时间前后 skew 个时间步内的验证码
+// 配置了 ReplayGuard 时，同一时间步的验证码只能成功校验一次
+func VerifyTOTP(ctx context.Context, secret, code string, opts ...xopt.Option[config]) error {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return err
+	}
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return err
+	}
+	current := timeStep(cfg.now(), cfg.period)
+	for i := -int64(cfg.skew); i <= int64(cfg.skew); i++ {
+		step := uint64(int64(current) + i)
+		ok, err := match(key, code, step, cfg)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if cfg.guard == nil {
+			return nil
+		}
+		// 记录保留到该时间步离开校验窗口
+		ttl := time.Duration(int64(cfg.skew)+i+1) * cfg.period
+		first, err := cfg.guard.Use(ctx, replayKey(key, step), ttl)
+		if err != nil {
+			return fmt.Errorf("otp replay guard: %w", err)
+		}
+		if !first {
+			return ErrCodeReplayed
+		}
+		return nil
+	}
+	return ErrInvalidCode
+}
+
+func match(key []byte, code string, counter uint64, cfg config) (bool, error) {
+	if len(code) != cfg.digits {
+		return false, nil
+	}
+	expected, err := generate(key, counter, cfg)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1, nil
+}
+
+func timeStep(t time.Time, period time.Duration) uint64 {
+	return uint64(t.Unix()) / uint64(period/time.Second)
+}
+
+// KeyURI 生成 TOTP 的 otpauth:// URI，可转换为二维码供认证器 App 扫描
+func KeyURI(secret, issuer, account string, opts ...xopt.Option[config]) (string, error) {
+	return keyURI("totp", secret, issuer, account, nil, opts...)
+}
+
+// HOTPKeyURI 生成 HOTP 的 otpauth:// URI
+func HOTPKeyURI(secret, issuer, account string, counter uint64, opts ...xopt.Option[config]) (string, error) {
+	return keyURI("hotp", secret, issuer, account, &counter, opts...)
+}
+
+func keyURI(typ, secret, issuer, account string, counter *uint64, opts ...xopt.Option[config]) (string, error) {
+	cfg, err := newConfig(opts...)
+	if err != nil {
+		return "", err
+	}
+	if _, err := decodeSecret(secret); err != nil {
+		return "", err
+	}
+	if account == "" {
+		return "", errors.New("account name is required")
+	}
+	label := account
+	if issuer != "" {
+		label = issuer + ":" + account
+	}
+
+	q := url.Values{}
+	q.Set("secret", strings.TrimRight(strings.ToUpper(secret), "="))
+	if issuer != "" {
+		q.Set("issuer", issuer)
+	}
+	q.Set("algorithm", strings.ToUpper(string(cfg.algorithm)))
+	q.Set("digits", strconv.Itoa(cfg.digits))
+	if counter != nil {
+		q.Set("counter", strconv.FormatUint(*counter, 10))
+	} else {
+		q.Set("period", strconv.Itoa(int(cfg.period/time.Second)))
+	}
+	u := url.URL{Scheme: "otpauth", Host: typ, Path: "/" + label, RawQuery: q.Encode()}
+	return u.String(), nil
+}
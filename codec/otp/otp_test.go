@@ -0,0 +1,169 @@
+package otp
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/monaco-io/lib/codec"
+	"github.com/redis/go-redis/v9"
+)
+
+// RFC 6238 附录 B 使用的 ASCII 密钥
+var (
+	secretSHA1   = codec.Base32Encode([]byte("12345678901234567890"))
+	secretSHA256 = codec.Base32Encode([]byte("12345678901234567890123456789012"))
+	secretSHA512 = codec.Base32Encode([]byte("1234567890123456789012345678901234567890123456789012345678901234"))
+)
+
+func TestHOTP_RFC4226(t *testing.T) {
+	expected := []string{"755224", "287082", "359152", "969429", "338314", "254676", "287922", "162583", "399871", "520489"}
+	for counter, want := range expected {
+		got, err := HOTP(secretSHA1, uint64(counter))
+		if err != nil {
+			t.Fatalf("HOTP() error = %v", err)
+		}
+		if got != want {
+			t.Errorf("HOTP(%d) = %s, want %s", counter, got, want)
+		}
+	}
+}
+
+func TestTOTP_RFC6238(t *testing.T) {
+	tests := []struct {
+		unix   int64
+		alg    codec.HashAlgorithm
+		secret string
+		want   string
+	}{
+		{59, codec.HashSHA1, secretSHA1, "94287082"},
+		{59, codec.HashSHA256, secretSHA256, "46119246"},
+		{59, codec.HashSHA512, secretSHA512, "90693936"},
+		{1111111109, codec.HashSHA1, secretSHA1, "07081804"},
+		{1234567890, codec.HashSHA256, secretSHA256, "91819424"},
+		{20000000000, codec.HashSHA512, secretSHA512, "47863826"},
+	}
+	for _, tt := range tests {
+		got, err := TOTPAt(tt.secret, time.Unix(tt.unix, 0), WithDigits(8), WithAlgorithm(tt.alg))
+		if err != nil {
+			t.Fatalf("TOTPAt() error = %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPAt(%d, %s) = %s, want %s", tt.unix, tt.alg, got, tt.want)
+		}
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	secret, err := GenerateSecret(0)
+	if err != nil {
+		t.Fatalf("GenerateSecret() error = %v", err)
+	}
+	if strings.Contains(secret, "=") || len(secret) != 32 {
+		t.Errorf("GenerateSecret() = %q", secret)
+	}
+
+	now := time.Unix(1700000000, 0)
+	clock := WithClock(func() time.Time { return now })
+	ctx := context.Background()
+
+	prev, _ := TOTPAt(secret, now.Add(-30*time.Second))
+	if err := VerifyTOTP(ctx, secret, prev, clock); err != nil {
+		t.Errorf("VerifyTOTP() previous step error = %v", err)
+	}
+	old, _ := TOTPAt(secret, now.Add(-90*time.Second))
+	if err := VerifyTOTP(ctx, secret, old, clock); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("VerifyTOTP() error = %v, want ErrInvalidCode", err)
+	}
+	if err := VerifyTOTP(ctx, secret, old, clock, WithSkew(3)); err != nil {
+		t.Errorf("VerifyTOTP() with skew 3 error = %v", err)
+	}
+	if err := VerifyTOTP(ctx, secret, "12345", clock); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("VerifyTOTP() error = %v, want ErrInvalidCode", err)
+	}
+
+	// 防重放
+	guard := NewMemoryReplayGuard()
+	code, _ := TOTP(secret, clock)
+	if err := VerifyTOTP(ctx, secret, code, clock, WithReplayGuard(guard)); err != nil {
+		t.Errorf("VerifyTOTP() first use error = %v", err)
+	}
+	if err := VerifyTOTP(ctx, secret, code, clock, WithReplayGuard(guard)); !errors.Is(err, ErrCodeReplayed) {
+		t.Errorf("VerifyTOTP() second use error = %v, want ErrCodeReplayed", err)
+	}
+}
+
+func TestVerifyHOTP(t *testing.T) {
+	code, _ := HOTP(secretSHA1, 5)
+	next, err := VerifyHOTP(secretSHA1, code, 4)
+	if err != nil || next != 6 {
+		t.Errorf("VerifyHOTP() = %d, %v, want 6", next, err)
+	}
+	if _, err := VerifyHOTP(secretSHA1, code, 2); !errors.Is(err, ErrInvalidCode) {
+		t.Errorf("VerifyHOTP() error = %v, want ErrInvalidCode", err)
+	}
+	if _, err := VerifyHOTP("not base32!", code, 0); !errors.Is(err, ErrInvalidSecret) {
+		t.Errorf("VerifyHOTP() error = %v, want ErrInvalidSecret", err)
+	}
+}
+
+func TestKeyURI(t *testing.T) {
+	uri, err := KeyURI("JBSWY3DPEHPK3PXP", "Example Co", "alice@example.com", WithDigits(8))
+	if err != nil {
+		t.Fatalf("KeyURI() error = %v", err)
+	}
+	u, _ := url.Parse(uri)
+	if u.Scheme != "otpauth" || u.Host != "totp" || u.Path != "/Example Co:alice@example.com" {
+		t.Errorf("KeyURI() = %q", uri)
+	}
+	q := u.Query()
+	if q.Get("secret") != "JBSWY3DPEHPK3PXP" || q.Get("issuer") != "Example Co" || q.Get("digits") != "8" ||
+		q.Get("algorithm") != "SHA1" || q.Get("period") != "30" {
+		t.Errorf("KeyURI() query = %v", q)
+	}
+
+	hotp, _ := HOTPKeyURI("JBSWY3DPEHPK3PXP", "", "bob", 7)
+	if !strings.HasPrefix(hotp, "otpauth://hotp/bob?") || !strings.Contains(hotp, "counter=7") {
+		t.Errorf("HOTPKeyURI() = %q", hotp)
+	}
+
+	if _, err := KeyURI("JBSWY3DPEHPK3PXP", "x", "a", WithDigits(4)); err == nil {
+		t.Error("KeyURI() expected error for invalid digits")
+	}
+}
+
+// fakeConn 内存实现的 SetNXer
+type fakeConn struct {
+	keys map[string]time.Duration
+}
+
+func (f *fakeConn) SetNX(_ context.Context, key string, _ any, ex time.Duration) *redis.BoolCmd {
+	if _, ok := f.keys[key]; ok {
+		return redis.NewBoolResult(false, nil)
+	}
+	f.keys[key] = ex
+	return redis.NewBoolResult(true, nil)
+}
+
+func TestRedisReplayGuard(t *testing.T) {
+	conn := &fakeConn{keys: map[string]time.Duration{}}
+	guard := &RedisReplayGuard{Conn: conn}
+	now := time.Unix(1700000000, 0)
+	clock := WithClock(func() time.Time { return now })
+
+	code, _ := TOTP(secretSHA1, clock)
+	if err := VerifyTOTP(context.Background(), secretSHA1, code, clock, WithReplayGuard(guard)); err != nil {
+		t.Fatalf("VerifyTOTP() error = %v", err)
+	}
+	if err := VerifyTOTP(context.Background(), secretSHA1, code, clock, WithReplayGuard(guard)); !errors.Is(err, ErrCodeReplayed) {
+		t.Errorf("VerifyTOTP() error = %v, want ErrCodeReplayed", err)
+	}
+	for key, ttl := range conn.keys {
+		if !strings.HasPrefix(key, "otp:used:") || ttl != 60*time.Second {
+			t.Errorf("SetNX(%q, ttl=%s)", key, ttl)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package otp
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ReplayGuard 防重放存储
+// Use 在 key 第一次使用时记录并返回 true，ttl 内再次使用返回 false
+type ReplayGuard interface {
+	Use(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// replayKey 由密钥摘要和时间步组成，不暴露密钥本身
+func replayKey(key []byte, step uint64) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:16]) + ":" + strconv.FormatUint(step, 10)
+}
+
+// MemoryReplayGuard 进程内防重放存储，适用于单实例部署
+type MemoryReplayGuard struct {
+	mu   sync.Mutex
+	used map[string]time.Time
+	now  func() time.Time
+}
+
+// NewMemoryReplayGuard 创建进程内防重放存储
+func NewMemoryReplayGuard() *MemoryReplayGuard {
+	return &MemoryReplayGuard{used: map[string]time.Time{}, now: time.Now}
+}
+
+func (g *MemoryReplayGuard) Use(_ context.Context, key string, ttl time.Duration) (bool, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := g.now()
+	for k, exp := range g.used {
+		if !now.Before(exp) {
+			delete(g.used, k)
+		}
+	}
+	if _, ok := g.used[key]; ok {
+		return false, nil
+	}
+	g.used[key] = now.Add(ttl)
+	return true, nil
+}
+
+// SetNXer RedisReplayGuard 需要的 redis 命令，*redis.Client、redis.UniversalClient 均已实现
+type SetNXer interface {
+	SetNX(ctx context.Context, key string, value any, ex time.Duration) *redis.BoolCmd
+}
+
+var _ SetNXer = (redis.UniversalClient)(nil)
+
+// RedisReplayGuard 基于 SETNX 的防重放存储，适用于多实例部署
+type RedisReplayGuard struct {
+	Conn   SetNXer
+	Prefix string // key 前缀，默认 "otp:used:"
+}
+
+func (g *RedisReplayGuard) Use(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	prefix := g.Prefix
+	if prefix == "" {
+		prefix = "otp:used:"
+	}
+	return g.Conn.SetNX(ctx, prefix+key, 1, ttl).Result()
+}
@@ -0,0 +1,216 @@
+package codec
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/monaco-io/lib/typing/xopt"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+/**
+口令哈希，输出 PHC 格式字符串，盐和参数都保存在结果中：
+
+argon2id：$argon2id$v=19$m=65536,t=3,p=4$<salt>$<hash>（salt/hash 为无填充标准 base64）
+bcrypt：  $2a$12$<salt+hash>
+
+登录校验成功后可以调用 NeedsRehash 判断是否需要按新参数重新哈希。
+**/
+
+// PasswordAlgorithm 口令哈希算法
+type PasswordAlgorithm string
+
+const (
+	PasswordArgon2id PasswordAlgorithm = "argon2id"
+	PasswordBcrypt   PasswordAlgorithm = "bcrypt"
+)
+
+// 校验时允许的 argon2 参数上限，防止恶意哈希消耗过多资源
+const (
+	maxArgon2Memory  = 1 << 20 // 1GiB
+	maxArgon2Time    = 64
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+var (
+	// ErrPasswordMismatch 口令不匹配
+	ErrPasswordMismatch = errors.New("lib.codec:password mismatch")
+	// ErrPasswordHash 不支持或格式错误的口令哈希
+	ErrPasswordHash = errors.New("lib.codec:invalid password hash")
+)
+
+type passwordConfig struct {
+	algorithm     PasswordAlgorithm
+	argon2Memory  uint32
+	argon2Time    uint32
+	argon2Threads uint8
+	bcryptCost    int
+}
+
+func newPasswordConfig(opts ...xopt.Option[passwordConfig]) passwordConfig {
+	cfg := passwordConfig{
+		algorithm:     PasswordArgon2id,
+		argon2Memory:  64 * 1024,
+		argon2Time:    3,
+		argon2Threads: 4,
+		bcryptCost:    12,
+	}
+	xopt.Apply(opts, &cfg)
+	return cfg
+}
+
+// WithPasswordAlgorithm 设置哈希算法，默认 argon2id
+func WithPasswordAlgorithm(alg PasswordAlgorithm) xopt.Option[passwordConfig] {
+	return func(cfg *passwordConfig) {
+		cfg.algorithm = alg
+	}
+}
+
+// WithArgon2Params 设置 argon2id 参数，memory 单位 KiB，默认 64MiB、3 轮、4 线程
+func WithArgon2Params(memory, time uint32, threads uint8) xopt.Option[passwordConfig] {
+	return func(cfg *passwordConfig) {
+		cfg.argon2Memory = memory
+		cfg.argon2Time = time
+		cfg.argon2Threads = threads
+	}
+}
+
+// WithBcryptCost 设置 bcrypt cost，默认 12
+func WithBcryptCost(cost int) xopt.Option[passwordConfig] {
+	return func(cfg *passwordConfig) {
+		cfg.bcryptCost = cost
+	}
+}
+
+// argon2Params argon2id 哈希参数
+type argon2Params struct {
+	memory  uint32
+	time    uint32
+	threads uint8
+	salt    []byte
+	hash    []byte
+}
+
+// HashPassword 计算口令哈希，返回 PHC 格式字符串
+func HashPassword(password string, opts ...xopt.Option[passwordConfig]) (string, error) {
+	cfg := newPasswordConfig(opts...)
+	switch cfg.algorithm {
+	case PasswordArgon2id:
+		if cfg.argon2Memory < 8*uint32(cfg.argon2Threads) || cfg.argon2Memory > maxArgon2Memory ||
+			cfg.argon2Time == 0 || cfg.argon2Time > maxArgon2Time || cfg.argon2Threads == 0 {
+			return "", fmt.Errorf("invalid argon2 params: m=%d,t=%d,p=%d", cfg.argon2Memory, cfg.argon2Time, cfg.argon2Threads)
+		}
+		salt := make([]byte, argon2SaltLength)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return "", err
+		}
+		hash := argon2.IDKey([]byte(password), salt, cfg.argon2Time, cfg.argon2Memory, cfg.argon2Threads, argon2KeyLength)
+		return argon2Params{
+			memory:  cfg.argon2Memory,
+			time:    cfg.argon2Time,
+			threads: cfg.argon2Threads,
+			salt:    salt,
+			hash:    hash,
+		}.String(), nil
+	case PasswordBcrypt:
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), cfg.bcryptCost)
+		if err != nil {
+			return "", err
+		}
+		return string(hash), nil
+	}
+	return "", fmt.Errorf("%w: unsupported algorithm %q", ErrPasswordHash, cfg.algorithm)
+}
+
+// VerifyPassword 校验口令，支持 argon2id 和 bcrypt 格式，不匹配时返回 ErrPasswordMismatch
+func VerifyPassword(password, encoded string) error {
+	switch passwordAlgorithm(encoded) {
+	case PasswordArgon2id:
+		p, err := parseArgon2(encoded)
+		if err != nil {
+			return err
+		}
+		hash := argon2.IDKey([]byte(password), p.salt, p.time, p.memory, p.threads, uint32(len(p.hash)))
+		if subtle.ConstantTimeCompare(hash, p.hash) != 1 {
+			return ErrPasswordMismatch
+		}
+		return nil
+	case PasswordBcrypt:
+		err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrPasswordMismatch
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrPasswordHash, err)
+		}
+		return nil
+	}
+	return ErrPasswordHash
+}
+
+// NeedsRehash 判断哈希是否使用了与当前配置不同的算法或参数
+func NeedsRehash(encoded string, opts ...xopt.Option[passwordConfig]) bool {
+	cfg := newPasswordConfig(opts...)
+	if passwordAlgorithm(encoded) != cfg.algorithm {
+		return true
+	}
+	switch cfg.algorithm {
+	case PasswordArgon2id:
+		p, err := parseArgon2(encoded)
+		return err != nil || p.memory != cfg.argon2Memory || p.time != cfg.argon2Time ||
+			p.threads != cfg.argon2Threads || len(p.hash) != argon2KeyLength
+	case PasswordBcrypt:
+		cost, err := bcrypt.Cost([]byte(encoded))
+		return err != nil || cost != cfg.bcryptCost
+	}
+	return true
+}
+
+func passwordAlgorithm(encoded string) PasswordAlgorithm {
+	switch {
+	case strings.HasPrefix(encoded, "$argon2id$"):
+		return PasswordArgon2id
+	case strings.HasPrefix(encoded, "$2a$"), strings.HasPrefix(encoded, "$2b$"), strings.HasPrefix(encoded, "$2y$"):
+		return PasswordBcrypt
+	}
+	return ""
+}
+
+func (p argon2Params) String() string {
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, p.memory, p.time, p.threads,
+		base64.RawStdEncoding.EncodeToString(p.salt), base64.RawStdEncoding.EncodeToString(p.hash))
+}
+
+// parseArgon2 解析 $argon2id$v=19$m=65536,t=3,p=4$salt$hash
+func parseArgon2(encoded string) (*argon2Params, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != string(PasswordArgon2id) {
+		return nil, ErrPasswordHash
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, fmt.Errorf("%w: unsupported argon2 version %q", ErrPasswordHash, parts[2])
+	}
+	var p argon2Params
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.time, &p.threads); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrPasswordHash, err)
+	}
+	if p.memory == 0 || p.memory > maxArgon2Memory || p.time == 0 || p.time > maxArgon2Time || p.threads == 0 {
+		return nil, fmt.Errorf("%w: argon2 params out of range", ErrPasswordHash)
+	}
+	var err error
+	if p.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil || len(p.salt) < 8 {
+		return nil, fmt.Errorf("%w: invalid salt", ErrPasswordHash)
+	}
+	if p.hash, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(p.hash) < 16 {
+		return nil, fmt.Errorf("%w: invalid hash", ErrPasswordHash)
+	}
+	return &p, nil
+}
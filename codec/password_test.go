@@ -0,0 +1,100 @@
+package codec
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+// 测试使用较低的参数以加快速度
+var (
+	fastArgon2 = WithArgon2Params(1024, 1, 1)
+	fastBcrypt = WithBcryptCost(4)
+)
+
+func TestHashPassword(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		opts   []xopt.Option[passwordConfig]
+	}{
+		{"argon2id", "$argon2id$v=19$m=1024,t=1,p=1$", []xopt.Option[passwordConfig]{fastArgon2}},
+		{"bcrypt", "$2a$04$", []xopt.Option[passwordConfig]{WithPasswordAlgorithm(PasswordBcrypt), fastBcrypt}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := HashPassword("hunter2", tt.opts...)
+			if err != nil {
+				t.Fatalf("HashPassword() error = %v", err)
+			}
+			if !strings.HasPrefix(encoded, tt.prefix) {
+				t.Errorf("HashPassword() = %q, want prefix %q", encoded, tt.prefix)
+			}
+			other, _ := HashPassword("hunter2", tt.opts...)
+			if other == encoded {
+				t.Error("HashPassword() should use a random salt")
+			}
+
+			if err := VerifyPassword("hunter2", encoded); err != nil {
+				t.Errorf("VerifyPassword() error = %v", err)
+			}
+			if err := VerifyPassword("hunter3", encoded); !errors.Is(err, ErrPasswordMismatch) {
+				t.Errorf("VerifyPassword() error = %v, want ErrPasswordMismatch", err)
+			}
+			if NeedsRehash(encoded, tt.opts...) {
+				t.Error("NeedsRehash() = true for current params")
+			}
+		})
+	}
+}
+
+func TestVerifyPassword_Known(t *testing.T) {
+	// 由参考实现生成
+	encoded := "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc"
+	if err := VerifyPassword("password", encoded); err != nil {
+		t.Errorf("VerifyPassword() error = %v", err)
+	}
+
+	invalid := []string{
+		"",
+		"5e884898da28047151d0e56f8dc6292773603d0d6aabbdd62a11ef721d1542d8",
+		"$argon2i$v=19$m=16,t=2,p=1$c29tZXNhbHQ$ZFXwSh1NhMUJYNoK9MOuUw",
+		"$argon2id$v=16$m=16,t=2,p=1$c29tZXNhbHQ$ZFXwSh1NhMUJYNoK9MOuUw",
+		"$argon2id$v=19$m=99999999,t=2,p=1$c29tZXNhbHQ$ZFXwSh1NhMUJYNoK9MOuUw",
+		"$argon2id$v=19$m=16,t=2,p=1$!!$ZFXwSh1NhMUJYNoK9MOuUw",
+		"$2a$04$short",
+	}
+	for _, encoded := range invalid {
+		if err := VerifyPassword("password", encoded); !errors.Is(err, ErrPasswordHash) {
+			t.Errorf("VerifyPassword(%q) error = %v, want ErrPasswordHash", encoded, err)
+		}
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	bcryptHash, _ := HashPassword("pw", WithPasswordAlgorithm(PasswordBcrypt), fastBcrypt)
+	argonHash, _ := HashPassword("pw", fastArgon2)
+
+	if !NeedsRehash(bcryptHash, fastArgon2) {
+		t.Error("NeedsRehash() should upgrade bcrypt to argon2id")
+	}
+	if !NeedsRehash(bcryptHash, WithPasswordAlgorithm(PasswordBcrypt), WithBcryptCost(5)) {
+		t.Error("NeedsRehash() should upgrade bcrypt cost")
+	}
+	if !NeedsRehash(argonHash, WithArgon2Params(2048, 1, 1)) {
+		t.Error("NeedsRehash() should upgrade argon2 memory")
+	}
+	if !NeedsRehash("garbage") {
+		t.Error("NeedsRehash() should be true for unknown format")
+	}
+
+	if _, err := HashPassword("pw", WithArgon2Params(0, 1, 1)); err == nil {
+		t.Error("HashPassword() expected error for invalid argon2 params")
+	}
+	if _, err := HashPassword("pw", WithPasswordAlgorithm("md5")); !errors.Is(err, ErrPasswordHash) {
+		t.Errorf("HashPassword() error = %v, want ErrPasswordHash", err)
+	}
+}
@@ -0,0 +1,81 @@
+package codec
+
+import (
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+/**
+公钥封装加密，接收方只需公开 X25519 公钥：
+
+| 临时公钥 (32) | AES-256-GCM 密文 (nonce || ciphertext || tag) |
+
+AES 密钥 = HKDF-SHA256(ECDH(临时私钥, 接收方公钥), salt = 临时公钥 || 接收方公钥)
+临时公钥和接收方公钥同时作为 GCM 附加认证数据。
+**/
+
+const sealInfo = "lib.codec seal v1"
+
+// ErrSealedData 密文格式错误
+var ErrSealedData = errors.New("lib.codec:invalid sealed data")
+
+// SealToPublicKey 使用接收方 X25519 公钥加密，每次调用生成新的临时密钥
+func SealToPublicKey(pub *ecdh.PublicKey, plaintext []byte) ([]byte, error) {
+	if pub == nil || pub.Curve() != ecdh.X25519() {
+		return nil, fmt.Errorf("%w: seal requires x25519 public key", ErrUnsupportedKey)
+	}
+	ephemeral, err := GenerateX25519Key()
+	if err != nil {
+		return nil, err
+	}
+	ephPub := ephemeral.PublicKey().Bytes()
+	c, aad, err := sealCipher(ephemeral, pub, ephPub, pub.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := c.EncryptWithAAD(plaintext, aad)
+	if err != nil {
+		return nil, err
+	}
+	return append(ephPub, ciphertext...), nil
+}
+
+// OpenWithPrivateKey 使用接收方 X25519 私钥解密 SealToPublicKey 的结果
+func OpenWithPrivateKey(priv *ecdh.PrivateKey, data []byte) ([]byte, error) {
+	if priv == nil || priv.Curve() != ecdh.X25519() {
+		return nil, fmt.Errorf("%w: open requires x25519 private key", ErrUnsupportedKey)
+	}
+	if len(data) < 32 {
+		return nil, ErrSealedData
+	}
+	ephPub, err := ecdh.X25519().NewPublicKey(data[:32])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSealedData, err)
+	}
+	c, aad, err := sealCipher(priv, ephPub, data[:32], priv.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return c.DecryptWithAAD(data[32:], aad)
+}
+
+// sealCipher 派生 AES 密钥，返回加密器和附加认证数据
+func sealCipher(priv *ecdh.PrivateKey, peer *ecdh.PublicKey, ephPub, recipient []byte) (*aesCipher, []byte, error) {
+	shared, err := priv.ECDH(peer)
+	if err != nil {
+		return nil, nil, err
+	}
+	salt := append(append(make([]byte, 0, 64), ephPub...), recipient...)
+	key, err := hkdf.Key(sha256.New, shared, salt, sealInfo, 32)
+	if err != nil {
+		return nil, nil, err
+	}
+	c, err := NewAESCipher(string(key), GCM)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, salt, nil
+}
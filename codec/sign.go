@@ -0,0 +1,257 @@
+package codec
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+/**
+请求签名，待签名字符串由以下部分按行拼接：
+
+METHOD
+/escaped/path
+排序后的 query
+小写请求头:值（按名称排序，每个一行）
+参与签名的请求头列表 a;b
+请求体 SHA256
+时间戳（Unix 秒）
+**/
+
+const (
+	// HeaderSignature 默认签名请求头
+	HeaderSignature = "X-Signature"
+	// HeaderSignatureTimestamp 默认时间戳请求头
+	HeaderSignatureTimestamp = "X-Signature-Timestamp"
+	// DefaultSignatureWindow 默认允许的时间偏差
+	DefaultSignatureWindow = 5 * time.Minute
+)
+
+var (
+	// ErrSignatureMissing 请求中没有签名或时间戳
+	ErrSignatureMissing = errors.New("lib.codec:signature missing")
+	// ErrSignatureMismatch 签名不匹配
+	ErrSignatureMismatch = errors.New("lib.codec:signature mismatch")
+	// ErrSignatureExpired 时间戳超出允许的时间窗口，可能是重放请求
+	ErrSignatureExpired = errors.New("lib.codec:signature expired")
+)
+
+type signConfig struct {
+	algorithm       HashAlgorithm
+	headers         []string
+	window          time.Duration
+	now             func() time.Time
+	signatureHeader string
+	timestampHeader string
+}
+
+// WithSignAlgorithm 设置 HMAC 算法，默认 sha256
+func WithSignAlgorithm(alg HashAlgorithm) xopt.Option[signConfig] {
+	return func(cfg *signConfig) {
+		cfg.algorithm = alg
+	}
+}
+
+// WithSignedHeaders 设置参与签名的请求头，host 取自 req.Host
+func WithSignedHeaders(headers ...string) xopt.Option[signConfig] {
+	return func(cfg *signConfig) {
+		cfg.headers = headers
+	}
+}
+
+// WithReplayWindow 设置校验时允许的时间偏差，默认 5 分钟
+func WithReplayWindow(d time.Duration) xopt.Option[signConfig] {
+	return func(cfg *signConfig) {
+		cfg.window = d
+	}
+}
+
+// WithSignClock 设置时间函数，便于测试
+func WithSignClock(now func() time.Time) xopt.Option[signConfig] {
+	return func(cfg *signConfig) {
+		cfg.now = now
+	}
+}
+
+// WithSignatureHeaders 设置签名和时间戳所在的请求头
+func WithSignatureHeaders(signature, timestamp string) xopt.Option[signConfig] {
+	return func(cfg *signConfig) {
+		cfg.signatureHeader = signature
+		cfg.timestampHeader = timestamp
+	}
+}
+
+// RequestSigner HTTP 请求签名器，签名方和校验方需要使用相同的配置
+type RequestSigner struct {
+	key []byte
+	cfg signConfig
+}
+
+// NewRequestSigner 创建请求签名器
+func NewRequestSigner(key []byte, opts ...xopt.Option[signConfig]) (*RequestSigner, error) {
+	cfg := signConfig{
+		algorithm:       HashSHA256,
+		window:          DefaultSignatureWindow,
+		now:             time.Now,
+		signatureHeader: HeaderSignature,
+		timestampHeader: HeaderSignatureTimestamp,
+	}
+	xopt.Apply(opts, &cfg)
+	if len(key) == 0 {
+		return nil, errors.New("signing key is empty")
+	}
+	if _, err := newHash(cfg.algorithm); err != nil {
+		return nil, err
+	}
+	if !isCryptoHash(cfg.algorithm) {
+		return nil, fmt.Errorf("%w: %s is not a cryptographic hash", ErrUnknownHash, cfg.algorithm)
+	}
+	headers := make([]string, 0, len(cfg.headers))
+	for _, h := range cfg.headers {
+		headers = append(headers, strings.ToLower(strings.TrimSpace(h)))
+	}
+	slices.Sort(headers)
+	cfg.headers = slices.Compact(headers)
+	return &RequestSigner{key: key, cfg: cfg}, nil
+}
+
+// Sign 计算签名并写入请求头
+func (s *RequestSigner) Sign(req *http.Request) error {
+	timestamp := strconv.FormatInt(s.cfg.now().Unix(), 10)
+	canonical, err := CanonicalRequest(req, s.cfg.headers, timestamp)
+	if err != nil {
+		return err
+	}
+	signature, err := hmacHex(s.cfg.algorithm, s.key, []byte(canonical))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(s.cfg.timestampHeader, timestamp)
+	req.Header.Set(s.cfg.signatureHeader, signature)
+	return nil
+}
+
+// Verify 校验请求签名和时间窗口
+func (s *RequestSigner) Verify(req *http.Request) error {
+	signature := req.Header.Get(s.cfg.signatureHeader)
+	timestamp := req.Header.Get(s.cfg.timestampHeader)
+	if signature == "" || timestamp == "" {
+		return ErrSignatureMissing
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp %q", ErrSignatureMismatch, timestamp)
+	}
+	if skew := s.cfg.now().Sub(time.Unix(ts, 0)); skew > s.cfg.window || skew < -s.cfg.window {
+		return fmt.Errorf("%w: timestamp skew %s", ErrSignatureExpired, skew)
+	}
+	canonical, err := CanonicalRequest(req, s.cfg.headers, timestamp)
+	if err != nil {
+		return err
+	}
+	if !verifyHMACHex(s.cfg.algorithm, s.key, []byte(canonical), signature) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// CanonicalRequest 生成待签名字符串，读取请求体后会恢复 req.Body
+func CanonicalRequest(req *http.Request, headers []string, timestamp string) (string, error) {
+	bodyHash, err := hashBody(req)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.ToUpper(req.Method))
+	b.WriteByte('\n')
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	b.WriteString(path)
+	b.WriteByte('\n')
+	b.WriteString(canonicalQuery(req.URL.Query()))
+	b.WriteByte('\n')
+
+	names := make([]string, 0, len(headers))
+	for _, h := range headers {
+		names = append(names, strings.ToLower(h))
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteByte(':')
+		b.WriteString(canonicalHeader(req, name))
+		b.WriteByte('\n')
+	}
+	b.WriteString(strings.Join(names, ";"))
+	b.WriteByte('\n')
+	b.WriteString(bodyHash)
+	b.WriteByte('\n')
+	b.WriteString(timestamp)
+	return b.String(), nil
+}
+
+// canonicalQuery 按 key 和 value 排序编码 query
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	var parts []string
+	for _, k := range keys {
+		values := slices.Clone(query[k])
+		slices.Sort(values)
+		for _, v := range values {
+			parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func canonicalHeader(req *http.Request, name string) string {
+	if name == "host" {
+		if req.Host != "" {
+			return req.Host
+		}
+		return req.URL.Host
+	}
+	values := req.Header.Values(name)
+	trimmed := make([]string, 0, len(values))
+	for _, v := range values {
+		trimmed = append(trimmed, strings.Join(strings.Fields(v), " "))
+	}
+	return strings.Join(trimmed, ",")
+}
+
+// hashBody 计算请求体 SHA256 并恢复请求体
+func hashBody(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		sum := sha256.Sum256(nil)
+		return hex.EncodeToString(sum[:]), nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to read request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,117 @@
+package codec
+
+import (
+	"crypto/sha256"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRequestSigner(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	clock := func() time.Time { return now }
+	signer, err := NewRequestSigner([]byte("secret"), WithSignedHeaders("Host", "Content-Type"), WithSignClock(clock))
+	if err != nil {
+		t.Fatalf("NewRequestSigner() error = %v", err)
+	}
+
+	newRequest := func() *http.Request {
+		req, _ := http.NewRequest(http.MethodPost, "https://api.example.com/v1/orders?b=2&a=1&a=0", strings.NewReader(`{"id":1}`))
+		req.Header.Set("Content-Type", "application/json")
+		return req
+	}
+
+	req := newRequest()
+	if err := signer.Sign(req); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	// 签名后请求体仍可读取
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != `{"id":1}` {
+		t.Errorf("body after Sign() = %q", body)
+	}
+	req.Body, _ = req.GetBody()
+
+	if err := signer.Verify(req); err != nil {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	// query 顺序不影响签名
+	reordered := newRequest()
+	reordered.URL.RawQuery = "a=0&b=2&a=1"
+	reordered.Header.Set(HeaderSignature, req.Header.Get(HeaderSignature))
+	reordered.Header.Set(HeaderSignatureTimestamp, req.Header.Get(HeaderSignatureTimestamp))
+	if err := signer.Verify(reordered); err != nil {
+		t.Errorf("Verify() reordered query error = %v", err)
+	}
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := newRequest()
+		tampered.Body = io.NopCloser(strings.NewReader(`{"id":2}`))
+		tampered.Header.Set(HeaderSignature, req.Header.Get(HeaderSignature))
+		tampered.Header.Set(HeaderSignatureTimestamp, req.Header.Get(HeaderSignatureTimestamp))
+		if err := signer.Verify(tampered); !errors.Is(err, ErrSignatureMismatch) {
+			t.Errorf("Verify() error = %v, want ErrSignatureMismatch", err)
+		}
+
+		tampered = newRequest()
+		tampered.Header.Set("Content-Type", "text/plain")
+		tampered.Header.Set(HeaderSignature, req.Header.Get(HeaderSignature))
+		tampered.Header.Set(HeaderSignatureTimestamp, req.Header.Get(HeaderSignatureTimestamp))
+		if err := signer.Verify(tampered); !errors.Is(err, ErrSignatureMismatch) {
+			t.Errorf("Verify() error = %v, want ErrSignatureMismatch", err)
+		}
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		late, _ := NewRequestSigner([]byte("secret"), WithSignedHeaders("Host", "Content-Type"),
+			WithSignClock(func() time.Time { return now.Add(10 * time.Minute) }))
+		req.Body, _ = req.GetBody()
+		if err := late.Verify(req); !errors.Is(err, ErrSignatureExpired) {
+			t.Errorf("Verify() error = %v, want ErrSignatureExpired", err)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		if err := signer.Verify(newRequest()); !errors.Is(err, ErrSignatureMissing) {
+			t.Errorf("Verify() error = %v, want ErrSignatureMissing", err)
+		}
+	})
+}
+
+func TestRequestSignerAlgorithm(t *testing.T) {
+	// 非加密哈希不能用于 HMAC，创建时即拒绝
+	for _, alg := range []HashAlgorithm{HashCRC32, HashFNV64a, HashXXHash64, "unknown"} {
+		if _, err := NewRequestSigner([]byte("secret"), WithSignAlgorithm(alg)); !errors.Is(err, ErrUnknownHash) {
+			t.Errorf("NewRequestSigner(%s) error = %v, want ErrUnknownHash", alg, err)
+		}
+	}
+	// 注册后变为非加密哈希时 Sign 返回错误，而不是写入空签名
+	RegisterHash("sign-test-weak", sha256.New, true)
+	signer, err := NewRequestSigner([]byte("secret"), WithSignAlgorithm("sign-test-weak"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	RegisterHash("sign-test-weak", sha256.New, false)
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com/", nil)
+	if err := signer.Sign(req); !errors.Is(err, ErrUnknownHash) || req.Header.Get(HeaderSignature) != "" {
+		t.Errorf("Sign() error = %v, signature = %q", err, req.Header.Get(HeaderSignature))
+	}
+}
+
+func TestCanonicalRequest(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.com/a%20b?z=1&y=a+b", nil)
+	req.Header.Set("X-Trace", "  a   b ")
+	got, err := CanonicalRequest(req, []string{"X-Trace", "host"}, "1700000000")
+	if err != nil {
+		t.Fatalf("CanonicalRequest() error = %v", err)
+	}
+	want := "GET\n/a%20b\ny=a+b&z=1\nhost:example.com\nx-trace:a b\nhost;x-trace\n" +
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855\n1700000000"
+	if got != want {
+		t.Errorf("CanonicalRequest() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,380 @@
+package xjson
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/monaco-io/lib/codec"
+	"github.com/monaco-io/lib/typing/xstr"
+)
+
+/**
+字段级加密和脱敏，通过 secure 标签声明：
+
+	type User struct {
+		Name   string `json:"name"`
+		IDCard string `json:"id_card" secure:"encrypt"`  // MarshalSecure 加密，MarshalMasked 全部打码
+		Phone  string `json:"phone" secure:"mask,3,4"`    // MarshalMasked 保留前 3 位和后 4 位
+		Email  string `json:"email" secure:"mask,1,0,#"`  // 使用 # 打码
+	}
+
+支持 string、*string 和 []byte 字段，string 密文以标准 base64 保存，[]byte 密文由 json 编码为 base64。
+嵌套结构体、未导出的嵌入结构体、指针、切片、数组、map 中的字段同样生效，原始对象不会被修改。
+**/
+
+const secureTag = "secure"
+
+// ErrSecureTag secure 标签格式错误或字段类型不支持
+var ErrSecureTag = errors.New("lib.xjson:invalid secure tag")
+
+// maxSecureDepth 嵌套深度上限，防止循环引用
+const maxSecureDepth = 64
+
+type secureRule struct {
+	encrypt bool
+	mask    bool
+	start   int
+	end     int
+	char    string
+}
+
+func parseSecureTag(tag string) (*secureRule, error) {
+	parts := strings.Split(tag, ",")
+	switch parts[0] {
+	case "encrypt":
+		if len(parts) != 1 {
+			return nil, fmt.Errorf("%w: %q", ErrSecureTag, tag)
+		}
+		return &secureRule{encrypt: true}, nil
+	case "mask":
+		rule := &secureRule{mask: true, start: 3, end: 4, char: "*"}
+		if len(parts) > 4 || len(parts) == 2 {
+			return nil, fmt.Errorf("%w: %q", ErrSecureTag, tag)
+		}
+		if len(parts) >= 3 {
+			var err1, err2 error
+			rule.start, err1 = strconv.Atoi(parts[1])
+			rule.end, err2 = strconv.Atoi(parts[2])
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("%w: %q", ErrSecureTag, tag)
+			}
+		}
+		if len(parts) == 4 {
+			rule.char = parts[3]
+		}
+		return rule, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrSecureTag, tag)
+}
+
+// fieldFunc 处理带 secure 标签的字符串值
+type fieldFunc func(rule *secureRule, field string, value []byte) ([]byte, error)
+
+// MarshalSecure 加密 secure:"encrypt" 字段后序列化
+func MarshalSecure(v any, c codec.CodecAES) ([]byte, error) {
+	copied, err := secureCopy(reflect.ValueOf(v), encryptField(c), cipherToBase64)
+	if err != nil {
+		return nil, err
+	}
+	return marshal(copied.Interface())
+}
+
+// UnmarshalSecure 反序列化后解密 secure:"encrypt" 字段，v 必须是非空指针
+func UnmarshalSecure(data []byte, v any, c codec.CodecAES) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("xjson: UnmarshalSecure requires a non-nil pointer")
+	}
+	if err := unmarshal(data, v); err != nil {
+		return err
+	}
+	decrypted, err := secureCopy(rv.Elem(), decryptField(c), cipherFromBase64)
+	if err != nil {
+		return err
+	}
+	rv.Elem().Set(decrypted)
+	return nil
+}
+
+// MarshalMasked 脱敏后序列化，用于日志输出
+// secure:"mask" 字段按规则打码，secure:"encrypt" 字段全部打码
+func MarshalMasked(v any) ([]byte, error) {
+	copied, err := secureCopy(reflect.ValueOf(v), maskField, 0)
+	if err != nil {
+		return nil, err
+	}
+	return marshal(copied.Interface())
+}
+
+// MarshalMaskedStringX 脱敏后序列化为字符串，出错时返回空字符串
+func MarshalMaskedStringX(v any) string {
+	b, _ := MarshalMasked(v)
+	return string(b)
+}
+
+func encryptField(c codec.CodecAES) fieldFunc {
+	return func(rule *secureRule, field string, value []byte) ([]byte, error) {
+		if !rule.encrypt {
+			return value, nil
+		}
+		ciphertext, err := c.Encrypt(value)
+		if err != nil {
+			return nil, fmt.Errorf("xjson: encrypt field %s: %w", field, err)
+		}
+		return ciphertext, nil
+	}
+}
+
+func decryptField(c codec.CodecAES) fieldFunc {
+	return func(rule *secureRule, field string, value []byte) ([]byte, error) {
+		if !rule.encrypt || len(value) == 0 {
+			return value, nil
+		}
+		plaintext, err := c.Decrypt(value)
+		if err != nil {
+			return nil, fmt.Errorf("xjson: decrypt field %s: %w", field, err)
+		}
+		return plaintext, nil
+	}
+}
+
+func maskField(rule *secureRule, _ string, value []byte) ([]byte, error) {
+	if rule.encrypt {
+		return []byte(xstr.Mask(string(value), 0, 0, "*")), nil
+	}
+	return []byte(xstr.Mask(string(value), rule.start, rule.end, rule.char)), nil
+}
+
+// cipherMode 加密字段为 string 时密文的 base64 处理方式
+type cipherMode int
+
+const (
+	cipherToBase64   cipherMode = iota + 1 // fn 输出密文，编码为 base64
+	cipherFromBase64                       // 先 base64 解码得到密文再调用 fn
+)
+
+// secureCopy 复制 v 并对带标签的字段调用 fn，原始值不会被修改
+func secureCopy(v reflect.Value, fn fieldFunc, mode cipherMode) (reflect.Value, error) {
+	w := secureWalker{fn: fn, mode: mode}
+	return w.copy(v, 0)
+}
+
+type secureWalker struct {
+	fn   fieldFunc
+	mode cipherMode
+}
+
+func (w *secureWalker) copy(v reflect.Value, depth int) (reflect.Value, error) {
+	if !v.IsValid() {
+		return v, nil
+	}
+	if depth > maxSecureDepth {
+		return v, errors.New("xjson: secure value is too deeply nested")
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v, nil
+		}
+		elem, err := w.copy(v.Elem(), depth+1)
+		if err != nil {
+			return v, err
+		}
+		p := reflect.New(v.Type().Elem())
+		p.Elem().Set(elem)
+		return p, nil
+	case reflect.Interface:
+		if v.IsNil() {
+			return v, nil
+		}
+		elem, err := w.copy(v.Elem(), depth+1)
+		if err != nil {
+			return v, err
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(elem)
+		return out, nil
+	case reflect.Struct:
+		return w.copyStruct(v, depth)
+	case reflect.Slice:
+		if v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+			return v, nil
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			elem, err := w.copy(v.Index(i), depth+1)
+			if err != nil {
+				return v, err
+			}
+			out.Index(i).Set(elem)
+		}
+		return out, nil
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			elem, err := w.copy(v.Index(i), depth+1)
+			if err != nil {
+				return v, err
+			}
+			out.Index(i).Set(elem)
+		}
+		return out, nil
+	case reflect.Map:
+		if v.IsNil() {
+			return v, nil
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			elem, err := w.copy(iter.Value(), depth+1)
+			if err != nil {
+				return v, err
+			}
+			out.SetMapIndex(iter.Key(), elem)
+		}
+		return out, nil
+	}
+	return v, nil
+}
+
+func (w *secureWalker) copyStruct(v reflect.Value, depth int) (reflect.Value, error) {
+	out := reflect.New(v.Type()).Elem()
+	out.Set(v)
+	if err := w.structFields(out, depth); err != nil {
+		return v, err
+	}
+	return out, nil
+}
+
+// structFields 原地处理已复制的结构体字段
+// 未导出的嵌入结构体会被 encoding/json 展开，其导出字段通过反射仍可设置
+func (w *secureWalker) structFields(out reflect.Value, depth int) error {
+	t := out.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		field := out.Field(i)
+		if !sf.IsExported() {
+			if !sf.Anonymous {
+				continue
+			}
+			switch {
+			case sf.Type.Kind() == reflect.Struct:
+				if err := w.structFields(field, depth+1); err != nil {
+					return err
+				}
+			case sf.Type.Kind() == reflect.Pointer && !field.IsNil() && hasSecureField(sf.Type, nil):
+				// 未导出的嵌入指针无法替换为副本，修改会影响原始对象
+				return fmt.Errorf("%w: embedded unexported pointer %s", ErrSecureTag, sf.Type)
+			}
+			continue
+		}
+		tag, ok := sf.Tag.Lookup(secureTag)
+		if !ok {
+			copied, err := w.copy(field, depth+1)
+			if err != nil {
+				return err
+			}
+			field.Set(copied)
+			continue
+		}
+		rule, err := parseSecureTag(tag)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+		if err := w.apply(rule, sf.Name, field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasSecureField 类型中是否包含 secure 标签字段
+func hasSecureField(t reflect.Type, seen map[reflect.Type]bool) bool {
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array, reflect.Map:
+		return hasSecureField(t.Elem(), seen)
+	case reflect.Struct:
+	default:
+		return false
+	}
+	if seen[t] {
+		return false
+	}
+	if seen == nil {
+		seen = map[reflect.Type]bool{}
+	}
+	seen[t] = true
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if _, ok := sf.Tag.Lookup(secureTag); ok && sf.IsExported() {
+			return true
+		}
+		if (sf.IsExported() || sf.Anonymous) && hasSecureField(sf.Type, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply 处理 string、*string 和 []byte 字段
+func (w *secureWalker) apply(rule *secureRule, name string, field reflect.Value) error {
+	switch {
+	case field.Kind() == reflect.String:
+		s, err := w.applyString(rule, name, field.String())
+		if err != nil {
+			return err
+		}
+		field.SetString(s)
+	case field.Kind() == reflect.Pointer && field.Type().Elem().Kind() == reflect.String:
+		if field.IsNil() {
+			return nil
+		}
+		s, err := w.applyString(rule, name, field.Elem().String())
+		if err != nil {
+			return err
+		}
+		p := reflect.New(field.Type().Elem())
+		p.Elem().SetString(s)
+		field.Set(p)
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.Uint8:
+		if field.IsNil() {
+			return nil
+		}
+		b, err := w.fn(rule, name, field.Bytes())
+		if err != nil {
+			return err
+		}
+		field.SetBytes(b)
+	default:
+		return fmt.Errorf("field %s: %w: unsupported type %s", name, ErrSecureTag, field.Type())
+	}
+	return nil
+}
+
+func (w *secureWalker) applyString(rule *secureRule, name, s string) (string, error) {
+	if s == "" {
+		return s, nil
+	}
+	value := []byte(s)
+	// 只有加密字段的字符串使用 base64 保存密文
+	if rule.encrypt && w.mode == cipherFromBase64 {
+		decoded, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return "", fmt.Errorf("xjson: decode field %s: %w", name, err)
+		}
+		value = decoded
+	}
+	out, err := w.fn(rule, name, value)
+	if err != nil {
+		return "", err
+	}
+	if rule.encrypt && w.mode == cipherToBase64 {
+		return base64.StdEncoding.EncodeToString(out), nil
+	}
+	return string(out), nil
+}
@@ -0,0 +1,163 @@
+package xjson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/monaco-io/lib/codec"
+)
+
+type secureAddress struct {
+	City   string `json:"city"`
+	Street string `json:"street" secure:"encrypt"`
+}
+
+type secureUser struct {
+	Name     string                   `json:"name"`
+	IDCard   string                   `json:"id_card" secure:"encrypt"`
+	Phone    string                   `json:"phone" secure:"mask,3,4"`
+	Email    *string                  `json:"email" secure:"mask,1,0,#"`
+	Token    []byte                   `json:"token" secure:"encrypt"`
+	Nickname string                   `json:"nickname" secure:"mask"`
+	Address  *secureAddress           `json:"address"`
+	History  []secureAddress          `json:"history"`
+	Extra    map[string]secureAddress `json:"extra"`
+}
+
+func newSecureUser() secureUser {
+	email := "alice@example.com"
+	return secureUser{
+		Name:     "alice",
+		IDCard:   "110101199003071234",
+		Phone:    "13812345678",
+		Email:    &email,
+		Token:    []byte("raw-token"),
+		Nickname: "wonderland",
+		Address:  &secureAddress{City: "Beijing", Street: "Chang'an Ave 1"},
+		History:  []secureAddress{{City: "Shanghai", Street: "Nanjing Rd 2"}},
+		Extra:    map[string]secureAddress{"work": {City: "Hangzhou", Street: "Wensan Rd 3"}},
+	}
+}
+
+func TestMarshalSecure(t *testing.T) {
+	c, err := codec.NewAESCipher("mysecretkey12345", codec.GCM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := newSecureUser()
+
+	data, err := MarshalSecure(user, c)
+	if err != nil {
+		t.Fatalf("MarshalSecure() error = %v", err)
+	}
+	for _, plain := range []string{"110101199003071234", "Chang'an Ave 1", "Nanjing Rd 2", "Wensan Rd 3", "raw-token"} {
+		if strings.Contains(string(data), plain) {
+			t.Errorf("MarshalSecure() leaks %q: %s", plain, data)
+		}
+	}
+	// 未标记的字段保持可读
+	for _, plain := range []string{"alice", "13812345678", "Beijing"} {
+		if !strings.Contains(string(data), plain) {
+			t.Errorf("MarshalSecure() should keep %q: %s", plain, data)
+		}
+	}
+	// 原始对象不被修改
+	if user.IDCard != "110101199003071234" || user.Address.Street != "Chang'an Ave 1" || string(user.Token) != "raw-token" {
+		t.Errorf("MarshalSecure() modified input: %+v", user)
+	}
+
+	var got secureUser
+	if err := UnmarshalSecure(data, &got, c); err != nil {
+		t.Fatalf("UnmarshalSecure() error = %v", err)
+	}
+	if got.IDCard != user.IDCard || got.Address.Street != user.Address.Street || string(got.Token) != "raw-token" ||
+		got.History[0].Street != "Nanjing Rd 2" || got.Extra["work"].Street != "Wensan Rd 3" {
+		t.Errorf("UnmarshalSecure() = %+v", got)
+	}
+
+	other, _ := codec.NewAESCipher("anothersecretkey", codec.GCM)
+	if err := UnmarshalSecure(data, &got, other); err == nil {
+		t.Error("UnmarshalSecure() expected error for wrong key")
+	}
+	if err := UnmarshalSecure(data, got, c); err == nil {
+		t.Error("UnmarshalSecure() expected error for non-pointer")
+	}
+}
+
+func TestMarshalMasked(t *testing.T) {
+	user := newSecureUser()
+	got := MarshalMaskedStringX(user)
+
+	for _, want := range []string{
+		`"phone":"138****5678"`,
+		`"email":"a################"`,
+		`"nickname":"won***land"`,
+		`"id_card":"******************"`,
+		`"street":"**************"`,
+		`"name":"alice"`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("MarshalMasked() = %s, want %s", got, want)
+		}
+	}
+	if *user.Email != "alice@example.com" {
+		t.Errorf("MarshalMasked() modified input: %q", *user.Email)
+	}
+}
+
+type secureCredential struct {
+	Password string `json:"password" secure:"encrypt"`
+	Hint     string `json:"hint" secure:"mask,1,0"`
+}
+
+func TestMarshalSecureEmbedded(t *testing.T) {
+	c, _ := codec.NewAESCipher("mysecretkey12345", codec.GCM)
+	// encoding/json 会展开未导出的嵌入结构体
+	type account struct {
+		Name string `json:"name"`
+		secureCredential
+	}
+	in := account{Name: "alice", secureCredential: secureCredential{Password: "p@ssw0rd", Hint: "pet name"}}
+
+	data, err := MarshalSecure(in, c)
+	if err != nil {
+		t.Fatalf("MarshalSecure() error = %v", err)
+	}
+	if strings.Contains(string(data), "p@ssw0rd") || !strings.Contains(string(data), "alice") {
+		t.Errorf("MarshalSecure() = %s", data)
+	}
+	var got account
+	if err := UnmarshalSecure(data, &got, c); err != nil || got.Password != "p@ssw0rd" {
+		t.Errorf("UnmarshalSecure() = %+v, %v", got, err)
+	}
+	if masked := MarshalMaskedStringX(in); !strings.Contains(masked, `"hint":"p*******"`) || strings.Contains(masked, "p@ssw0rd") {
+		t.Errorf("MarshalMasked() = %s", masked)
+	}
+	if in.Password != "p@ssw0rd" {
+		t.Errorf("MarshalSecure() modified input: %+v", in)
+	}
+
+	// 未导出的嵌入指针无法复制，拒绝而不是泄露或修改原始对象
+	type pointerAccount struct {
+		*secureCredential
+	}
+	if _, err := MarshalSecure(pointerAccount{&secureCredential{Password: "p@ssw0rd"}}, c); !errors.Is(err, ErrSecureTag) {
+		t.Errorf("MarshalSecure() error = %v, want ErrSecureTag", err)
+	}
+}
+
+func TestSecureTagInvalid(t *testing.T) {
+	type badType struct {
+		Age int `secure:"mask"`
+	}
+	type badTag struct {
+		Phone string `secure:"mask,3"`
+	}
+	if _, err := MarshalMasked(badType{Age: 1}); !errors.Is(err, ErrSecureTag) {
+		t.Errorf("MarshalMasked() error = %v, want ErrSecureTag", err)
+	}
+	if _, err := MarshalMasked(badTag{Phone: "1"}); !errors.Is(err, ErrSecureTag) {
+		t.Errorf("MarshalMasked() error = %v, want ErrSecureTag", err)
+	}
+}
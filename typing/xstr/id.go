@@ -0,0 +1,338 @@
+package xstr
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+var (
+	// ErrClockBackwards 时钟回拨超过允许范围
+	ErrClockBackwards = errors.New("lib.xstr:clock moved backwards")
+	// ErrInvalidID ID 格式错误
+	ErrInvalidID = errors.New("lib.xstr:invalid id")
+)
+
+/**
+Snowflake 64 位 ID：
+
+| 符号位 (1) | 毫秒时间戳 (41) | 机器 ID (10) | 序号 (12) |
+
+同一毫秒内序号用尽时借用下一毫秒，时钟小幅回拨时沿用上次的时间戳继续递增，
+ID 在单个生成器内严格递增。
+**/
+
+const (
+	snowflakeWorkerBits   = 10
+	snowflakeSequenceBits = 12
+	snowflakeTimeBits     = 41
+
+	// MaxSnowflakeWorkerID 机器 ID 上限
+	MaxSnowflakeWorkerID = 1<<snowflakeWorkerBits - 1
+	maxSnowflakeSequence = 1<<snowflakeSequenceBits - 1
+)
+
+// DefaultSnowflakeEpoch 默认起始时间 2020-01-01 UTC
+var DefaultSnowflakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type snowflakeConfig struct {
+	epoch        time.Time
+	workerID     int64
+	maxBackwards time.Duration
+	now          func() time.Time
+}
+
+// WithEpoch 设置起始时间，生成器之间必须一致
+func WithEpoch(epoch time.Time) xopt.Option[snowflakeConfig] {
+	return func(cfg *snowflakeConfig) {
+		cfg.epoch = epoch
+	}
+}
+
+// WithWorkerID 设置机器 ID，范围 0~1023
+// 同一 /22 网段内的部署可以使用内网 IPv4 的低 10 位：
+//
+//	xstr.WithWorkerID(int64(ip.AtoI(ip.InternalV4()) & xstr.MaxSnowflakeWorkerID))
+func WithWorkerID(id int64) xopt.Option[snowflakeConfig] {
+	return func(cfg *snowflakeConfig) {
+		cfg.workerID = id
+	}
+}
+
+// WithMaxClockBackwards 设置允许的时钟回拨，范围内继续使用上次的时间戳，超出返回 ErrClockBackwards，默认 5 秒
+func WithMaxClockBackwards(d time.Duration) xopt.Option[snowflakeConfig] {
+	return func(cfg *snowflakeConfig) {
+		cfg.maxBackwards = d
+	}
+}
+
+// WithSnowflakeClock 设置时间函数，便于测试
+func WithSnowflakeClock(now func() time.Time) xopt.Option[snowflakeConfig] {
+	return func(cfg *snowflakeConfig) {
+		cfg.now = now
+	}
+}
+
+// Snowflake 分布式 ID 生成器，并发安全
+type Snowflake struct {
+	mu           sync.Mutex
+	epoch        int64
+	workerID     int64
+	maxBackwards int64
+	now          func() time.Time
+	// clock 观察到的最大真实时间，用于判断时钟回拨
+	clock int64
+	// last 最近一次 ID 使用的时间戳，序号用尽时可能领先 clock
+	last     int64
+	sequence int64
+}
+
+// SnowflakeID 解析后的 Snowflake ID
+type SnowflakeID struct {
+	Time     time.Time
+	WorkerID int64
+	Sequence int64
+}
+
+// NewSnowflake 创建 Snowflake 生成器
+func NewSnowflake(opts ...xopt.Option[snowflakeConfig]) (*Snowflake, error) {
+	cfg := snowflakeConfig{
+		epoch:        DefaultSnowflakeEpoch,
+		maxBackwards: 5 * time.Second,
+		now:          time.Now,
+	}
+	xopt.Apply(opts, &cfg)
+	if cfg.workerID < 0 || cfg.workerID > MaxSnowflakeWorkerID {
+		return nil, fmt.Errorf("invalid worker id: %d, must be between 0 and %d", cfg.workerID, MaxSnowflakeWorkerID)
+	}
+	if cfg.epoch.After(cfg.now()) {
+		return nil, fmt.Errorf("invalid epoch: %s is in the future", cfg.epoch)
+	}
+	return &Snowflake{
+		epoch:        cfg.epoch.UnixMilli(),
+		workerID:     cfg.workerID,
+		maxBackwards: cfg.maxBackwards.Milliseconds(),
+		now:          cfg.now,
+		clock:        -1,
+		last:         -1,
+	}, nil
+}
+
+// Next 生成下一个 ID
+func (s *Snowflake) Next() (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UnixMilli() - s.epoch
+	// 只比较真实时间，借用的毫秒不算回拨
+	if s.clock-now > s.maxBackwards {
+		return 0, fmt.Errorf("%w: by %dms", ErrClockBackwards, s.clock-now)
+	}
+	s.clock = max(s.clock, now)
+	if now > s.last {
+		s.last = now
+		s.sequence = 0
+	} else {
+		// 同一毫秒或小幅回拨，序号用尽时借用下一毫秒
+		s.sequence++
+		if s.sequence > maxSnowflakeSequence {
+			s.last++
+			s.sequence = 0
+		}
+	}
+	if s.last >= 1<<snowflakeTimeBits {
+		return 0, errors.New("snowflake timestamp overflow")
+	}
+	return s.last<<(snowflakeWorkerBits+snowflakeSequenceBits) | s.workerID<<snowflakeSequenceBits | s.sequence, nil
+}
+
+// NextX 生成下一个 ID，出错时 panic，避免返回重复的 0
+func (s *Snowflake) NextX() int64 {
+	id, err := s.Next()
+	if err != nil {
+		panic(err)
+	}
+	return id
+}
+
+// Parse 解析 ID 中的时间、机器 ID 和序号
+func (s *Snowflake) Parse(id int64) SnowflakeID {
+	return SnowflakeID{
+		Time:     time.UnixMilli(id>>(snowflakeWorkerBits+snowflakeSequenceBits) + s.epoch),
+		WorkerID: id >> snowflakeSequenceBits & MaxSnowflakeWorkerID,
+		Sequence: id & maxSnowflakeSequence,
+	}
+}
+
+/**
+ULID 128 位 ID，Crockford Base32 编码为 26 个字符，可按字典序排序：
+
+| 毫秒时间戳 (48) | 随机数 (80) |
+
+同一毫秒内随机数部分递增，保证单个生成器内单调。
+**/
+
+const (
+	crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	ulidLength        = 26
+	maxULIDTime       = 1<<48 - 1
+)
+
+// crockfordDecode 不区分大小写，O 视为 0、I/L 视为 1，与 codec 的 Crockford Base32 一致
+var crockfordDecode = func() (table [256]byte) {
+	for i := range table {
+		table[i] = 0xff
+	}
+	for i := 0; i < len(crockfordAlphabet); i++ {
+		table[crockfordAlphabet[i]] = byte(i)
+		table[unicode.ToLower(rune(crockfordAlphabet[i]))] = byte(i)
+	}
+	for _, c := range "Oo" {
+		table[c] = 0
+	}
+	for _, c := range "IiLl" {
+		table[c] = 1
+	}
+	return
+}()
+
+// ULIDGenerator 单调 ULID 生成器，并发安全
+type ULIDGenerator struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	entropy io.Reader
+	last    int64
+	random  [10]byte
+}
+
+// NewULIDGenerator 创建 ULID 生成器，now 为空时使用 time.Now
+func NewULIDGenerator(now func() time.Time) *ULIDGenerator {
+	if now == nil {
+		now = time.Now
+	}
+	return &ULIDGenerator{now: now, entropy: rand.Reader, last: -1}
+}
+
+// New 生成 ULID
+func (g *ULIDGenerator) New() (string, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := g.now().UnixMilli()
+	if ms < 0 || ms > maxULIDTime {
+		return "", fmt.Errorf("ulid timestamp out of range: %d", ms)
+	}
+	if ms <= g.last {
+		// 同一毫秒或时钟回拨，沿用上次的时间戳，随机数加一
+		ms = g.last
+		if !increment(g.random[:]) {
+			// 随机数溢出，借用下一毫秒
+			ms++
+			if _, err := io.ReadFull(g.entropy, g.random[:]); err != nil {
+				return "", err
+			}
+		}
+	} else if _, err := io.ReadFull(g.entropy, g.random[:]); err != nil {
+		return "", err
+	}
+	g.last = ms
+
+	var id [16]byte
+	binary.BigEndian.PutUint16(id[0:2], uint16(ms>>32))
+	binary.BigEndian.PutUint32(id[2:6], uint32(ms))
+	copy(id[6:], g.random[:])
+	return encodeULID(id), nil
+}
+
+func increment(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeULID 将 128 位编码为 26 个字符，首字符只有 3 位
+func encodeULID(id [16]byte) string {
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+	var out [ulidLength]byte
+	for i := ulidLength - 1; i >= 0; i-- {
+		out[i] = crockfordAlphabet[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(out[:])
+}
+
+func decodeULID(s string) ([16]byte, error) {
+	var id [16]byte
+	if len(s) != ulidLength {
+		return id, fmt.Errorf("%w: ulid must be %d characters", ErrInvalidID, ulidLength)
+	}
+	var hi, lo uint64
+	for i := 0; i < ulidLength; i++ {
+		v := crockfordDecode[s[i]]
+		if v == 0xff {
+			return id, fmt.Errorf("%w: invalid ulid character %q", ErrInvalidID, s[i])
+		}
+		if i == 0 && v > 7 {
+			return id, fmt.Errorf("%w: ulid overflow", ErrInvalidID)
+		}
+		hi = hi<<5 | lo>>59
+		lo = lo<<5 | uint64(v)
+	}
+	binary.BigEndian.PutUint64(id[:8], hi)
+	binary.BigEndian.PutUint64(id[8:], lo)
+	return id, nil
+}
+
+var defaultULID = NewULIDGenerator(nil)
+
+// ULID 生成单调递增的 ULID，读取随机数失败时返回错误
+func ULID() (string, error) {
+	return defaultULID.New()
+}
+
+// ULIDTime 解析 ULID 中的时间戳
+func ULIDTime(s string) (time.Time, error) {
+	id, err := decodeULID(s)
+	if err != nil {
+		return time.Time{}, err
+	}
+	ms := int64(binary.BigEndian.Uint16(id[0:2]))<<32 | int64(binary.BigEndian.Uint32(id[2:6]))
+	return time.UnixMilli(ms), nil
+}
+
+// UUIDv7 生成按时间排序的 UUIDv7，同一进程内单调递增
+func UUIDv7() string {
+	return uuid.Must(uuid.NewV7()).String()
+}
+
+// UUIDv7X 生成不带连字符的 UUIDv7
+func UUIDv7X() string {
+	return strings.ReplaceAll(UUIDv7(), HYPHEN, EMPTY)
+}
+
+// UUIDTime 解析 UUIDv7 中的毫秒时间戳
+func UUIDTime(s string) (time.Time, error) {
+	u, err := uuid.Parse(s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %w", ErrInvalidID, err)
+	}
+	if u.Version() != 7 {
+		return time.Time{}, fmt.Errorf("%w: uuid version %d has no unix timestamp", ErrInvalidID, u.Version())
+	}
+	return time.UnixMilli(int64(binary.BigEndian.Uint64(u[:8]) >> 16)), nil
+}
@@ -0,0 +1,195 @@
+package xstr
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+)
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestSnowflake(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	s, err := NewSnowflake(WithWorkerID(7), WithSnowflakeClock(clock.Now))
+	if err != nil {
+		t.Fatalf("NewSnowflake() error = %v", err)
+	}
+
+	// 同一毫秒内超过 4096 个 ID 仍然严格递增
+	var last int64
+	for i := 0; i < 10000; i++ {
+		id, err := s.Next()
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if id <= last {
+			t.Fatalf("Next() = %d, not greater than %d", id, last)
+		}
+		last = id
+	}
+
+	clock.Add(time.Second)
+	id := s.NextX()
+	parsed := s.Parse(id)
+	if !parsed.Time.Equal(clock.Now()) || parsed.WorkerID != 7 || parsed.Sequence != 0 {
+		t.Errorf("Parse() = %+v", parsed)
+	}
+
+	// 小幅回拨继续递增
+	clock.Add(-time.Second)
+	if next := s.NextX(); next <= id {
+		t.Errorf("Next() after small rollback = %d, want > %d", next, id)
+	}
+	// 超出允许范围
+	clock.Add(-10 * time.Second)
+	if _, err := s.Next(); !errors.Is(err, ErrClockBackwards) {
+		t.Errorf("Next() error = %v, want ErrClockBackwards", err)
+	}
+}
+
+func TestSnowflakeStrict(t *testing.T) {
+	// 不允许回拨时，序号用尽借用下一毫秒不应被当作时钟回拨
+	clock := &fakeClock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+	s, _ := NewSnowflake(WithMaxClockBackwards(0), WithSnowflakeClock(clock.Now))
+	for i := 0; i < 3*(maxSnowflakeSequence+1); i++ {
+		if _, err := s.Next(); err != nil {
+			t.Fatalf("Next() #%d error = %v", i, err)
+		}
+	}
+	clock.Add(-time.Millisecond)
+	if _, err := s.Next(); !errors.Is(err, ErrClockBackwards) {
+		t.Errorf("Next() error = %v, want ErrClockBackwards", err)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("NextX() should panic on error")
+		}
+	}()
+	s.NextX()
+}
+
+func TestSnowflakeOptions(t *testing.T) {
+	if _, err := NewSnowflake(WithWorkerID(MaxSnowflakeWorkerID + 1)); err == nil {
+		t.Error("NewSnowflake() expected error for invalid worker id")
+	}
+	if _, err := NewSnowflake(WithEpoch(time.Now().Add(time.Hour))); err == nil {
+		t.Error("NewSnowflake() expected error for future epoch")
+	}
+
+	epoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s, _ := NewSnowflake(WithEpoch(epoch), WithWorkerID(1023))
+	parsed := s.Parse(s.NextX())
+	if parsed.WorkerID != 1023 || time.Since(parsed.Time) > time.Second {
+		t.Errorf("Parse() = %+v", parsed)
+	}
+}
+
+func TestSnowflakeConcurrent(t *testing.T) {
+	s, _ := NewSnowflake()
+	var mu sync.Mutex
+	seen := map[int64]bool{}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				id := s.NextX()
+				mu.Lock()
+				if seen[id] {
+					t.Errorf("duplicate id %d", id)
+				}
+				seen[id] = true
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestULID(t *testing.T) {
+	clock := &fakeClock{now: time.UnixMilli(1469918176385)}
+	g := NewULIDGenerator(clock.Now)
+
+	ids := make([]string, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		id, err := g.New()
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if len(id) != 26 {
+			t.Fatalf("New() = %q, want 26 characters", id)
+		}
+		ids = append(ids, id)
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Error("ULIDs within the same millisecond should be monotonic")
+	}
+	// 规范中的示例时间戳编码为 01ARYZ6S41
+	if !strings.HasPrefix(ids[0], "01ARYZ6S41") {
+		t.Errorf("New() = %q, want prefix 01ARYZ6S41", ids[0])
+	}
+
+	ts, err := ULIDTime(strings.ToLower(ids[0]))
+	if err != nil || ts.UnixMilli() != 1469918176385 {
+		t.Errorf("ULIDTime() = %v, %v", ts, err)
+	}
+	for _, bad := range []string{"", "01ARYZ6S41", "81ARYZ6S41TSV4RRFFQ69G5FAV", "01ARYZ6S41TSV4RRFFQ69G5FAU"} {
+		if _, err := ULIDTime(bad); !errors.Is(err, ErrInvalidID) {
+			t.Errorf("ULIDTime(%q) error = %v, want ErrInvalidID", bad, err)
+		}
+	}
+
+	a, err1 := ULID()
+	b, err2 := ULID()
+	if err1 != nil || err2 != nil || a >= b {
+		t.Errorf("ULID() not monotonic: %q, %v >= %q, %v", a, err1, b, err2)
+	}
+
+	// 随机数读取失败时返回错误，而不是空 ID
+	eg := NewULIDGenerator(nil)
+	eg.entropy = iotest.ErrReader(errors.New("entropy exhausted"))
+	if id, err := eg.New(); err == nil || id != "" {
+		t.Errorf("New() = %q, %v, want entropy error", id, err)
+	}
+}
+
+func TestUUIDv7(t *testing.T) {
+	before := time.Now().Truncate(time.Millisecond)
+	ids := make([]string, 100)
+	for i := range ids {
+		ids[i] = UUIDv7()
+	}
+	if !sort.StringsAreSorted(ids) {
+		t.Error("UUIDv7 should be monotonic")
+	}
+	ts, err := UUIDTime(ids[0])
+	if err != nil || ts.Before(before) || time.Since(ts) > time.Second {
+		t.Errorf("UUIDTime() = %v, %v", ts, err)
+	}
+	if x := UUIDv7X(); len(x) != 32 || strings.Contains(x, "-") {
+		t.Errorf("UUIDv7X() = %q", x)
+	}
+	if _, err := UUIDTime(UUID()); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("UUIDTime(v4) error = %v, want ErrInvalidID", err)
+	}
+}
@@ -0,0 +1,441 @@
+package xhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/monaco-io/lib/typing/xopt"
+	"github.com/monaco-io/lib/xlog"
+)
+
+// ErrCircuitOpen 熔断器打开时的快速失败错误，可用 errors.Is 判断
+var ErrCircuitOpen = errors.New("lib.xhttp:circuit breaker is open")
+
+// BreakerState 熔断器状态
+type BreakerState int
+
+const (
+	StateClosed BreakerState = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("BreakerState(%d)", int(s))
+}
+
+// CircuitOpenError 熔断期间返回的错误
+type CircuitOpenError struct {
+	Host  string
+	State BreakerState
+	// RetryAfter 距离进入半开状态的剩余时间，半开状态下为 0
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: host=%s state=%s retry_after=%s", ErrCircuitOpen, e.Host, e.State, e.RetryAfter)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+const (
+	DefaultBreakerWindow      = 100
+	DefaultBreakerMinCalls    = 20
+	DefaultBreakerFailureRate = 0.5
+	DefaultBreakerOpenTimeout = 30 * time.Second
+	DefaultBreakerProbeCalls  = 5
+)
+
+type breakerConfig struct {
+	window        int
+	minCalls      int
+	failureRate   float64
+	slowDuration  time.Duration
+	slowRate      float64
+	openTimeout   time.Duration
+	probeCalls    int
+	isFailure     func(resp *http.Response, err error) bool
+	onStateChange func(host string, from, to BreakerState)
+	now           func() time.Time
+}
+
+// BreakerWindow 滑动窗口记录最近 size 次调用，至少 minCalls 次调用后才计算比例
+func BreakerWindow(size, minCalls int) xopt.Option[breakerConfig] {
+	return func(cfg *breakerConfig) {
+		cfg.window = size
+		cfg.minCalls = minCalls
+	}
+}
+
+// BreakerFailureRate 失败比例达到 rate 时打开熔断，默认为 0.5
+func BreakerFailureRate(rate float64) xopt.Option[breakerConfig] {
+	return func(cfg *breakerConfig) {
+		cfg.failureRate = rate
+	}
+}
+
+// BreakerSlowCall 耗时不低于 d 的调用视为慢调用，慢调用比例达到 rate 时打开熔断
+// 默认不统计慢调用
+func BreakerSlowCall(d time.Duration, rate float64) xopt.Option[breakerConfig] {
+	return func(cfg *breakerConfig) {
+		cfg.slowDuration = d
+		cfg.slowRate = rate
+	}
+}
+
+// BreakerOpenTimeout 打开状态持续 d 后进入半开状态，默认为 30s
+func BreakerOpenTimeout(d time.Duration) xopt.Option[breakerConfig] {
+	return func(cfg *breakerConfig) {
+		cfg.openTimeout = d
+	}
+}
+
+// BreakerProbeCalls 半开状态允许通过的探测请求数，全部完成后决定关闭或重新打开，默认为 5
+func BreakerProbeCalls(n int) xopt.Option[breakerConfig] {
+	return func(cfg *breakerConfig) {
+		cfg.probeCalls = n
+	}
+}
+
+// BreakerIsFailure 自定义失败判定，默认网络错误和 5xx 响应视为失败
+func BreakerIsFailure(f func(resp *http.Response, err error) bool) xopt.Option[breakerConfig] {
+	return func(cfg *breakerConfig) {
+		cfg.isFailure = f
+	}
+}
+
+// BreakerOnStateChange 状态变化回调，在持有锁之外同步调用
+func BreakerOnStateChange(f func(host string, from, to BreakerState)) xopt.Option[breakerConfig] {
+	return func(cfg *breakerConfig) {
+		cfg.onStateChange = f
+	}
+}
+
+// BreakerClock 自定义时钟，主要用于测试
+func BreakerClock(now func() time.Time) xopt.Option[breakerConfig] {
+	return func(cfg *breakerConfig) {
+		cfg.now = now
+	}
+}
+
+// LogBreakerStateChange 使用 xlog 记录状态变化，可直接传给 BreakerOnStateChange
+func LogBreakerStateChange(host string, from, to BreakerState) {
+	msg := "xhttp circuit breaker state changed"
+	if to == StateOpen {
+		xlog.W(context.Background(), msg, "host", host, "from", from.String(), "to", to.String())
+		return
+	}
+	xlog.I(context.Background(), msg, "host", host, "from", from.String(), "to", to.String())
+}
+
+func defaultIsFailure(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+	return resp == nil || resp.StatusCode >= http.StatusInternalServerError
+}
+
+// CircuitBreaker 按 host 隔离的熔断器，可以在多个请求间共享
+type CircuitBreaker struct {
+	cfg   breakerConfig
+	mu    sync.Mutex
+	hosts map[string]*hostBreaker
+}
+
+// NewCircuitBreaker 创建熔断器
+func NewCircuitBreaker(opts ...xopt.Option[breakerConfig]) *CircuitBreaker {
+	cfg := breakerConfig{
+		window:      DefaultBreakerWindow,
+		minCalls:    DefaultBreakerMinCalls,
+		failureRate: DefaultBreakerFailureRate,
+		openTimeout: DefaultBreakerOpenTimeout,
+		probeCalls:  DefaultBreakerProbeCalls,
+		isFailure:   defaultIsFailure,
+		now:         time.Now,
+	}
+	xopt.Apply(opts, &cfg)
+	cfg.window = max(cfg.window, 1)
+	cfg.minCalls = min(max(cfg.minCalls, 1), cfg.window)
+	cfg.probeCalls = min(max(cfg.probeCalls, 1), cfg.window)
+	return &CircuitBreaker{cfg: cfg, hosts: make(map[string]*hostBreaker)}
+}
+
+// State 返回 host 当前状态
+func (cb *CircuitBreaker) State(host string) BreakerState {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	h, ok := cb.hosts[host]
+	if !ok {
+		return StateClosed
+	}
+	if h.state == StateOpen && cb.cfg.now().Sub(h.openedAt) >= cb.cfg.openTimeout {
+		return StateHalfOpen
+	}
+	return h.state
+}
+
+// Allow 申请一次调用，熔断时返回 *CircuitOpenError
+// 调用结束后必须执行 done 上报结果
+func (cb *CircuitBreaker) Allow(host string) (done func(resp *http.Response, err error), err error) {
+	call, err := cb.allow(host)
+	if err != nil {
+		return nil, err
+	}
+	return call.done, nil
+}
+
+func (cb *CircuitBreaker) allow(host string) (*breakerCall, error) {
+	cb.mu.Lock()
+	h, ok := cb.hosts[host]
+	if !ok {
+		h = newHostBreaker(cb.cfg.window)
+		cb.hosts[host] = h
+	}
+	now := cb.cfg.now()
+	var changes []stateChange
+	if h.state == StateOpen {
+		if wait := cb.cfg.openTimeout - now.Sub(h.openedAt); wait > 0 {
+			cb.mu.Unlock()
+			return nil, &CircuitOpenError{Host: host, State: StateOpen, RetryAfter: wait}
+		}
+		changes = append(changes, h.transition(StateHalfOpen, now))
+	}
+	if h.state == StateHalfOpen {
+		if h.probes >= cb.cfg.probeCalls {
+			cb.mu.Unlock()
+			cb.notify(host, changes)
+			return nil, &CircuitOpenError{Host: host, State: StateHalfOpen}
+		}
+		h.probes++
+	}
+	generation := h.generation
+	cb.mu.Unlock()
+	cb.notify(host, changes)
+	return &breakerCall{cb: cb, host: host, h: h, generation: generation, start: now}, nil
+}
+
+// breakerCall 一次已放行的调用，done 和 release 只生效一次
+type breakerCall struct {
+	cb         *CircuitBreaker
+	host       string
+	h          *hostBreaker
+	generation uint64
+	start      time.Time
+	once       sync.Once
+}
+
+func (c *breakerCall) done(resp *http.Response, err error) {
+	c.once.Do(func() {
+		c.cb.record(c.host, c.h, c.generation, c.cb.cfg.isFailure(resp, err), c.cb.cfg.now().Sub(c.start))
+	})
+}
+
+// release 调用未发出，归还半开状态的探测名额，不计入统计
+func (c *breakerCall) release() {
+	c.once.Do(func() {
+		c.cb.mu.Lock()
+		defer c.cb.mu.Unlock()
+		if c.h.generation == c.generation && c.h.state == StateHalfOpen && c.h.probes > 0 {
+			c.h.probes--
+		}
+	})
+}
+
+// record 上报调用结果，状态已变化时忽略旧状态下发起的调用
+func (cb *CircuitBreaker) record(host string, h *hostBreaker, generation uint64, failure bool, elapsed time.Duration) {
+	slow := cb.cfg.slowDuration > 0 && elapsed >= cb.cfg.slowDuration
+
+	cb.mu.Lock()
+	if h.generation != generation {
+		cb.mu.Unlock()
+		return
+	}
+	h.add(failure, slow)
+	var changes []stateChange
+	switch h.state {
+	case StateClosed:
+		if h.count >= cb.cfg.minCalls && cb.tripped(h) {
+			changes = append(changes, h.transition(StateOpen, cb.cfg.now()))
+		}
+	case StateHalfOpen:
+		if h.count >= cb.cfg.probeCalls {
+			to := StateClosed
+			if cb.tripped(h) {
+				to = StateOpen
+			}
+			changes = append(changes, h.transition(to, cb.cfg.now()))
+		}
+	}
+	cb.mu.Unlock()
+	cb.notify(host, changes)
+}
+
+func (cb *CircuitBreaker) tripped(h *hostBreaker) bool {
+	total := float64(h.count)
+	if float64(h.failures)/total >= cb.cfg.failureRate {
+		return true
+	}
+	return cb.cfg.slowDuration > 0 && float64(h.slows)/total >= cb.cfg.slowRate
+}
+
+func (cb *CircuitBreaker) notify(host string, changes []stateChange) {
+	if cb.cfg.onStateChange == nil {
+		return
+	}
+	for _, c := range changes {
+		cb.cfg.onStateChange(host, c.from, c.to)
+	}
+}
+
+// RoundTripper 包装 next，按请求 host 熔断，next 为 nil 时使用 http.DefaultTransport
+func (cb *CircuitBreaker) RoundTripper(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &breakerTransport{next: next, cb: cb}
+}
+
+// Interceptor 以拦截器方式接入，熔断时 Before 返回 *CircuitOpenError
+// 拦截器拿不到网络错误，响应为 nil 时以请求 context 的错误上报，context 被取消不计为失败
+// 注册在其后的拦截器 Before 失败时释放占用的调用，不计入统计
+func (cb *CircuitBreaker) Interceptor() Interceptor {
+	return &breakerInterceptor{cb: cb}
+}
+
+// errNoResponse 拦截器模式下请求未得到响应
+var errNoResponse = errors.New("lib.xhttp:no response")
+
+type breakerInterceptor struct {
+	cb *CircuitBreaker
+	// pending 记录 Before 到 After 之间的请求
+	pending sync.Map
+}
+
+func (i *breakerInterceptor) Before(req *http.Request) error {
+	call, err := i.cb.allow(req.URL.Host)
+	if err != nil {
+		return err
+	}
+	i.pending.Store(req, call)
+	return nil
+}
+
+func (i *breakerInterceptor) After(resp *http.Response, req *http.Request) error {
+	call, ok := i.pending.LoadAndDelete(req)
+	if !ok {
+		return nil
+	}
+	var err error
+	if resp == nil {
+		if err = req.Context().Err(); err == nil {
+			err = errNoResponse
+		}
+	}
+	call.(*breakerCall).done(resp, err)
+	return nil
+}
+
+// abort 请求未发送，释放半开状态占用的探测名额
+func (i *breakerInterceptor) abort(req *http.Request) {
+	if call, ok := i.pending.LoadAndDelete(req); ok {
+		call.(*breakerCall).release()
+	}
+}
+
+// Breaker 为当前请求开启熔断，cb 应在多个请求间共享
+func Breaker(cb *CircuitBreaker) xopt.Option[Request] {
+	return func(request *Request) {
+		request.ownClient()
+		request.Transport = cb.RoundTripper(request.Transport)
+	}
+}
+
+type breakerTransport struct {
+	next http.RoundTripper
+	cb   *CircuitBreaker
+}
+
+func (t *breakerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	done, err := t.cb.Allow(req.URL.Host)
+	if err != nil {
+		if req.Body != nil {
+			_ = req.Body.Close()
+		}
+		return nil, err
+	}
+	resp, err := t.next.RoundTrip(req)
+	done(resp, err)
+	return resp, err
+}
+
+type stateChange struct {
+	from, to BreakerState
+}
+
+// hostBreaker 单个 host 的状态和滑动窗口
+type hostBreaker struct {
+	state      BreakerState
+	generation uint64
+	openedAt   time.Time
+	probes     int
+
+	// 环形缓冲区，bit0 表示失败，bit1 表示慢调用
+	outcomes []uint8
+	next     int
+	count    int
+	failures int
+	slows    int
+}
+
+func newHostBreaker(window int) *hostBreaker {
+	return &hostBreaker{outcomes: make([]uint8, window)}
+}
+
+func (h *hostBreaker) add(failure, slow bool) {
+	if h.count == len(h.outcomes) {
+		old := h.outcomes[h.next]
+		h.failures -= int(old & 1)
+		h.slows -= int(old >> 1)
+	} else {
+		h.count++
+	}
+	var v uint8
+	if failure {
+		v |= 1
+		h.failures++
+	}
+	if slow {
+		v |= 2
+		h.slows++
+	}
+	h.outcomes[h.next] = v
+	h.next = (h.next + 1) % len(h.outcomes)
+}
+
+// transition 切换状态并清空窗口
+func (h *hostBreaker) transition(to BreakerState, now time.Time) stateChange {
+	change := stateChange{from: h.state, to: to}
+	h.state = to
+	h.generation++
+	h.probes = 0
+	h.next, h.count, h.failures, h.slows = 0, 0, 0, 0
+	clear(h.outcomes)
+	if to == StateOpen {
+		h.openedAt = now
+	}
+	return change
+}
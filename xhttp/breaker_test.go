@@ -0,0 +1,203 @@
+package xhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestCircuitBreakerStates(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	var changes []string
+	cb := NewCircuitBreaker(
+		BreakerWindow(4, 4),
+		BreakerFailureRate(0.5),
+		BreakerOpenTimeout(time.Second),
+		BreakerProbeCalls(2),
+		BreakerClock(clock.Now),
+		BreakerOnStateChange(func(host string, from, to BreakerState) {
+			changes = append(changes, from.String()+"->"+to.String())
+		}),
+	)
+	const host = "example.com"
+	call := func(status int) error {
+		done, err := cb.Allow(host)
+		if err != nil {
+			return err
+		}
+		done(&http.Response{StatusCode: status}, nil)
+		return nil
+	}
+
+	// 窗口未满时不熔断
+	for _, status := range []int{500, 500, 200} {
+		if err := call(status); err != nil {
+			t.Fatalf("Allow() error = %v", err)
+		}
+	}
+	if got := cb.State(host); got != StateClosed {
+		t.Fatalf("State() = %v, want closed", got)
+	}
+	_ = call(200)
+	if got := cb.State(host); got != StateOpen {
+		t.Fatalf("State() = %v, want open", got)
+	}
+
+	err := call(200)
+	var openErr *CircuitOpenError
+	if !errors.As(err, &openErr) || !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Allow() error = %v, want CircuitOpenError", err)
+	}
+	if openErr.Host != host || openErr.RetryAfter != time.Second {
+		t.Errorf("CircuitOpenError = %+v", openErr)
+	}
+
+	// 半开：探测失败后重新打开
+	clock.Advance(time.Second)
+	if got := cb.State(host); got != StateHalfOpen {
+		t.Fatalf("State() = %v, want half-open", got)
+	}
+	done1, err := cb.Allow(host)
+	if err != nil {
+		t.Fatalf("Allow() probe error = %v", err)
+	}
+	done2, err := cb.Allow(host)
+	if err != nil {
+		t.Fatalf("Allow() probe error = %v", err)
+	}
+	if _, err := cb.Allow(host); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Allow() beyond probes error = %v, want ErrCircuitOpen", err)
+	}
+	done1(nil, errors.New("dial failed"))
+	done2(&http.Response{StatusCode: 200}, nil)
+	if got := cb.State(host); got != StateOpen {
+		t.Fatalf("State() = %v, want open", got)
+	}
+
+	// 半开：探测成功后关闭
+	clock.Advance(time.Second)
+	_ = call(200)
+	_ = call(200)
+	if got := cb.State(host); got != StateClosed {
+		t.Fatalf("State() = %v, want closed", got)
+	}
+	if got := cb.State("other.com"); got != StateClosed {
+		t.Errorf("State(other) = %v, want closed", got)
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}
+	if len(changes) != len(want) {
+		t.Fatalf("changes = %v, want %v", changes, want)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("changes[%d] = %s, want %s", i, changes[i], want[i])
+		}
+	}
+}
+
+func TestCircuitBreakerSlowCall(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	cb := NewCircuitBreaker(
+		BreakerWindow(2, 2),
+		BreakerSlowCall(time.Second, 1),
+		BreakerClock(clock.Now),
+	)
+	for range 2 {
+		done, err := cb.Allow("slow.com")
+		if err != nil {
+			t.Fatalf("Allow() error = %v", err)
+		}
+		clock.Advance(2 * time.Second)
+		done(&http.Response{StatusCode: 200}, nil)
+	}
+	if got := cb.State("slow.com"); got != StateOpen {
+		t.Errorf("State() = %v, want open", got)
+	}
+}
+
+func TestBreakerOption(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	cb := NewCircuitBreaker(BreakerWindow(2, 2))
+	for range 2 {
+		if _, err := Do(context.Background(), server.URL, Breaker(cb)); err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+	}
+	// 熔断后快速失败，重试不会继续请求
+	_, err := Do(context.Background(), server.URL, Breaker(cb), Retry(RetryBackoff(0, 0)))
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Do() error = %v, want ErrCircuitOpen", err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+	if http.DefaultClient.Transport != nil {
+		t.Error("Breaker() should not modify http.DefaultClient")
+	}
+}
+
+func TestBreakerInterceptor(t *testing.T) {
+	cb := NewCircuitBreaker(BreakerWindow(1, 1))
+	i := cb.Interceptor()
+	req := &http.Request{URL: &url.URL{Host: "example.com"}}
+	// 被取消的请求不计为失败
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := i.Before(req.WithContext(ctx)); err != nil {
+		t.Fatalf("Before() error = %v", err)
+	}
+	_ = i.After(nil, req.WithContext(ctx))
+	if err := i.Before(req); err != nil {
+		t.Fatalf("Before() error = %v", err)
+	}
+	_ = i.After(nil, req)
+	if err := i.Before(req); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Before() error = %v, want ErrCircuitOpen", err)
+	}
+}
+
+func TestBreakerInterceptorAbort(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	cb := NewCircuitBreaker(BreakerWindow(1, 1), BreakerProbeCalls(1), BreakerOpenTimeout(time.Second), BreakerClock(clock.Now))
+	host := server.Listener.Addr().String()
+	done, _ := cb.Allow(host)
+	done(nil, errors.New("boom"))
+	clock.Advance(time.Second)
+
+	// 后续拦截器 Before 失败时归还半开状态的探测名额
+	errReject := errors.New("rejected")
+	client := NewClient(ClientInterceptors(cb.Interceptor(), &InterceptorFunc{
+		BeforeFunc: func(*http.Request) error { return errReject },
+	}))
+	for range 3 {
+		if _, err := client.Do(context.Background(), server.URL); !errors.Is(err, errReject) {
+			t.Fatalf("Do() error = %v, want rejected", err)
+		}
+	}
+	client = NewClient(ClientInterceptors(cb.Interceptor()))
+	if _, err := client.Do(context.Background(), server.URL); err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if state := cb.State(host); state != StateClosed {
+		t.Errorf("State() = %s, want closed", state)
+	}
+}
@@ -0,0 +1,192 @@
+package xhttp
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+// XClient 实例级别的 HTTP 客户端，持有基础 URL、默认请求头、底层 client 和拦截器链
+// 适合为每个下游服务创建一个实例并复用
+type XClient struct {
+	baseURL   string
+	header    http.Header
+	client    *http.Client
+	timeout   time.Duration
+	transport http.RoundTripper
+	options   []xopt.Option[Request]
+
+	mu           sync.RWMutex
+	interceptors []Interceptor
+}
+
+// DefaultClient 包级函数 Do、Sugar 使用的客户端
+var DefaultClient = NewClient()
+
+// BaseURL 设置基础 URL，请求地址不含 scheme 时拼接在其后
+func BaseURL(baseURL string) xopt.Option[XClient] {
+	return func(c *XClient) {
+		c.baseURL = baseURL
+	}
+}
+
+// DefaultHeader 设置每个请求的默认请求头，请求级别使用 Header(key, value, true) 覆盖
+func DefaultHeader(key, value string) xopt.Option[XClient] {
+	return func(c *XClient) {
+		c.header.Add(key, value)
+	}
+}
+
+// HTTPClient 指定底层 client，默认为 http.DefaultClient
+func HTTPClient(client *http.Client) xopt.Option[XClient] {
+	return func(c *XClient) {
+		c.client = client
+	}
+}
+
+// ClientTimeout 设置底层 client 的超时时间
+func ClientTimeout(timeout time.Duration) xopt.Option[XClient] {
+	return func(c *XClient) {
+		c.timeout = timeout
+	}
+}
+
+// ClientTransport 设置底层 client 的 Transport
+func ClientTransport(transport http.RoundTripper) xopt.Option[XClient] {
+	return func(c *XClient) {
+		c.transport = transport
+	}
+}
+
+// ClientInterceptors 按顺序追加拦截器
+func ClientInterceptors(i ...Interceptor) xopt.Option[XClient] {
+	return func(c *XClient) {
+		c.interceptors = append(c.interceptors, i...)
+	}
+}
+
+// ClientOptions 每个请求默认应用的请求选项，先于请求级别的选项执行
+// 如 ClientOptions(Retry(), Breaker(cb))
+func ClientOptions(opts ...xopt.Option[Request]) xopt.Option[XClient] {
+	return func(c *XClient) {
+		c.options = append(c.options, opts...)
+	}
+}
+
+// NewClient 创建客户端
+func NewClient(opts ...xopt.Option[XClient]) *XClient {
+	c := &XClient{header: make(http.Header)}
+	xopt.Apply(opts, c)
+	if c.client == nil {
+		c.client = http.DefaultClient
+	}
+	if c.timeout > 0 || c.transport != nil {
+		// 复制 client，避免修改共享的 http.DefaultClient
+		client := *c.client
+		if c.timeout > 0 {
+			client.Timeout = c.timeout
+		}
+		if c.transport != nil {
+			client.Transport = c.transport
+		}
+		c.client = &client
+	}
+	return c
+}
+
+// Use 按顺序追加拦截器，对之后发起的请求生效
+func (c *XClient) Use(i ...Interceptor) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// 写时复制，进行中的请求持有旧的切片
+	c.interceptors = append(slices.Clip(c.interceptors), i...)
+}
+
+// Interceptors 返回当前的拦截器链
+func (c *XClient) Interceptors() []Interceptor {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return slices.Clip(c.interceptors)
+}
+
+// URL 将请求地址解析为完整 URL
+func (c *XClient) URL(rawURL string) string {
+	switch {
+	case c.baseURL == "" || strings.Contains(rawURL, "://"):
+		return rawURL
+	case rawURL == "":
+		return c.baseURL
+	case strings.HasPrefix(rawURL, "?"):
+		return c.baseURL + rawURL
+	}
+	return strings.TrimRight(c.baseURL, "/") + "/" + strings.TrimLeft(rawURL, "/")
+}
+
+// NewRequest 构造请求但不发送，不执行拦截器，可用于 Curl 导出
+func (c *XClient) NewRequest(ctx context.Context, url string, opts ...xopt.Option[Request]) (*Request, error) {
+	ctx = withTraceContext(ctx)
+	request, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet, c.URL(url), nil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.header {
+		request.Header[key] = slices.Clone(values)
+	}
+	xrequest := &Request{
+		Request:      request,
+		Client:       c.client,
+		decoder:      defaultDecoder,
+		interceptors: c.Interceptors(),
+	}
+	xopt.Apply(c.options, xrequest)
+	xopt.Apply(opts, xrequest)
+	if xrequest.err != nil {
+		return nil, xrequest.err
+	}
+	return xrequest, nil
+}
+
+func (c *XClient) build(ctx context.Context, url string, opts ...xopt.Option[Request]) (*Request, error) {
+	xrequest, err := c.NewRequest(ctx, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := xrequest.before(); err != nil {
+		return nil, err
+	}
+	return xrequest, nil
+}
+
+// before 执行所有拦截器的 Before 方法，失败时释放已执行的拦截器
+// 每次 before 成功后应当发送一次请求，doWithInterceptors 会执行对应的 After
+func (r *Request) before() error {
+	for n, i := range r.interceptors {
+		if err := i.Before(r.Request); err != nil {
+			abortInterceptors(r.interceptors[:n], r.Request)
+			return err
+		}
+	}
+	return nil
+}
+
+// interceptorAborter 拦截器可选实现，后续拦截器 Before 失败、请求不会发送时调用
+// 用于释放 Before 中占用的资源，此时不会调用 After
+type interceptorAborter interface {
+	abort(req *http.Request)
+}
+
+func abortInterceptors(interceptors []Interceptor, req *http.Request) {
+	for _, i := range slices.Backward(interceptors) {
+		if a, ok := i.(interceptorAborter); ok {
+			a.abort(req)
+		}
+	}
+}
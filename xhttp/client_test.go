@@ -0,0 +1,92 @@
+package xhttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestXClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ContentType, ContentTypeJSON)
+		_, _ = w.Write([]byte(`{"path":"` + r.URL.Path + `","token":"` + r.Header.Get("X-Token") + `","trace":"` + r.Header.Get("X-Trace") + `"}`))
+	}))
+	defer server.Close()
+
+	var order []string
+	interceptor := func(name string) Interceptor {
+		return &InterceptorFunc{BeforeFunc: func(req *http.Request) error {
+			order = append(order, name)
+			req.Header.Set("X-Trace", strings.Join(order, ","))
+			return nil
+		}}
+	}
+	client := NewClient(
+		BaseURL(server.URL+"/api/"),
+		DefaultHeader("X-Token", "t1"),
+		ClientTimeout(time.Second),
+		ClientInterceptors(interceptor("a")),
+	)
+	client.Use(interceptor("b"))
+
+	type result struct {
+		Path  string `json:"path"`
+		Token string `json:"token"`
+		Trace string `json:"trace"`
+	}
+	resp, err := ClientSugar[result](client, context.Background(), "/users")
+	if err != nil {
+		t.Fatalf("ClientSugar() error = %v", err)
+	}
+	want := result{Path: "/api/users", Token: "t1", Trace: "a,b"}
+	if resp.Body != want {
+		t.Errorf("ClientSugar() = %+v, want %+v", resp.Body, want)
+	}
+
+	order = nil
+	resp, err = ClientSugar[result](client, context.Background(), "users", Header("X-Token", "t2", true))
+	if err != nil {
+		t.Fatalf("ClientSugar() error = %v", err)
+	}
+	if resp.Body.Token != "t2" {
+		t.Errorf("Token = %q, want t2", resp.Body.Token)
+	}
+
+	if http.DefaultClient.Timeout != 0 {
+		t.Error("NewClient() should not modify http.DefaultClient")
+	}
+	if len(DefaultClient.Interceptors()) != 0 {
+		t.Error("XClient interceptors should not leak into DefaultClient")
+	}
+}
+
+func TestXClientURL(t *testing.T) {
+	tests := []struct {
+		base, url, want string
+	}{
+		{"", "http://a.com/x", "http://a.com/x"},
+		{"http://a.com", "http://b.com/x", "http://b.com/x"},
+		{"http://a.com/api", "", "http://a.com/api"},
+		{"http://a.com/api", "?q=1", "http://a.com/api?q=1"},
+		{"http://a.com/api/", "/users", "http://a.com/api/users"},
+		{"http://a.com/api", "users?q=1", "http://a.com/api/users?q=1"},
+	}
+	for _, tt := range tests {
+		if got := NewClient(BaseURL(tt.base)).URL(tt.url); got != tt.want {
+			t.Errorf("URL(%q, %q) = %q, want %q", tt.base, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestRequestOptionsDoNotModifyDefaultClient(t *testing.T) {
+	req, err := build(context.Background(), "http://example.com", Timeout(time.Second), Jar(nil))
+	if err != nil {
+		t.Fatalf("build() error = %v", err)
+	}
+	if req.Client == http.DefaultClient || http.DefaultClient.Timeout != 0 {
+		t.Error("Timeout() should copy the client instead of modifying http.DefaultClient")
+	}
+}
@@ -0,0 +1,326 @@
+package xhttp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/monaco-io/lib/codec"
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+const (
+	AcceptEncoding  = "Accept-Encoding"
+	ContentEncoding = "Content-Encoding"
+	ContentLength   = "Content-Length"
+
+	EncodingGZip = "gzip"
+)
+
+type gzipConfig struct {
+	algorithm       string
+	level           int
+	compressRequest bool
+	maxSize         int64
+}
+
+// CompressAlgorithm 指定压缩请求体、响应体使用的 codec 压缩算法，默认为 gzip
+// 算法必须有对应的 HTTP Content-Encoding 标识
+func CompressAlgorithm(name string) xopt.Option[gzipConfig] {
+	return func(cfg *gzipConfig) {
+		cfg.algorithm = name
+	}
+}
+
+// GZipLevel 设置压缩级别，默认为 gzip.DefaultCompression
+func GZipLevel(level int) xopt.Option[gzipConfig] {
+	return func(cfg *gzipConfig) {
+		cfg.level = level
+	}
+}
+
+// GZipRequestBody 客户端发送前压缩请求体
+func GZipRequestBody() xopt.Option[gzipConfig] {
+	return func(cfg *gzipConfig) {
+		cfg.compressRequest = true
+	}
+}
+
+// GZipMaxSize 限制解压后的最大字节数，防止解压炸弹，<=0 表示不限制
+func GZipMaxSize(n int64) xopt.Option[gzipConfig] {
+	return func(cfg *gzipConfig) {
+		cfg.maxSize = n
+	}
+}
+
+func newGZipConfig(opts ...xopt.Option[gzipConfig]) gzipConfig {
+	cfg := gzipConfig{algorithm: codec.CompressGZip, level: codec.DefaultCompression}
+	xopt.Apply(opts, &cfg)
+	return cfg
+}
+
+// acceptEncodings 所有已注册且有 HTTP 标识的压缩算法
+func acceptEncodings() string {
+	var tokens []string
+	for _, c := range codec.Compressors() {
+		if c.Encoding() != "" {
+			tokens = append(tokens, c.Encoding())
+		}
+	}
+	return strings.Join(tokens, ", ")
+}
+
+// newDecodeBody 按 Content-Encoding 创建解压读取器
+func newDecodeBody(encoding string, body io.Reader, maxSize int64) (io.ReadCloser, error) {
+	c, ok := codec.GetCompressorByEncoding(encoding)
+	if !ok {
+		return nil, fmt.Errorf("%w: content-encoding %s", codec.ErrUnknownCompressor, encoding)
+	}
+	return codec.NewDecompressReader(c.Name(), body, codec.WithMaxSize(maxSize))
+}
+
+// gzipTransport 透明压缩请求体、解压响应体的 RoundTripper
+// 响应体支持所有已注册的 codec 压缩算法
+type gzipTransport struct {
+	next http.RoundTripper
+	gzipConfig
+}
+
+// NewGZipTransport 包装 next，自动协商压缩算法并流式解压响应
+// next 为 nil 时使用 http.DefaultTransport
+func NewGZipTransport(next http.RoundTripper, opts ...xopt.Option[gzipConfig]) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &gzipTransport{next: next, gzipConfig: newGZipConfig(opts...)}
+}
+
+// GZip 为当前请求开启透明压缩
+func GZip(opts ...xopt.Option[gzipConfig]) xopt.Option[Request] {
+	return func(request *Request) {
+		request.ownClient()
+		request.Transport = NewGZipTransport(request.Transport, opts...)
+	}
+}
+
+func (t *gzipTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if req.Header.Get(AcceptEncoding) == "" {
+		req.Header.Set(AcceptEncoding, acceptEncodings())
+	}
+	if t.compressRequest && req.Body != nil && req.Body != http.NoBody && req.Header.Get(ContentEncoding) == "" {
+		c, ok := codec.GetCompressor(t.algorithm)
+		if !ok || c.Encoding() == "" {
+			_ = req.Body.Close()
+			return nil, fmt.Errorf("%w: %s has no content-encoding", codec.ErrUnknownCompressor, t.algorithm)
+		}
+		body := req.Body
+		pr, pw := io.Pipe()
+		go func() {
+			err := compressTo(pw, body, c, t.level)
+			_ = body.Close()
+			_ = pw.CloseWithError(err)
+		}()
+		req.Body = pr
+		req.GetBody = nil
+		req.ContentLength = -1
+		req.Header.Del(ContentLength)
+		req.Header.Set(ContentEncoding, c.Encoding())
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	encoding := resp.Header.Get(ContentEncoding)
+	if !hasBody(req.Method, resp.StatusCode) || encoding == "" || strings.EqualFold(encoding, "identity") {
+		return resp, nil
+	}
+	gr, err := newDecodeBody(encoding, resp.Body, t.maxSize)
+	if err != nil {
+		_ = resp.Body.Close()
+		return nil, err
+	}
+	resp.Body = &gzipBody{ReadCloser: gr, raw: resp.Body}
+	resp.Header.Del(ContentEncoding)
+	resp.Header.Del(ContentLength)
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return resp, nil
+}
+
+// compressTo 将 src 压缩写入 dst
+func compressTo(dst io.Writer, src io.Reader, c codec.Compressor, level int) error {
+	w, err := c.NewWriter(dst, level)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, src); err != nil {
+		_ = w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// gzipBody 关闭时同时关闭解压器和原始响应体
+type gzipBody struct {
+	io.ReadCloser
+	raw io.Closer
+}
+
+func (b *gzipBody) Close() error {
+	_ = b.ReadCloser.Close()
+	return b.raw.Close()
+}
+
+func hasBody(method string, code int) bool {
+	return method != http.MethodHead &&
+		code != http.StatusNoContent &&
+		code != http.StatusNotModified &&
+		(code < 100 || code >= 200)
+}
+
+// GZipHandler 服务端中间件：解压请求体，并在客户端支持时压缩响应
+// 请求体支持所有已注册的 codec 压缩算法，响应优先使用 CompressAlgorithm 指定的算法
+// 请求体超过 GZipMaxSize 时，读取 r.Body 会返回 codec.ErrDecompressTooLarge
+func GZipHandler(next http.Handler, opts ...xopt.Option[gzipConfig]) http.Handler {
+	cfg := newGZipConfig(opts...)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if encoding := r.Header.Get(ContentEncoding); encoding != "" && !strings.EqualFold(encoding, "identity") {
+			gr, err := newDecodeBody(encoding, r.Body, cfg.maxSize)
+			if err != nil {
+				code := http.StatusBadRequest
+				if errors.Is(err, codec.ErrUnknownCompressor) {
+					code = http.StatusUnsupportedMediaType
+				}
+				http.Error(w, err.Error(), code)
+				return
+			}
+			defer func() { _ = gr.Close() }()
+			r.Body = gr
+			r.ContentLength = -1
+			r.Header.Del(ContentEncoding)
+			r.Header.Del(ContentLength)
+		}
+		c, ok := negotiateEncoding(r.Header.Get(AcceptEncoding), cfg.algorithm)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		gw := &gzipResponseWriter{ResponseWriter: w, method: r.Method, compressor: c, level: cfg.level}
+		defer func() { _ = gw.Close() }()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// negotiateEncoding 优先选择 preferred 算法，否则按注册顺序选择客户端接受的算法
+func negotiateEncoding(header, preferred string) (codec.Compressor, bool) {
+	if header == "" {
+		return nil, false
+	}
+	if c, ok := codec.GetCompressor(preferred); ok && c.Encoding() != "" && acceptsEncoding(header, c.Encoding()) {
+		return c, true
+	}
+	for _, c := range codec.Compressors() {
+		if c.Encoding() != "" && acceptsEncoding(header, c.Encoding()) {
+			return c, true
+		}
+	}
+	return nil, false
+}
+
+// acceptsEncoding 判断 Accept-Encoding 是否接受指定编码（q=0 表示拒绝）
+// 明确列出的编码优先于通配符 *
+func acceptsEncoding(header, encoding string) bool {
+	wildcard := false
+	for _, item := range strings.Split(header, ",") {
+		token, params, _ := strings.Cut(item, ";")
+		token = strings.TrimSpace(token)
+		switch {
+		case strings.EqualFold(token, encoding):
+			return qValue(params) > 0
+		case token == "*":
+			wildcard = qValue(params) > 0
+		}
+	}
+	return wildcard
+}
+
+// qValue 解析 q 参数，缺省为 1，格式错误视为 0
+func qValue(params string) float64 {
+	for _, param := range strings.Split(params, ";") {
+		name, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "q") {
+			continue
+		}
+		q, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0
+		}
+		return q
+	}
+	return 1
+}
+
+// gzipResponseWriter 在第一次写入时决定是否压缩响应
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	method      string
+	compressor  codec.Compressor
+	level       int
+	gw          io.WriteCloser
+	wroteHeader bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	header := w.Header()
+	if hasBody(w.method, code) && header.Get(ContentEncoding) == "" {
+		if gw, err := w.compressor.NewWriter(w.ResponseWriter, w.level); err == nil {
+			w.gw = gw
+			header.Set(ContentEncoding, w.compressor.Encoding())
+			header.Add("Vary", AcceptEncoding)
+			header.Del(ContentLength)
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get(ContentType) == "" {
+			w.Header().Set(ContentType, http.DetectContentType(p))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gw == nil {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.gw.Write(p)
+}
+
+func (w *gzipResponseWriter) Flush() {
+	if f, ok := w.gw.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *gzipResponseWriter) Close() error {
+	if w.gw == nil {
+		return nil
+	}
+	return w.gw.Close()
+}
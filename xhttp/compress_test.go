@@ -0,0 +1,157 @@
+package xhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/monaco-io/lib/codec"
+)
+
+func TestGZipRoundTrip(t *testing.T) {
+	payload := strings.Repeat("compress me ", 1000)
+
+	var gotEncoding string
+	server := httptest.NewServer(GZipHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotEncoding = r.Header.Get(ContentEncoding)
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set(ContentType, ContentTypeText)
+		_, _ = w.Write(body)
+	})))
+	defer server.Close()
+
+	resp, err := Do(context.Background(), server.URL,
+		Method(http.MethodPost),
+		BodyText(payload),
+		GZip(GZipRequestBody()),
+	)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if string(resp.Body) != payload {
+		t.Errorf("Do() body length = %d, want %d", len(resp.Body), len(payload))
+	}
+	if gotEncoding != "" {
+		t.Errorf("handler should strip Content-Encoding, got %q", gotEncoding)
+	}
+}
+
+func TestGZipHandlerNegotiation(t *testing.T) {
+	handler := GZipHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("a", 100)))
+	}))
+
+	tests := []struct {
+		name           string
+		acceptEncoding string
+		wantGZip       bool // 是否压缩
+	}{
+		{"no header", "", false},
+		{"gzip", "gzip", true},
+		{"gzip among others", "br, gzip;q=0.8", true},
+		{"gzip refused", "gzip;q=0", false},
+		{"wildcard", "*", true},
+		{"deflate only", "deflate", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.acceptEncoding != "" {
+				req.Header.Set(AcceptEncoding, tt.acceptEncoding)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			encoding := rec.Header().Get(ContentEncoding)
+			if (encoding != "") != tt.wantGZip {
+				t.Fatalf("Content-Encoding = %q, want compressed %v", encoding, tt.wantGZip)
+			}
+			if encoding == EncodingGZip && !codec.IsGzipData(rec.Body.Bytes()) {
+				t.Error("response body is not gzip data")
+			}
+			if encoding == "deflate" && !codec.IsZlibData(rec.Body.Bytes()) {
+				t.Error("response body is not zlib data")
+			}
+		})
+	}
+}
+
+func TestAcceptsEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"gzip", true},
+		{"*", true},
+		{"*;q=0", false},
+		// 明确的编码优先于通配符，与顺序无关
+		{"*, gzip;q=0", false},
+		{"gzip;q=0, *", false},
+		{"*;q=0, gzip", true},
+		{"br;level=1;q=0.5, gzip;foo=bar;q=0.1", true},
+		{"gzip;q=abc", false},
+	}
+	for _, tt := range tests {
+		if got := acceptsEncoding(tt.header, EncodingGZip); got != tt.want {
+			t.Errorf("acceptsEncoding(%q) = %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestGZipTransportMaxSize(t *testing.T) {
+	server := httptest.NewServer(GZipHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(make([]byte, 1<<20))
+	})))
+	defer server.Close()
+
+	_, err := Do(context.Background(), server.URL, GZip(GZipMaxSize(1024)))
+	if !errors.Is(err, codec.ErrDecompressTooLarge) {
+		t.Errorf("Do() error = %v, want ErrDecompressTooLarge", err)
+	}
+}
+
+func TestCompressAlgorithm(t *testing.T) {
+	var gotEncoding string
+	handler := GZipHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_, _ = w.Write(body)
+	}), CompressAlgorithm(codec.CompressZlib))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotEncoding = r.Header.Get(ContentEncoding)
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	resp, err := Do(context.Background(), server.URL,
+		Method(http.MethodPost),
+		BodyText("zlib body"),
+		GZip(GZipRequestBody(), CompressAlgorithm(codec.CompressZlib)),
+	)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if string(resp.Body) != "zlib body" {
+		t.Errorf("Do() body = %q", resp.Body)
+	}
+	if gotEncoding != "deflate" {
+		t.Errorf("request Content-Encoding = %q, want deflate", gotEncoding)
+	}
+
+	// lzw 没有 HTTP 编码标识，不能用于请求体
+	if _, err := Do(context.Background(), server.URL,
+		Method(http.MethodPost),
+		BodyText("x"),
+		GZip(GZipRequestBody(), CompressAlgorithm(codec.CompressLZW)),
+	); !errors.Is(err, codec.ErrUnknownCompressor) {
+		t.Errorf("Do() error = %v, want ErrUnknownCompressor", err)
+	}
+}
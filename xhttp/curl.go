@@ -0,0 +1,543 @@
+package xhttp
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+// ErrCurlSyntax 无法解析的 curl 命令
+var ErrCurlSyntax = errors.New("lib.xhttp:invalid curl command")
+
+// Curl 导出等价的 curl 命令，请求体会被缓存以便之后继续发送
+func (r *Request) Curl() (string, error) {
+	req := r.Request
+	args := []string{"curl"}
+	switch req.Method {
+	case "", http.MethodGet:
+	case http.MethodHead:
+		args = append(args, "-I")
+	default:
+		args = append(args, "-X", req.Method)
+	}
+	args = append(args, shellQuote(req.URL.String()))
+
+	username, password, basic := req.BasicAuth()
+	if req.Host != "" && req.Host != req.URL.Host {
+		args = append(args, "-H", shellQuote("Host: "+req.Host))
+	}
+	for _, key := range slices.Sorted(maps.Keys(req.Header)) {
+		if basic && key == "Authorization" {
+			continue
+		}
+		for _, value := range req.Header[key] {
+			args = append(args, "-H", shellQuote(key+": "+value))
+		}
+	}
+	if basic {
+		args = append(args, "-u", shellQuote(username+":"+password))
+	}
+
+	if err := rewindableBody(req); err != nil {
+		return "", err
+	}
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return "", err
+		}
+		body, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return "", err
+		}
+		if len(body) > 0 {
+			args = append(args, "--data-binary", shellQuote(string(body)))
+		}
+	}
+	return strings.Join(args, " "), nil
+}
+
+// shellQuote 使用单引号转义，包含控制字符或非 UTF-8 内容时使用 $'...'
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@,+%", r))
+	}) < 0 {
+		return s
+	}
+	if utf8.ValidString(s) && strings.IndexFunc(s, func(r rune) bool { return r < 0x20 || r == 0x7f }) < 0 {
+		return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+	}
+	var b strings.Builder
+	b.WriteString("$'")
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, `\x%02x`, s[i])
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\'' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\x%02x`, r)
+		default:
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
+
+type curlConfig struct {
+	allowFiles bool
+}
+
+// CurlAllowFiles 允许 -d @file、--data-urlencode name@file、-F name=@file 和 -F name=<file 读取本地文件
+// 默认拒绝，避免解析不可信的命令时读取或上传任意文件
+func CurlAllowFiles() xopt.Option[curlConfig] {
+	return func(cfg *curlConfig) {
+		cfg.allowFiles = true
+	}
+}
+
+// ParseCurl 将 curl 命令转换为请求选项，支持浏览器“复制为 cURL”的输出
+// 引用本地文件的参数需要 CurlAllowFiles，否则返回 ErrCurlSyntax
+//
+//	opts, err := xhttp.ParseCurl(`curl 'https://example.com' -H 'Accept: */*' --data-raw 'a=1'`)
+//	xhttp.Do(ctx, "", opts...)
+func ParseCurl(command string, opts ...xopt.Option[curlConfig]) ([]xopt.Option[Request], error) {
+	var cfg curlConfig
+	xopt.Apply(opts, &cfg)
+	args, err := splitShell(command)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > 0 && args[0] == "curl" {
+		args = args[1:]
+	}
+	var (
+		rawURL, method string
+		header         = http.Header{}
+		data           []string
+		parts          []FormPart
+		user           *string
+		get            bool
+		timeout        time.Duration
+	)
+	for i := 0; i < len(args); i++ {
+		flag, value, inline := args[i], "", false
+		// 短参数可以与值相连，如 -XPOST
+		if len(flag) > 2 && flag[0] == '-' && flag[1] != '-' && strings.ContainsRune("XHdbuAeFm", rune(flag[1])) {
+			flag, value, inline = flag[:2], flag[2:], true
+		}
+		next := func() (string, error) {
+			if inline {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("%w: %s requires a value", ErrCurlSyntax, flag)
+			}
+			i++
+			return args[i], nil
+		}
+		switch flag {
+		case "-X", "--request":
+			if method, err = next(); err != nil {
+				return nil, err
+			}
+		case "-H", "--header":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if key, val, ok := strings.Cut(v, ":"); ok {
+				if val = strings.TrimSpace(val); val != "" {
+					header.Add(strings.TrimSpace(key), val)
+				}
+			} else if key, ok := strings.CutSuffix(v, ";"); ok {
+				header.Add(strings.TrimSpace(key), "")
+			} else {
+				return nil, fmt.Errorf("%w: header %q", ErrCurlSyntax, v)
+			}
+		case "-d", "--data", "--data-ascii", "--data-binary", "--data-raw", "--data-urlencode":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if v, err = cfg.data(flag, v); err != nil {
+				return nil, err
+			}
+			data = append(data, v)
+		case "-F", "--form":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			part, err := cfg.formPart(v)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, part)
+		case "-u", "--user":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			user = &v
+		case "-b", "--cookie":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			if !strings.Contains(v, "=") {
+				return nil, fmt.Errorf("%w: cookie file %q is not supported", ErrCurlSyntax, v)
+			}
+			header.Add("Cookie", v)
+		case "-A", "--user-agent":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			header.Set("User-Agent", v)
+		case "-e", "--referer":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			header.Set("Referer", v)
+		case "-m", "--max-time":
+			v, err := next()
+			if err != nil {
+				return nil, err
+			}
+			seconds, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: max-time %q", ErrCurlSyntax, v)
+			}
+			timeout = time.Duration(seconds * float64(time.Second))
+		case "--url":
+			if rawURL, err = next(); err != nil {
+				return nil, err
+			}
+		case "-G", "--get":
+			get = true
+		case "-I", "--head":
+			method = http.MethodHead
+		case "--compressed", "-s", "--silent", "-S", "--show-error", "-L", "--location",
+			"-i", "--include", "-v", "--verbose", "-g", "--globoff", "-f", "--fail",
+			"--http1.1", "--http2":
+			// Transport 自动处理压缩和重定向，输出相关参数无需处理
+		default:
+			if strings.HasPrefix(flag, "-") {
+				return nil, fmt.Errorf("%w: unsupported option %s", ErrCurlSyntax, flag)
+			}
+			if rawURL != "" {
+				return nil, fmt.Errorf("%w: multiple urls", ErrCurlSyntax)
+			}
+			rawURL = flag
+		}
+	}
+	if rawURL == "" {
+		return nil, fmt.Errorf("%w: missing url", ErrCurlSyntax)
+	}
+	if len(data) > 0 && len(parts) > 0 {
+		return nil, fmt.Errorf("%w: --data and --form cannot be combined", ErrCurlSyntax)
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	body := strings.Join(data, "&")
+	if get && body != "" {
+		if strings.Contains(rawURL, "?") {
+			rawURL += "&" + body
+		} else {
+			rawURL += "?" + body
+		}
+		data = nil
+	}
+
+	reqOpts := []xopt.Option[Request]{URL(rawURL), Headers(header)}
+	switch {
+	case method != "":
+	case get:
+		method = http.MethodGet
+	case len(data) > 0 || len(parts) > 0:
+		method = http.MethodPost
+	default:
+		method = http.MethodGet
+	}
+	reqOpts = append(reqOpts, Method(method))
+	if user != nil {
+		username, password, _ := strings.Cut(*user, ":")
+		reqOpts = append(reqOpts, BasicAuth(username, password))
+	}
+	if len(data) > 0 {
+		reqOpts = append(reqOpts, curlBody(body))
+	}
+	if len(parts) > 0 {
+		reqOpts = append(reqOpts, BodyMultipart(parts))
+	}
+	if timeout > 0 {
+		reqOpts = append(reqOpts, Timeout(timeout))
+	}
+	return reqOpts, nil
+}
+
+// curlBody 未指定 Content-Type 时与 curl 一致使用表单编码
+func curlBody(body string) xopt.Option[Request] {
+	return func(request *Request) {
+		contentType := request.Header.Get(ContentType)
+		if contentType == "" {
+			contentType = ContentTypeURLEncoded
+		}
+		NativeBody(contentType, strings.NewReader(body))(request)
+	}
+}
+
+// readFile 读取命令中引用的本地文件，未开启 CurlAllowFiles 时拒绝
+func (cfg curlConfig) readFile(file string) ([]byte, error) {
+	if !cfg.allowFiles {
+		return nil, fmt.Errorf("%w: file reference %q requires CurlAllowFiles", ErrCurlSyntax, file)
+	}
+	return os.ReadFile(file)
+}
+
+// data 按 curl 的规则处理 -d 系列参数的值，@file 从文件读取
+func (cfg curlConfig) data(flag, value string) (string, error) {
+	switch flag {
+	case "--data-raw":
+		return value, nil
+	case "--data-urlencode":
+		name, content, hasName := strings.Cut(value, "=")
+		if !hasName {
+			name, content = "", value
+		}
+		if !hasName && strings.Contains(value, "@") {
+			var file string
+			name, file, _ = strings.Cut(value, "@")
+			data, err := cfg.readFile(file)
+			if err != nil {
+				return "", err
+			}
+			content = string(data)
+		}
+		if name == "" {
+			return url.QueryEscape(content), nil
+		}
+		return name + "=" + url.QueryEscape(content), nil
+	}
+	file, ok := strings.CutPrefix(value, "@")
+	if !ok {
+		return value, nil
+	}
+	data, err := cfg.readFile(file)
+	if err != nil {
+		return "", err
+	}
+	if flag == "--data-binary" {
+		return string(data), nil
+	}
+	return strings.NewReplacer("\r", "", "\n", "").Replace(string(data)), nil
+}
+
+// formPart 解析 -F 参数，支持 name=value、name=@file;type=...;filename=...、name=<file
+func (cfg curlConfig) formPart(value string) (FormPart, error) {
+	name, content, ok := strings.Cut(value, "=")
+	if !ok {
+		return FormPart{}, fmt.Errorf("%w: form %q", ErrCurlSyntax, value)
+	}
+	switch {
+	case strings.HasPrefix(content, "@"):
+		fields := strings.Split(content[1:], ";")
+		if !cfg.allowFiles {
+			return FormPart{}, fmt.Errorf("%w: file reference %q requires CurlAllowFiles", ErrCurlSyntax, fields[0])
+		}
+		part := FormFile(name, fields[0])
+		for _, field := range fields[1:] {
+			key, val, _ := strings.Cut(field, "=")
+			switch strings.TrimSpace(key) {
+			case "type":
+				part = part.WithContentType(val)
+			case "filename":
+				part.filename = val
+			}
+		}
+		return part, nil
+	case strings.HasPrefix(content, "<"):
+		data, err := cfg.readFile(content[1:])
+		if err != nil {
+			return FormPart{}, err
+		}
+		return FormField(name, string(data)), nil
+	}
+	return FormField(name, content), nil
+}
+
+// splitShell 按 POSIX shell 规则拆分参数，支持单引号、双引号、$'...' 和续行
+func splitShell(command string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inArg   bool
+	)
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		case c == '\\':
+			if i+1 >= len(command) {
+				return nil, fmt.Errorf("%w: trailing backslash", ErrCurlSyntax)
+			}
+			i++
+			if command[i] == '\n' {
+				continue
+			}
+			if command[i] == '\r' && i+1 < len(command) && command[i+1] == '\n' {
+				i++
+				continue
+			}
+			current.WriteByte(command[i])
+			inArg = true
+		case c == '\'':
+			end := strings.IndexByte(command[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unterminated quote", ErrCurlSyntax)
+			}
+			current.WriteString(command[i+1 : i+1+end])
+			i += end + 1
+			inArg = true
+		case c == '"':
+			i++
+			for ; i < len(command) && command[i] != '"'; i++ {
+				if command[i] == '\\' && i+1 < len(command) && strings.IndexByte("$`\"\\\n", command[i+1]) >= 0 {
+					i++
+					if command[i] == '\n' {
+						continue
+					}
+				}
+				current.WriteByte(command[i])
+			}
+			if i >= len(command) {
+				return nil, fmt.Errorf("%w: unterminated quote", ErrCurlSyntax)
+			}
+			inArg = true
+		case c == '$' && i+1 < len(command) && command[i+1] == '\'':
+			n, err := ansiCQuote(&current, command[i+2:])
+			if err != nil {
+				return nil, err
+			}
+			i += n + 2
+			inArg = true
+		default:
+			current.WriteByte(c)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// ansiCQuote 解码 $'...' 的内容写入 b，返回包含结束引号在内消耗的字节数
+func ansiCQuote(b *strings.Builder, s string) (int, error) {
+	var buf bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '\'' {
+			b.Write(buf.Bytes())
+			return i + 1, nil
+		}
+		if c != '\\' || i+1 >= len(s) {
+			buf.WriteByte(c)
+			continue
+		}
+		i++
+		switch c = s[i]; c {
+		case 'n':
+			buf.WriteByte('\n')
+		case 'r':
+			buf.WriteByte('\r')
+		case 't':
+			buf.WriteByte('\t')
+		case 'a':
+			buf.WriteByte('\a')
+		case 'b':
+			buf.WriteByte('\b')
+		case 'f':
+			buf.WriteByte('\f')
+		case 'v':
+			buf.WriteByte('\v')
+		case 'e', 'E':
+			buf.WriteByte(0x1b)
+		case 'x', 'u', 'U':
+			width := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+			j := i + 1
+			for j < len(s) && j-i-1 < width && isHex(s[j]) {
+				j++
+			}
+			if j == i+1 {
+				buf.WriteByte('\\')
+				buf.WriteByte(c)
+				continue
+			}
+			n, err := strconv.ParseUint(s[i+1:j], 16, 32)
+			if err != nil {
+				return 0, fmt.Errorf("%w: escape %q", ErrCurlSyntax, s[i-1:j])
+			}
+			if c == 'x' {
+				buf.WriteByte(byte(n))
+			} else {
+				buf.WriteRune(rune(n))
+			}
+			i = j - 1
+		case '0', '1', '2', '3', '4', '5', '6', '7':
+			j := i
+			for j < len(s) && j-i < 3 && s[j] >= '0' && s[j] <= '7' {
+				j++
+			}
+			// 与 bash 一致，超过 \377 时取低 8 位
+			n, err := strconv.ParseUint(s[i:j], 8, 16)
+			if err != nil {
+				return 0, fmt.Errorf("%w: escape %q", ErrCurlSyntax, s[i-1:j])
+			}
+			buf.WriteByte(byte(n))
+			i = j - 1
+		default:
+			// \\ \' \" \? 以及未知转义
+			if !strings.ContainsRune(`\'"?`, rune(c)) {
+				buf.WriteByte('\\')
+			}
+			buf.WriteByte(c)
+		}
+	}
+	return 0, fmt.Errorf("%w: unterminated quote", ErrCurlSyntax)
+}
+
+func isHex(c byte) bool {
+	return c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
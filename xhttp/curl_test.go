@@ -0,0 +1,198 @@
+package xhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/monaco-io/lib/typing/xjson"
+)
+
+type echoResult struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header"`
+	Body   string      `json:"body"`
+}
+
+func newEchoServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Header.Del("Accept-Encoding")
+		r.Header.Del("Content-Length")
+		if strings.HasPrefix(r.UserAgent(), "Go-http-client") {
+			r.Header.Del("User-Agent")
+		}
+		w.Header().Set(ContentType, ContentTypeJSON)
+		_, _ = w.Write(xjson.MarshalX(echoResult{Method: r.Method, URL: r.URL.String(), Header: r.Header, Body: string(body)}))
+	}))
+}
+
+func TestCurlRoundTrip(t *testing.T) {
+	server := newEchoServer()
+	defer server.Close()
+	ctx := context.Background()
+
+	request, err := NewRequest(ctx, server.URL+"/b?q=1",
+		Method(http.MethodPost),
+		Header("X-Quote", `it's "quoted"`),
+		Header("X-Multi", "1"),
+		Header("X-Multi", "2"),
+		BasicAuth("user", "p@ss:word"),
+		BodyText("line1\nline2 'q' \x00\xff"),
+	)
+	if err != nil {
+		t.Fatalf("NewRequest() error = %v", err)
+	}
+	command, err := request.Curl()
+	if err != nil {
+		t.Fatalf("Curl() error = %v", err)
+	}
+	if !strings.HasPrefix(command, "curl -X POST ") || !strings.Contains(command, "-u user:p@ss:word") {
+		t.Errorf("Curl() = %s", command)
+	}
+
+	// 导出后请求体仍可发送
+	want, err := Sugar[echoResult](ctx, "", URL(request.URL.String()),
+		Method(http.MethodPost),
+		Header("X-Quote", `it's "quoted"`),
+		Header("X-Multi", "1"),
+		Header("X-Multi", "2"),
+		BasicAuth("user", "p@ss:word"),
+		BodyText("line1\nline2 'q' \x00\xff"),
+	)
+	if err != nil {
+		t.Fatalf("Sugar() error = %v", err)
+	}
+	opts, err := ParseCurl(command)
+	if err != nil {
+		t.Fatalf("ParseCurl(%s) error = %v", command, err)
+	}
+	got, err := Sugar[echoResult](ctx, "", opts...)
+	if err != nil {
+		t.Fatalf("Sugar() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Body, want.Body) {
+		t.Errorf("replayed request = %+v, want %+v", got.Body, want.Body)
+	}
+
+	// 已发送的可重放请求同样可以导出
+	resp, err := Do(ctx, server.URL, Method(http.MethodPut), BodyText("sent"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if command, _ := resp.Curl(); !strings.HasSuffix(command, "--data-binary sent") {
+		t.Errorf("Curl() after send = %s", command)
+	}
+}
+
+func TestParseCurl(t *testing.T) {
+	server := newEchoServer()
+	defer server.Close()
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "data.txt")
+	if err := os.WriteFile(file, []byte("a=1\nb=2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		command string
+		want    echoResult
+	}{
+		{
+			name: "chrome",
+			command: `curl '` + server.URL + `/api?x=1' \
+  -H 'accept: application/json' \
+  -H 'content-type: application/json' \
+  -b 'sid=abc; theme=dark' \
+  --data-raw $'{"name":"it\'s","emoji":"é"}' \
+  --compressed`,
+			want: echoResult{Method: http.MethodPost, URL: "/api?x=1", Body: `{"name":"it's","emoji":"é"}`, Header: http.Header{
+				"Accept":       {"application/json"},
+				"Content-Type": {"application/json"},
+				"Cookie":       {"sid=abc; theme=dark"},
+			}},
+		},
+		{
+			name:    "form data",
+			command: `curl -XPUT "` + server.URL + `/f" -d 'a=b' --data-urlencode 'c=d e' -A "agent \"x\""`,
+			want: echoResult{Method: http.MethodPut, URL: "/f", Body: "a=b&c=d+e", Header: http.Header{
+				"Content-Type": {ContentTypeURLEncoded},
+				"User-Agent":   {`agent "x"`},
+			}},
+		},
+		{
+			name:    "get with data",
+			command: `curl -G ` + server.URL + `/g -d q=1 --data @` + file,
+			want:    echoResult{Method: http.MethodGet, URL: "/g?q=1&a=1b=2", Header: http.Header{}},
+		},
+		{
+			name:    "basic auth",
+			command: `curl --url ` + server.URL + ` -u admin:secret -H 'Empty;'`,
+			want: echoResult{Method: http.MethodGet, URL: "/", Header: http.Header{
+				"Authorization": {"Basic YWRtaW46c2VjcmV0"},
+				"Empty":         {""},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := ParseCurl(tt.command, CurlAllowFiles())
+			if err != nil {
+				t.Fatalf("ParseCurl() error = %v", err)
+			}
+			resp, err := Sugar[echoResult](ctx, "", opts...)
+			if err != nil {
+				t.Fatalf("Sugar() error = %v", err)
+			}
+			if !reflect.DeepEqual(resp.Body, tt.want) {
+				t.Errorf("request = %+v, want %+v", resp.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCurlErrors(t *testing.T) {
+	for _, command := range []string{
+		`curl`,
+		`curl 'http://a`,
+		`curl http://a -H`,
+		`curl http://a --proxy http://b`,
+		`curl http://a http://b`,
+		`curl http://a -d x -F y=z`,
+		`curl http://a -b cookies.txt`,
+		// 默认不读取本地文件
+		`curl http://a -d @/etc/passwd`,
+		`curl http://a --data-urlencode name@/etc/passwd`,
+		`curl http://a -F f=@/etc/passwd`,
+		`curl http://a -F f=</etc/passwd`,
+	} {
+		if _, err := ParseCurl(command); !errors.Is(err, ErrCurlSyntax) {
+			t.Errorf("ParseCurl(%s) error = %v, want ErrCurlSyntax", command, err)
+		}
+	}
+}
+
+func TestAnsiCQuote(t *testing.T) {
+	for command, want := range map[string]string{
+		`$'a\x41\101\n'`: "aAA\n",
+		`$'\777'`:        "\xff",
+		`$'\400'`:        "\x00",
+		`$'\u00e9'`:      "é",
+	} {
+		args, err := splitShell(command)
+		if err != nil || len(args) != 1 || args[0] != want {
+			t.Errorf("splitShell(%s) = %q, %v, want %q", command, args, err, want)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package xhttp
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/monaco-io/lib/codec"
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+const (
+	Range        = "Range"
+	ContentRange = "Content-Range"
+)
+
+var (
+	// ErrUnexpectedStatus 响应状态码不符合预期
+	ErrUnexpectedStatus = errors.New("lib.xhttp:unexpected status code")
+	// ErrChecksumMismatch 下载内容校验失败
+	ErrChecksumMismatch = errors.New("lib.xhttp:checksum mismatch")
+)
+
+// partSuffix 下载中的临时文件后缀，完成后重命名为目标文件
+const partSuffix = ".part"
+
+type downloadConfig struct {
+	algorithm codec.HashAlgorithm
+	checksum  string
+	progress  func(written, total int64)
+	noResume  bool
+	options   []xopt.Option[Request]
+}
+
+// DownloadChecksum 下载完成后使用 codec 哈希算法校验，sum 为十六进制摘要
+// 校验失败时删除临时文件并返回 ErrChecksumMismatch
+func DownloadChecksum(alg codec.HashAlgorithm, sum string) xopt.Option[downloadConfig] {
+	return func(cfg *downloadConfig) {
+		cfg.algorithm = alg
+		cfg.checksum = sum
+	}
+}
+
+// DownloadProgress 进度回调，written 包含续传前已有的字节数，total 未知时为 -1
+func DownloadProgress(f func(written, total int64)) xopt.Option[downloadConfig] {
+	return func(cfg *downloadConfig) {
+		cfg.progress = f
+	}
+}
+
+// DownloadNoResume 忽略已有的临时文件，重新下载
+func DownloadNoResume() xopt.Option[downloadConfig] {
+	return func(cfg *downloadConfig) {
+		cfg.noResume = true
+	}
+}
+
+// DownloadOptions 下载请求使用的请求选项
+func DownloadOptions(opts ...xopt.Option[Request]) xopt.Option[downloadConfig] {
+	return func(cfg *downloadConfig) {
+		cfg.options = append(cfg.options, opts...)
+	}
+}
+
+// Download 使用 DefaultClient 下载文件
+func Download(ctx context.Context, url, path string, opts ...xopt.Option[downloadConfig]) (*Response[int64], error) {
+	return DefaultClient.Download(ctx, url, path, opts...)
+}
+
+// Download 下载 url 到 path，返回文件大小
+// 数据先写入 path.part，中断后再次调用时通过 Range 请求续传，完成后重命名为 path
+func (c *XClient) Download(ctx context.Context, url, path string, opts ...xopt.Option[downloadConfig]) (*Response[int64], error) {
+	var cfg downloadConfig
+	xopt.Apply(opts, &cfg)
+
+	var h hash.Hash
+	if cfg.algorithm != "" {
+		var err error
+		if h, err = codec.NewHash(cfg.algorithm); err != nil {
+			return nil, err
+		}
+	}
+
+	part := path + partSuffix
+	var offset int64
+	if !cfg.noResume {
+		if info, err := os.Stat(part); err == nil {
+			offset = info.Size()
+		}
+	}
+	reqOpts := cfg.options
+	if offset > 0 {
+		reqOpts = append(slices.Clip(reqOpts), Header(Range, fmt.Sprintf("bytes=%d-", offset), true))
+	}
+	response, err := c.DoStream(ctx, url, reqOpts...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	total := int64(-1)
+	if length, err := strconv.ParseInt(response.RespHeader.Get(ContentLength), 10, 64); err == nil {
+		total = length
+	}
+	switch {
+	case offset > 0 && response.Code == http.StatusPartialContent:
+		start, size, ok := parseContentRange(response.RespHeader.Get(ContentRange))
+		if !ok || start != offset {
+			return nil, fmt.Errorf("%w: %d content-range %q for offset %d", ErrUnexpectedStatus, response.Code, response.RespHeader.Get(ContentRange), offset)
+		}
+		total = size
+	case offset > 0 && response.Code == http.StatusRequestedRangeNotSatisfiable:
+		// 临时文件已失效，重新下载
+		_ = response.Body.Close()
+		return c.Download(ctx, url, path, append(slices.Clip(opts), DownloadNoResume())...)
+	case response.Code >= 200 && response.Code < 300:
+		// 服务端不支持 Range 时返回完整内容
+		offset = 0
+	default:
+		return nil, fmt.Errorf("%w: %d", ErrUnexpectedStatus, response.Code)
+	}
+
+	if h != nil && offset > 0 {
+		if err := hashFile(h, part); err != nil {
+			return nil, err
+		}
+	}
+	flag := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if offset > 0 {
+		flag = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	}
+	file, err := os.OpenFile(part, flag, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	writers := []io.Writer{file}
+	if h != nil {
+		writers = append(writers, h)
+	}
+	if cfg.progress != nil {
+		writers = append(writers, &progressWriter{written: offset, total: total, f: cfg.progress})
+	}
+	n, err := io.Copy(io.MultiWriter(writers...), response.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if h != nil {
+		if sum := hex.EncodeToString(h.Sum(nil)); !strings.EqualFold(sum, cfg.checksum) {
+			_ = os.Remove(part)
+			return nil, fmt.Errorf("%w: %s got %s, want %s", ErrChecksumMismatch, cfg.algorithm, sum, cfg.checksum)
+		}
+	}
+	if err := os.Rename(part, path); err != nil {
+		return nil, err
+	}
+	return &Response[int64]{
+		Body:        offset + n,
+		Code:        response.Code,
+		RespHeader:  response.RespHeader,
+		Request:     response.Request,
+		TraceResult: response.TraceResult,
+	}, nil
+}
+
+// parseContentRange 解析 "bytes start-end/size"，size 未知时为 -1
+func parseContentRange(value string) (start, size int64, ok bool) {
+	value, found := strings.CutPrefix(value, "bytes ")
+	if !found {
+		return 0, 0, false
+	}
+	span, total, found := strings.Cut(value, "/")
+	if !found {
+		return 0, 0, false
+	}
+	first, _, found := strings.Cut(span, "-")
+	if !found {
+		return 0, 0, false
+	}
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if total == "*" {
+		return start, -1, true
+	}
+	size, err = strconv.ParseInt(total, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	return start, size, true
+}
+
+func hashFile(h hash.Hash, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = file.Close() }()
+	_, err = io.Copy(h, file)
+	return err
+}
+
+type progressWriter struct {
+	written, total int64
+	f              func(written, total int64)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.written += int64(len(p))
+	w.f(w.written, w.total)
+	return len(p), nil
+}
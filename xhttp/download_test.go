@@ -0,0 +1,201 @@
+package xhttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/monaco-io/lib/codec"
+)
+
+func newFileServer(content []byte, ranges *[]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/file" {
+			http.NotFound(w, r)
+			return
+		}
+		*ranges = append(*ranges, r.Header.Get(Range))
+		http.ServeContent(w, r, "file", time.Time{}, bytes.NewReader(content))
+	}))
+}
+
+func TestDownload(t *testing.T) {
+	content := []byte(strings.Repeat("0123456789", 10000))
+	sum := codec.SHA256(content)
+	var ranges []string
+	server := newFileServer(content, &ranges)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "file")
+	var lastWritten, lastTotal int64
+	resp, err := Download(context.Background(), server.URL+"/file", path,
+		DownloadChecksum(codec.HashSHA256, sum),
+		DownloadProgress(func(written, total int64) { lastWritten, lastTotal = written, total }),
+	)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if resp.Body != int64(len(content)) || lastWritten != int64(len(content)) || lastTotal != int64(len(content)) {
+		t.Errorf("Download() size=%d progress=%d/%d, want %d", resp.Body, lastWritten, lastTotal, len(content))
+	}
+	got, _ := os.ReadFile(path)
+	if !bytes.Equal(got, content) {
+		t.Error("downloaded content mismatch")
+	}
+	if _, err := os.Stat(path + partSuffix); !os.IsNotExist(err) {
+		t.Error("part file should be renamed")
+	}
+}
+
+func TestDownloadResume(t *testing.T) {
+	content := []byte(strings.Repeat("abcdefghij", 1000))
+	var ranges []string
+	server := newFileServer(content, &ranges)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(path+partSuffix, content[:4000], 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var firstWritten int64 = -1
+	resp, err := Download(context.Background(), server.URL+"/file", path,
+		DownloadChecksum(codec.HashSHA256, codec.SHA256(content)),
+		DownloadProgress(func(written, total int64) {
+			if firstWritten < 0 {
+				firstWritten = written
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if len(ranges) != 1 || ranges[0] != "bytes=4000-" {
+		t.Errorf("Range = %v, want [bytes=4000-]", ranges)
+	}
+	if resp.Code != http.StatusPartialContent || resp.Body != int64(len(content)) {
+		t.Errorf("Download() code=%d size=%d", resp.Code, resp.Body)
+	}
+	if firstWritten <= 4000 {
+		t.Errorf("progress should include resumed bytes, got %d", firstWritten)
+	}
+	got, _ := os.ReadFile(path)
+	if !bytes.Equal(got, content) {
+		t.Error("resumed content mismatch")
+	}
+
+	// 临时文件比远端更大时重新下载
+	ranges = nil
+	if err := os.WriteFile(path+partSuffix, bytes.Repeat([]byte("x"), len(content)+10), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Download(context.Background(), server.URL+"/file", path); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if len(ranges) != 2 || ranges[1] != "" {
+		t.Errorf("Range = %v, want a full request after 416", ranges)
+	}
+	got, _ = os.ReadFile(path)
+	if !bytes.Equal(got, content) {
+		t.Error("restarted content mismatch")
+	}
+}
+
+func TestDownloadErrors(t *testing.T) {
+	var ranges []string
+	server := newFileServer([]byte("hello"), &ranges)
+	defer server.Close()
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "bad")
+	_, err := Download(context.Background(), server.URL+"/file", path, DownloadChecksum(codec.HashMD5, "00"))
+	if !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("Download() error = %v, want ErrChecksumMismatch", err)
+	}
+	if _, err := os.Stat(path + partSuffix); !os.IsNotExist(err) {
+		t.Error("part file should be removed after checksum mismatch")
+	}
+
+	_, err = Download(context.Background(), server.URL+"/missing", filepath.Join(dir, "missing"))
+	if !errors.Is(err, ErrUnexpectedStatus) {
+		t.Errorf("Download() error = %v, want ErrUnexpectedStatus", err)
+	}
+
+	tests := []struct {
+		value       string
+		start, size int64
+		ok          bool
+	}{
+		{"bytes 10-19/100", 10, 100, true},
+		{"bytes 10-19/*", 10, -1, true},
+		{"bytes */100", 0, 0, false},
+		{"items 0-1/2", 0, 0, false},
+	}
+	for _, tt := range tests {
+		start, size, ok := parseContentRange(tt.value)
+		if start != tt.start || size != tt.size || ok != tt.ok {
+			t.Errorf("parseContentRange(%q) = %d, %d, %v", tt.value, start, size, ok)
+		}
+	}
+}
+
+func TestDoStreamAndMaxBodySize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("chunked") != "" {
+			w.(http.Flusher).Flush()
+		}
+		_, _ = w.Write([]byte(strings.Repeat("a", 1024)))
+	}))
+	defer server.Close()
+
+	stream, err := DoStream(context.Background(), server.URL)
+	if err != nil {
+		t.Fatalf("DoStream() error = %v", err)
+	}
+	body, err := io.ReadAll(stream.Body)
+	_ = stream.Body.Close()
+	if err != nil || len(body) != 1024 || stream.TraceResult == nil {
+		t.Errorf("DoStream() body=%d err=%v trace=%v", len(body), err, stream.TraceResult)
+	}
+
+	for _, url := range []string{server.URL, server.URL + "?chunked=1"} {
+		if _, err := Do(context.Background(), url, MaxBodySize(100)); !errors.Is(err, ErrBodyTooLarge) {
+			t.Errorf("Do(%s) error = %v, want ErrBodyTooLarge", url, err)
+		}
+	}
+	resp, err := Do(context.Background(), server.URL, MaxBodySize(1024), Header("X-Trace", "req"))
+	if err != nil || len(resp.Body) != 1024 {
+		t.Fatalf("Do() body=%d err=%v", len(resp.Body), err)
+	}
+	// RespHeader 为响应头，Header 为请求头
+	if resp.RespHeader.Get(ContentLength) != "1024" || resp.Header.Get("X-Trace") != "req" || resp.RespHeader.Get("X-Trace") != "" {
+		t.Errorf("RespHeader = %v, Header = %v", resp.RespHeader, resp.Header)
+	}
+}
+
+func TestDoTotalDuration(t *testing.T) {
+	const delay = 300 * time.Millisecond
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		time.Sleep(delay)
+		_, _ = w.Write([]byte("slow body"))
+	}))
+	defer server.Close()
+
+	// TotalDuration 只统计到响应头，不包含读取响应体
+	resp, err := Do(context.Background(), server.URL)
+	if err != nil || string(resp.Body) != "slow body" {
+		t.Fatalf("Do() = %v, %v", resp, err)
+	}
+	if d := resp.TraceResult.TotalDuration.Value; d <= 0 || d >= delay {
+		t.Errorf("TotalDuration = %s, want time to response headers", d)
+	}
+}
@@ -0,0 +1,296 @@
+package xhttp
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+const ContentTypeOctetStream = "application/octet-stream"
+
+// ErrPartReplay 读取器来源的分段只能发送一次
+var ErrPartReplay = errors.New("lib.xhttp:multipart reader part cannot be replayed")
+
+// FormPart multipart 表单中的一个分段
+type FormPart struct {
+	name        string
+	filename    string
+	contentType string
+	// size 返回分段内容大小，-1 表示未知
+	size       func() (int64, error)
+	open       func() (io.ReadCloser, error)
+	replayable bool
+}
+
+// FormField 普通表单字段
+func FormField(name, value string) FormPart {
+	return FormPart{
+		name:       name,
+		size:       func() (int64, error) { return int64(len(value)), nil },
+		open:       func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader(value)), nil },
+		replayable: true,
+	}
+}
+
+// FormFile 从本地路径读取的文件，发送时才打开文件
+func FormFile(name, path string) FormPart {
+	return FormPart{
+		name:        name,
+		filename:    filepath.Base(path),
+		contentType: ContentTypeOctetStream,
+		size: func() (int64, error) {
+			info, err := os.Stat(path)
+			if err != nil {
+				return 0, err
+			}
+			return info.Size(), nil
+		},
+		open:       func() (io.ReadCloser, error) { return os.Open(path) },
+		replayable: true,
+	}
+}
+
+// FormReader 从 r 读取的文件，size 未知时传 -1
+// r 只能读取一次，因此请求不能被重定向或重试重放，除非开启 Retry 缓存请求体
+func FormReader(name, filename string, r io.Reader, size int64) FormPart {
+	var once sync.Once
+	return FormPart{
+		name:        name,
+		filename:    filename,
+		contentType: ContentTypeOctetStream,
+		size:        func() (int64, error) { return size, nil },
+		open: func() (rc io.ReadCloser, err error) {
+			err = ErrPartReplay
+			once.Do(func() {
+				rc, err = io.NopCloser(r), nil
+			})
+			return rc, err
+		},
+	}
+}
+
+// FormFileHeader 服务端收到的上传文件，可用于转发
+func FormFileHeader(name string, fh *multipart.FileHeader) FormPart {
+	contentType := fh.Header.Get(ContentType)
+	if contentType == "" {
+		contentType = ContentTypeOctetStream
+	}
+	return FormPart{
+		name:        name,
+		filename:    fh.Filename,
+		contentType: contentType,
+		size:        func() (int64, error) { return fh.Size, nil },
+		open: func() (io.ReadCloser, error) {
+			return fh.Open()
+		},
+		replayable: true,
+	}
+}
+
+// WithContentType 设置文件分段的 Content-Type，默认为 application/octet-stream
+func (p FormPart) WithContentType(contentType string) FormPart {
+	p.contentType = contentType
+	return p
+}
+
+func (p FormPart) header() textproto.MIMEHeader {
+	h := make(textproto.MIMEHeader)
+	if p.filename == "" {
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"`, quoteEscaper.Replace(p.name)))
+		return h
+	}
+	h.Set("Content-Disposition", multipart.FileContentDisposition(p.name, p.filename))
+	h.Set(ContentType, p.contentType)
+	return h
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+type multipartConfig struct {
+	boundary string
+	progress func(written, total int64)
+}
+
+// MultipartBoundary 指定分隔符，默认随机生成
+func MultipartBoundary(boundary string) xopt.Option[multipartConfig] {
+	return func(cfg *multipartConfig) {
+		cfg.boundary = boundary
+	}
+}
+
+// MultipartProgress 上传进度回调，total 未知时为 -1
+func MultipartProgress(f func(written, total int64)) xopt.Option[multipartConfig] {
+	return func(cfg *multipartConfig) {
+		cfg.progress = f
+	}
+}
+
+// BodyMultipart 流式发送 multipart 表单，不在内存中缓存文件
+// 所有分段大小已知时设置 Content-Length，打开或读取分段失败时请求返回该错误
+func BodyMultipart(parts []FormPart, opts ...xopt.Option[multipartConfig]) xopt.Option[Request] {
+	return func(request *Request) {
+		cfg := multipartConfig{boundary: multipart.NewWriter(nil).Boundary()}
+		xopt.Apply(opts, &cfg)
+		w := multipart.NewWriter(nil)
+		if err := w.SetBoundary(cfg.boundary); err != nil {
+			request.err = err
+			return
+		}
+		sizes, total, err := multipartLength(parts, cfg.boundary)
+		if err != nil {
+			request.err = err
+			return
+		}
+		newBody := func() io.ReadCloser {
+			return &multipartBody{parts: parts, sizes: sizes, boundary: cfg.boundary, total: total, progress: cfg.progress}
+		}
+		request.Header.Set(ContentType, w.FormDataContentType())
+		request.Body = newBody()
+		request.ContentLength = total
+		request.GetBody = nil
+		if !slices.ContainsFunc(parts, func(p FormPart) bool { return !p.replayable }) {
+			request.GetBody = func() (io.ReadCloser, error) { return newBody(), nil }
+		}
+	}
+}
+
+// multipartLength 计算各分段大小和请求体长度，存在未知大小的分段时长度为 -1
+func multipartLength(parts []FormPart, boundary string) ([]int64, int64, error) {
+	var (
+		counter countWriter
+		total   int64
+	)
+	w := multipart.NewWriter(&counter)
+	_ = w.SetBoundary(boundary)
+	sizes := make([]int64, len(parts))
+	for i, p := range parts {
+		size, err := p.size()
+		if err != nil {
+			return nil, 0, fmt.Errorf("multipart part %q: %w", p.name, err)
+		}
+		sizes[i] = size
+		if size < 0 || total < 0 {
+			total = -1
+			continue
+		}
+		total += size
+		if _, err := w.CreatePart(p.header()); err != nil {
+			return nil, 0, err
+		}
+	}
+	if total < 0 {
+		return sizes, -1, nil
+	}
+	if err := w.Close(); err != nil {
+		return nil, 0, err
+	}
+	return sizes, total + counter.n, nil
+}
+
+// multipartBody 首次读取时才启动写入协程，未发送的请求不会泄漏协程
+type multipartBody struct {
+	parts    []FormPart
+	sizes    []int64
+	boundary string
+	total    int64
+	progress func(written, total int64)
+
+	once    sync.Once
+	pr      *io.PipeReader
+	written int64
+}
+
+func (b *multipartBody) start() {
+	b.once.Do(func() {
+		pr, pw := io.Pipe()
+		b.pr = pr
+		go func() {
+			_ = pw.CloseWithError(writeMultipart(pw, b.parts, b.sizes, b.boundary))
+		}()
+	})
+}
+
+func (b *multipartBody) Read(p []byte) (int, error) {
+	b.start()
+	n, err := b.pr.Read(p)
+	if n > 0 && b.progress != nil {
+		b.written += int64(n)
+		b.progress(b.written, b.total)
+	}
+	return n, err
+}
+
+func (b *multipartBody) Close() error {
+	// 未读取就关闭时不启动写入协程
+	b.once.Do(func() {
+		b.pr, _ = io.Pipe()
+	})
+	return b.pr.Close()
+}
+
+func writeMultipart(dst io.Writer, parts []FormPart, sizes []int64, boundary string) error {
+	w := multipart.NewWriter(dst)
+	if err := w.SetBoundary(boundary); err != nil {
+		return err
+	}
+	for i, p := range parts {
+		if err := writePart(w, p, sizes[i]); err != nil {
+			return fmt.Errorf("multipart part %q: %w", p.name, err)
+		}
+	}
+	return w.Close()
+}
+
+// writePart 写入一个分段，实际大小与 Content-Length 计算时不一致时返回错误
+func writePart(w *multipart.Writer, p FormPart, size int64) error {
+	rc, err := p.open()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+	pw, err := w.CreatePart(p.header())
+	if err != nil {
+		return err
+	}
+	n, err := io.Copy(pw, rc)
+	if err != nil {
+		return err
+	}
+	if size >= 0 && n != size {
+		return fmt.Errorf("size changed from %d to %d", size, n)
+	}
+	return nil
+}
+
+type countWriter struct{ n int64 }
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// formParts 将 multipart.Form 按字段名排序转换为分段
+func formParts(form *multipart.Form) []FormPart {
+	var parts []FormPart
+	for _, key := range slices.Sorted(maps.Keys(form.Value)) {
+		for _, value := range form.Value[key] {
+			parts = append(parts, FormField(key, value))
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(form.File)) {
+		for _, fh := range form.File[key] {
+			parts = append(parts, FormFileHeader(key, fh))
+		}
+	}
+	return parts
+}
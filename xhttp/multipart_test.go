@@ -0,0 +1,179 @@
+package xhttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/iotest"
+
+	"github.com/monaco-io/lib/typing/xjson"
+)
+
+type uploadResult struct {
+	ContentLength int64             `json:"content_length"`
+	Fields        map[string]string `json:"fields"`
+	Files         map[string]string `json:"files"`
+	FileTypes     map[string]string `json:"file_types"`
+}
+
+func newUploadServer(t *testing.T, failFirst bool) *httptest.Server {
+	var calls atomic.Int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if failFirst && calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		result := uploadResult{
+			ContentLength: r.ContentLength,
+			Fields:        map[string]string{},
+			Files:         map[string]string{},
+			FileTypes:     map[string]string{},
+		}
+		for key, values := range r.MultipartForm.Value {
+			result.Fields[key] = strings.Join(values, ",")
+		}
+		for key, files := range r.MultipartForm.File {
+			f, err := files[0].Open()
+			if err != nil {
+				t.Errorf("open %s: %v", key, err)
+				continue
+			}
+			data, _ := io.ReadAll(f)
+			_ = f.Close()
+			result.Files[key] = files[0].Filename + ":" + string(data)
+			result.FileTypes[key] = files[0].Header.Get(ContentType)
+		}
+		w.Header().Set(ContentType, ContentTypeJSON)
+		_, _ = w.Write(xjson.MarshalX(result))
+	}))
+}
+
+func TestBodyMultipart(t *testing.T) {
+	server := newUploadServer(t, false)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("file content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var written, total int64
+	resp, err := Sugar[uploadResult](context.Background(), server.URL,
+		Method(http.MethodPost),
+		BodyMultipart([]FormPart{
+			FormField("name", `x"y`),
+			FormFile("file", path).WithContentType(ContentTypeText),
+			FormReader("reader", "r.bin", strings.NewReader("reader content"), 14),
+		}, MultipartProgress(func(w, t int64) { written, total = w, t })),
+	)
+	if err != nil {
+		t.Fatalf("Sugar() error = %v", err)
+	}
+	got := resp.Body
+	if got.Fields["name"] != `x"y` || got.Files["file"] != "a.txt:file content" || got.Files["reader"] != "r.bin:reader content" {
+		t.Errorf("upload = %+v", got)
+	}
+	if got.FileTypes["file"] != ContentTypeText || got.FileTypes["reader"] != ContentTypeOctetStream {
+		t.Errorf("file types = %v", got.FileTypes)
+	}
+	if got.ContentLength <= 0 || written != got.ContentLength || total != got.ContentLength {
+		t.Errorf("content length = %d, progress = %d/%d", got.ContentLength, written, total)
+	}
+
+	// 大小未知时使用分块传输
+	resp, err = Sugar[uploadResult](context.Background(), server.URL,
+		Method(http.MethodPost),
+		BodyMultipart([]FormPart{FormReader("reader", "r.bin", strings.NewReader("stream"), -1)},
+			MultipartProgress(func(w, t int64) { total = t })),
+	)
+	if err != nil {
+		t.Fatalf("Sugar() error = %v", err)
+	}
+	if resp.Body.ContentLength != -1 || total != -1 || resp.Body.Files["reader"] != "r.bin:stream" {
+		t.Errorf("chunked upload = %+v, total = %d", resp.Body, total)
+	}
+}
+
+func TestBodyMultipartErrors(t *testing.T) {
+	server := newUploadServer(t, false)
+	defer server.Close()
+
+	_, err := Do(context.Background(), server.URL, Method(http.MethodPost),
+		BodyMultipart([]FormPart{FormFile("file", filepath.Join(t.TempDir(), "missing"))}))
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Do() error = %v, want os.ErrNotExist", err)
+	}
+
+	readErr := errors.New("disk failure")
+	_, err = Do(context.Background(), server.URL, Method(http.MethodPost),
+		BodyMultipart([]FormPart{FormReader("file", "f", iotest.ErrReader(readErr), -1)}))
+	if !errors.Is(err, readErr) {
+		t.Errorf("Do() error = %v, want %v", err, readErr)
+	}
+
+	_, err = Do(context.Background(), server.URL, Method(http.MethodPost),
+		BodyMultipart([]FormPart{FormReader("file", "f", strings.NewReader("short"), 10)}))
+	if err == nil {
+		t.Error("Do() expected error for size mismatch")
+	}
+}
+
+func TestBodyMultipartReplay(t *testing.T) {
+	server := newUploadServer(t, true)
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("retry me"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := Sugar[uploadResult](context.Background(), server.URL,
+		Method(http.MethodPut),
+		BodyMultipart([]FormPart{FormFile("file", path)}),
+		Retry(RetryBackoff(0, 0)),
+	)
+	if err != nil {
+		t.Fatalf("Sugar() error = %v", err)
+	}
+	if resp.Attempts != 2 || resp.Body.Files["file"] != "a.txt:retry me" {
+		t.Errorf("attempts = %d, upload = %+v", resp.Attempts, resp.Body)
+	}
+}
+
+func TestBodyMultipartForm(t *testing.T) {
+	server := newUploadServer(t, false)
+	defer server.Close()
+
+	// 构造服务端收到的表单，再原样转发
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+	_ = w.WriteField("b", "2")
+	_ = w.WriteField("a", "1")
+	fw, _ := w.CreateFormFile("file", "f.txt")
+	_, _ = fw.Write([]byte("forwarded"))
+	_ = w.Close()
+	form, err := multipart.NewReader(&buf, w.Boundary()).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = form.RemoveAll() }()
+
+	resp, err := Sugar[uploadResult](context.Background(), server.URL, Method(http.MethodPost), BodyMultipartForm(form))
+	if err != nil {
+		t.Fatalf("Sugar() error = %v", err)
+	}
+	if resp.Body.Fields["a"] != "1" || resp.Body.Fields["b"] != "2" || resp.Body.Files["file"] != "f.txt:forwarded" {
+		t.Errorf("forwarded = %+v", resp.Body)
+	}
+}
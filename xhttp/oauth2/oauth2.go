@@ -0,0 +1,395 @@
+// Package oauth2 获取并缓存 OAuth2 访问令牌，支持 client_credentials 和 refresh_token 授权
+//
+//	ts := oauth2.ClientCredentials(tokenURL, id, secret, oauth2.WithScopes("read"))
+//	xhttp.Do(ctx, url, oauth2.Auth(ts))
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/monaco-io/lib/cache/rs"
+	"github.com/monaco-io/lib/typing/xjson"
+	"github.com/monaco-io/lib/typing/xopt"
+	"github.com/monaco-io/lib/xhttp"
+)
+
+// ErrRetrieve 从令牌端点获取令牌失败
+var ErrRetrieve = errors.New("lib.xhttp.oauth2:retrieve token failed")
+
+// DefaultExpiryDelta 令牌过期前提前刷新的时间
+const DefaultExpiryDelta = time.Minute
+
+// Token OAuth2 访问令牌
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Scope        string    `json:"scope,omitempty"`
+	Expiry       time.Time `json:"expiry,omitzero"`
+}
+
+// Type 令牌类型，默认为 Bearer
+func (t *Token) Type() string {
+	switch {
+	case t.TokenType == "", strings.EqualFold(t.TokenType, "bearer"):
+		return "Bearer"
+	}
+	return t.TokenType
+}
+
+// valid 令牌存在且在 delta 之后仍未过期，Expiry 为零值表示不过期
+func (t *Token) valid(now time.Time, delta time.Duration) bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || now.Add(delta).Before(t.Expiry))
+}
+
+// TokenSource 提供访问令牌
+type TokenSource interface {
+	Token(ctx context.Context) (*Token, error)
+}
+
+// Invalidator 可以废弃令牌的 TokenSource，服务端返回 401 时调用
+type Invalidator interface {
+	Invalidate(token *Token)
+}
+
+// ErrorResponse RFC 6749 5.2 定义的错误响应
+type ErrorResponse struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+	URI         string `json:"error_uri,omitempty"`
+}
+
+// AuthStyle 客户端凭证的发送方式
+type AuthStyle int
+
+const (
+	// AuthStyleHeader 使用 HTTP Basic 认证发送 client_id 和 client_secret
+	AuthStyleHeader AuthStyle = iota
+	// AuthStyleParams 在请求体中发送 client_id 和 client_secret
+	AuthStyleParams
+)
+
+type config struct {
+	scopes      []string
+	params      url.Values
+	authStyle   AuthStyle
+	expiryDelta time.Duration
+	token       *Token
+	client      *xhttp.XClient
+	reqOpts     []xopt.Option[xhttp.Request]
+	cache       rs.ICache
+	cacheKey    string
+	now         func() time.Time
+}
+
+// WithScopes 申请的权限范围
+func WithScopes(scopes ...string) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.scopes = scopes
+	}
+}
+
+// WithEndpointParams 令牌请求的额外参数，如 audience、resource
+func WithEndpointParams(params url.Values) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.params = params
+	}
+}
+
+// WithAuthStyle 客户端凭证的发送方式，默认为 AuthStyleHeader
+func WithAuthStyle(style AuthStyle) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.authStyle = style
+	}
+}
+
+// WithExpiryDelta 令牌过期前多久提前刷新，默认为 DefaultExpiryDelta
+func WithExpiryDelta(d time.Duration) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.expiryDelta = d
+	}
+}
+
+// WithToken 初始令牌，如授权码流程获得的令牌
+func WithToken(token *Token) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.token = token
+	}
+}
+
+// WithClient 请求令牌端点使用的客户端，默认为 xhttp.DefaultClient
+func WithClient(client *xhttp.XClient) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.client = client
+	}
+}
+
+// WithRequestOptions 请求令牌端点时附加的选项，如 Timeout、Retry
+func WithRequestOptions(opts ...xopt.Option[xhttp.Request]) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.reqOpts = append(cfg.reqOpts, opts...)
+	}
+}
+
+// WithCache 通过 redis 在多个实例间共享令牌，key 应包含 client_id 和 scope 以免混用
+func WithCache(conn rs.ICache, key string) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.cache = conn
+		cfg.cacheKey = key
+	}
+}
+
+// WithClock 自定义时钟，主要用于测试
+func WithClock(now func() time.Time) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.now = now
+	}
+}
+
+// Source 缓存令牌的 TokenSource，过期前提前刷新，并发刷新只请求一次令牌端点
+type Source struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	grantType    string
+	cfg          config
+
+	group singleflight.Group
+
+	mu    sync.Mutex
+	token *Token
+	// invalid 被服务端拒绝的令牌，共享缓存中的同一令牌不再使用
+	invalid string
+}
+
+var (
+	_ TokenSource = (*Source)(nil)
+	_ Invalidator = (*Source)(nil)
+)
+
+// ClientCredentials 使用 client_credentials 授权获取令牌
+func ClientCredentials(tokenURL, clientID, clientSecret string, opts ...xopt.Option[config]) *Source {
+	return newSource(tokenURL, clientID, clientSecret, "client_credentials", opts...)
+}
+
+// RefreshToken 使用 refresh_token 授权获取令牌，服务端返回新的 refresh_token 时自动替换
+func RefreshToken(tokenURL, clientID, clientSecret, refreshToken string, opts ...xopt.Option[config]) *Source {
+	s := newSource(tokenURL, clientID, clientSecret, "refresh_token", opts...)
+	if s.token == nil {
+		s.token = &Token{}
+	}
+	if s.token.RefreshToken == "" {
+		s.token.RefreshToken = refreshToken
+	}
+	return s
+}
+
+func newSource(tokenURL, clientID, clientSecret, grantType string, opts ...xopt.Option[config]) *Source {
+	cfg := config{
+		expiryDelta: DefaultExpiryDelta,
+		client:      xhttp.DefaultClient,
+		now:         time.Now,
+	}
+	xopt.Apply(opts, &cfg)
+	s := &Source{
+		tokenURL:     tokenURL,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		grantType:    grantType,
+		cfg:          cfg,
+	}
+	if cfg.token != nil {
+		token := *cfg.token
+		s.token = &token
+	}
+	return s
+}
+
+// Token 返回有效的令牌，即将过期时刷新
+// 刷新使用独立于 ctx 的上下文，调用方取消不影响其他等待同一次刷新的调用方
+func (s *Source) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	token := s.token
+	s.mu.Unlock()
+	if token.valid(s.cfg.now(), s.cfg.expiryDelta) {
+		return token, nil
+	}
+	ch := s.group.DoChan("token", func() (any, error) {
+		return s.refresh(context.WithoutCancel(ctx))
+	})
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.(*Token), nil
+	}
+}
+
+// Invalidate 废弃令牌，下次调用 Token 时重新获取
+func (s *Source) Invalidate(token *Token) {
+	if token == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invalid = token.AccessToken
+	if s.token != nil && s.token.AccessToken == token.AccessToken {
+		s.token = &Token{RefreshToken: s.token.RefreshToken}
+	}
+}
+
+func (s *Source) refresh(ctx context.Context) (*Token, error) {
+	now := s.cfg.now()
+	s.mu.Lock()
+	current, invalid := s.token, s.invalid
+	s.mu.Unlock()
+	if current.valid(now, s.cfg.expiryDelta) {
+		return current, nil
+	}
+
+	if shared := s.loadShared(ctx); shared != nil {
+		if shared.AccessToken != invalid && shared.valid(now, s.cfg.expiryDelta) {
+			s.store(shared)
+			return shared, nil
+		}
+		// 其他实例轮换过 refresh_token 时使用最新的
+		if shared.RefreshToken != "" {
+			rotated := Token{}
+			if current != nil {
+				rotated = *current
+			}
+			rotated.RefreshToken = shared.RefreshToken
+			current = &rotated
+		}
+	}
+
+	token, err := s.retrieve(ctx, current)
+	if err != nil {
+		// 提前刷新失败时，未过期的令牌仍可继续使用
+		if current.valid(now, 0) && current.AccessToken != invalid {
+			return current, nil
+		}
+		return nil, err
+	}
+	s.store(token)
+	s.saveShared(ctx, token)
+	return token, nil
+}
+
+func (s *Source) store(token *Token) {
+	s.mu.Lock()
+	s.token = token
+	s.mu.Unlock()
+}
+
+// retrieve 请求令牌端点
+func (s *Source) retrieve(ctx context.Context, current *Token) (*Token, error) {
+	form := url.Values{"grant_type": {s.grantType}}
+	if s.grantType == "refresh_token" {
+		if current == nil || current.RefreshToken == "" {
+			return nil, fmt.Errorf("%w: missing refresh token", ErrRetrieve)
+		}
+		form.Set("refresh_token", current.RefreshToken)
+	}
+	if len(s.cfg.scopes) > 0 {
+		form.Set("scope", strings.Join(s.cfg.scopes, " "))
+	}
+	for key, values := range s.cfg.params {
+		form[key] = values
+	}
+	opts := []xopt.Option[xhttp.Request]{
+		xhttp.Method(http.MethodPost),
+		xhttp.Header("Accept", xhttp.ContentTypeJSON, true),
+		xhttp.DecoderJSON(),
+	}
+	if s.cfg.authStyle == AuthStyleParams {
+		form.Set("client_id", s.clientID)
+		if s.clientSecret != "" {
+			form.Set("client_secret", s.clientSecret)
+		}
+	} else {
+		// RFC 6749 2.3.1 要求凭证先进行表单编码
+		opts = append(opts, xhttp.BasicAuth(url.QueryEscape(s.clientID), url.QueryEscape(s.clientSecret)))
+	}
+	opts = append(opts, xhttp.BodyWWWFormURLEncoded(form))
+	opts = append(opts, s.cfg.reqOpts...)
+
+	resp, err := xhttp.ClientSugarE[tokenResponse, ErrorResponse](s.cfg.client, ctx, s.tokenURL, opts...)
+	if err != nil {
+		if se, ok := xhttp.AsStatusError[ErrorResponse](err); ok && se.Body.Code != "" {
+			return nil, fmt.Errorf("%w: %s %s: %w", ErrRetrieve, se.Body.Code, se.Body.Description, err)
+		}
+		return nil, fmt.Errorf("%w: %w", ErrRetrieve, err)
+	}
+	body := resp.Body
+	if body.AccessToken == "" {
+		return nil, fmt.Errorf("%w: server response missing access_token", ErrRetrieve)
+	}
+	token := &Token{
+		AccessToken:  body.AccessToken,
+		TokenType:    body.TokenType,
+		RefreshToken: body.RefreshToken,
+		Scope:        body.Scope,
+	}
+	if body.ExpiresIn > 0 {
+		token.Expiry = s.cfg.now().Add(time.Duration(body.ExpiresIn) * time.Second)
+	}
+	if token.RefreshToken == "" && current != nil {
+		token.RefreshToken = current.RefreshToken
+	}
+	return token, nil
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	Scope        string `json:"scope"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// loadShared 读取共享缓存，失败时忽略并直接请求令牌端点
+func (s *Source) loadShared(ctx context.Context) *Token {
+	if s.cfg.cache == nil {
+		return nil
+	}
+	data, err := s.cfg.cache.Get(ctx, s.cfg.cacheKey).Result()
+	if err != nil || data == "" {
+		return nil
+	}
+	var token Token
+	if err := xjson.UnmarshalString(data, &token); err != nil {
+		return nil
+	}
+	return &token
+}
+
+// saveShared 写入共享缓存，带 refresh_token 的令牌不过期以便其他实例继续刷新
+func (s *Source) saveShared(ctx context.Context, token *Token) {
+	if s.cfg.cache == nil {
+		return
+	}
+	var ttl time.Duration
+	if !token.Expiry.IsZero() && token.RefreshToken == "" {
+		if ttl = token.Expiry.Sub(s.cfg.now()) - s.cfg.expiryDelta; ttl <= 0 {
+			return
+		}
+	}
+	data, err := xjson.MarshalString(token)
+	if err != nil {
+		return
+	}
+	_ = s.cfg.cache.Set(ctx, s.cfg.cacheKey, data, ttl).Err()
+}
@@ -0,0 +1,217 @@
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/monaco-io/lib/cache/rs"
+	"github.com/monaco-io/lib/xhttp"
+)
+
+type tokenServer struct {
+	*httptest.Server
+	calls atomic.Int32
+	// forms 每次请求的表单，按顺序记录
+	mu    sync.Mutex
+	forms []string
+}
+
+// newTokenServer 每次返回新的 access_token，refresh_token 授权同时轮换 refresh_token，refresh_token 为 bad 时返回错误
+func newTokenServer(t *testing.T) *tokenServer {
+	ts := &tokenServer{}
+	ts.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("ParseForm() error = %v", err)
+		}
+		n := ts.calls.Add(1)
+		id, secret, basic := r.BasicAuth()
+		if !basic {
+			id, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+		}
+		ts.mu.Lock()
+		ts.forms = append(ts.forms, r.PostForm.Encode())
+		ts.mu.Unlock()
+		w.Header().Set(xhttp.ContentType, xhttp.ContentTypeJSON)
+		if id != "id" || secret != "secret" || r.PostForm.Get("refresh_token") == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error":"invalid_grant","error_description":"bad credentials"}`))
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+		if r.PostForm.Get("grant_type") == "refresh_token" {
+			_, _ = fmt.Fprintf(w, `{"access_token":"at%d","token_type":"bearer","refresh_token":"rt%d","expires_in":3600}`, n, n)
+			return
+		}
+		_, _ = fmt.Fprintf(w, `{"access_token":"at%d","token_type":"bearer","expires_in":3600}`, n)
+	}))
+	return ts
+}
+
+func TestClientCredentials(t *testing.T) {
+	server := newTokenServer(t)
+	defer server.Close()
+	now := time.Unix(1700000000, 0)
+	var mu sync.Mutex
+	clock := WithClock(func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	})
+	ts := ClientCredentials(server.URL, "id", "secret", WithScopes("read", "write"), clock)
+
+	// 并发获取只请求一次令牌端点
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			token, err := ts.Token(context.Background())
+			if err != nil || token.AccessToken != "at1" {
+				t.Errorf("Token() = %v, %v", token, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if got := server.calls.Load(); got != 1 {
+		t.Errorf("token endpoint calls = %d, want 1", got)
+	}
+	if form := server.forms[0]; form != "grant_type=client_credentials&scope=read+write" {
+		t.Errorf("form = %s", form)
+	}
+
+	// 过期前 DefaultExpiryDelta 内提前刷新
+	mu.Lock()
+	now = now.Add(time.Hour - DefaultExpiryDelta - time.Second)
+	mu.Unlock()
+	if token, _ := ts.Token(context.Background()); token.AccessToken != "at1" {
+		t.Errorf("Token() = %s, want cached at1", token.AccessToken)
+	}
+	mu.Lock()
+	now = now.Add(2 * time.Second)
+	mu.Unlock()
+	if token, _ := ts.Token(context.Background()); token.AccessToken != "at2" {
+		t.Errorf("Token() = %s, want refreshed at2", token.AccessToken)
+	}
+
+	_, err := ClientCredentials(server.URL, "id", "wrong", WithAuthStyle(AuthStyleParams)).Token(context.Background())
+	if !errors.Is(err, ErrRetrieve) || !errors.Is(err, xhttp.ErrUnexpectedStatus) || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Errorf("Token() error = %v", err)
+	}
+}
+
+func TestRefreshToken(t *testing.T) {
+	server := newTokenServer(t)
+	defer server.Close()
+
+	ts := RefreshToken(server.URL, "id", "secret", "rt0")
+	token, err := ts.Token(context.Background())
+	if err != nil || token.AccessToken != "at1" || token.RefreshToken != "rt1" {
+		t.Fatalf("Token() = %+v, %v", token, err)
+	}
+	ts.Invalidate(token)
+	if token, _ = ts.Token(context.Background()); token.AccessToken != "at2" {
+		t.Errorf("Token() after Invalidate = %s, want at2", token.AccessToken)
+	}
+	// 使用轮换后的 refresh_token
+	if server.forms[1] != "grant_type=refresh_token&refresh_token=rt1" {
+		t.Errorf("form = %s", server.forms[1])
+	}
+
+	if _, err := RefreshToken(server.URL, "id", "secret", "bad").Token(context.Background()); !errors.Is(err, ErrRetrieve) {
+		t.Errorf("Token() error = %v, want ErrRetrieve", err)
+	}
+}
+
+// fakeCache 只实现 Get、Set 的 rs.ICache
+type fakeCache struct {
+	rs.ICache
+	mu   sync.Mutex
+	data map[string]string
+	ttl  map[string]time.Duration
+}
+
+func (f *fakeCache) Get(_ context.Context, key string) *redis.StringCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if v, ok := f.data[key]; ok {
+		return redis.NewStringResult(v, nil)
+	}
+	return redis.NewStringResult("", redis.Nil)
+}
+
+func (f *fakeCache) Set(_ context.Context, key string, value any, ex time.Duration) *redis.StatusCmd {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.data[key] = value.(string)
+	f.ttl[key] = ex
+	return redis.NewStatusResult("OK", nil)
+}
+
+func TestSharedCache(t *testing.T) {
+	server := newTokenServer(t)
+	defer server.Close()
+	cache := &fakeCache{data: map[string]string{}, ttl: map[string]time.Duration{}}
+
+	a := ClientCredentials(server.URL, "id", "secret", WithCache(cache, "oauth2:id"))
+	b := ClientCredentials(server.URL, "id", "secret", WithCache(cache, "oauth2:id"))
+	ta, _ := a.Token(context.Background())
+	tb, _ := b.Token(context.Background())
+	if ta.AccessToken != "at1" || tb.AccessToken != "at1" || server.calls.Load() != 1 {
+		t.Errorf("tokens = %s, %s, calls = %d", ta.AccessToken, tb.AccessToken, server.calls.Load())
+	}
+	if ttl := cache.ttl["oauth2:id"]; ttl <= 0 || ttl > time.Hour-DefaultExpiryDelta {
+		t.Errorf("cache ttl = %s", ttl)
+	}
+
+	// 被拒绝的令牌不再从共享缓存读取
+	b.Invalidate(tb)
+	if tb, _ = b.Token(context.Background()); tb.AccessToken != "at2" {
+		t.Errorf("Token() after Invalidate = %s, want at2", tb.AccessToken)
+	}
+	a.Invalidate(ta)
+	if ta, _ = a.Token(context.Background()); ta.AccessToken != "at2" || server.calls.Load() != 2 {
+		t.Errorf("Token() = %s, calls = %d, want shared at2", ta.AccessToken, server.calls.Load())
+	}
+}
+
+func TestAuth(t *testing.T) {
+	server := newTokenServer(t)
+	defer server.Close()
+	// 第一个令牌被服务端拒绝
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := make([]byte, 16)
+		n, _ := r.Body.Read(body)
+		if r.Header.Get("Authorization") != "Bearer at2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = fmt.Fprintf(w, "ok %s", body[:n])
+	}))
+	defer api.Close()
+
+	ts := ClientCredentials(server.URL, "id", "secret")
+	resp, err := xhttp.Do(context.Background(), api.URL, Auth(ts), xhttp.Method(http.MethodPost), xhttp.BodyText("payload"))
+	if err != nil || resp.Code != http.StatusOK || string(resp.Body) != "ok payload" {
+		t.Fatalf("Do() = %v, %v", resp, err)
+	}
+	if resp.Request.Header.Get("Authorization") != "" {
+		t.Error("original request should not be modified")
+	}
+
+	// 重试一次后仍为 401 时返回响应
+	ts.Invalidate(&Token{AccessToken: "at2"})
+	resp, err = xhttp.Do(context.Background(), api.URL, Auth(ts))
+	if err != nil || resp.Code != http.StatusUnauthorized || server.calls.Load() != 4 {
+		t.Errorf("Do() = %v, %v, calls = %d", resp.Code, err, server.calls.Load())
+	}
+}
@@ -0,0 +1,79 @@
+package oauth2
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/monaco-io/lib/typing/xopt"
+	"github.com/monaco-io/lib/xhttp"
+)
+
+// Transport 为请求添加 Authorization 头的 RoundTripper
+// 响应 401 时废弃令牌并使用新令牌重试一次，请求体不可重放时不重试
+type Transport struct {
+	Source TokenSource
+	// Base 为空时使用 http.DefaultTransport
+	Base http.RoundTripper
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.Source.Token(req.Context())
+	if err != nil {
+		closeBody(req)
+		return nil, err
+	}
+	resp, err := t.base().RoundTrip(authorize(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	invalidator, ok := t.Source.(Invalidator)
+	if !ok || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		return resp, nil
+	}
+	invalidator.Invalidate(token)
+	retry, err := t.Source.Token(req.Context())
+	if err != nil || retry.AccessToken == token.AccessToken {
+		return resp, nil
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	next := authorize(req, retry)
+	if req.GetBody != nil {
+		if next.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+	return t.base().RoundTrip(next)
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+// authorize 复制请求并设置 Authorization，RoundTripper 不能修改原请求
+func authorize(req *http.Request, token *Token) *http.Request {
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", token.Type()+" "+token.AccessToken)
+	return r
+}
+
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		_ = req.Body.Close()
+	}
+}
+
+// Auth 为当前请求添加 OAuth2 令牌，ts 应在多个请求间共享
+// 包装当前的 Transport，需放在 xhttp.Transport 等选项之后
+// 用于 XClient 时可以通过 xhttp.ClientOptions(oauth2.Auth(ts)) 设置
+func Auth(ts TokenSource) xopt.Option[xhttp.Request] {
+	return func(request *xhttp.Request) {
+		xhttp.Transport(&Transport{Source: ts, Base: request.Transport})(request)
+	}
+}
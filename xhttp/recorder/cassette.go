@@ -0,0 +1,294 @@
+package recorder
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/monaco-io/lib/typing/xjson"
+	"github.com/monaco-io/lib/typing/xyaml"
+)
+
+// Cassette 录制的请求响应集合
+type Cassette struct {
+	Interactions []*Interaction `yaml:"interactions"`
+}
+
+// Interaction 一次请求和对应的响应
+type Interaction struct {
+	RecordedAt time.Time     `yaml:"recorded_at"`
+	Duration   time.Duration `yaml:"duration"`
+	Request    Request       `yaml:"request"`
+	Response   Response      `yaml:"response"`
+}
+
+// Request 录制的请求
+type Request struct {
+	Method string      `yaml:"method"`
+	URL    string      `yaml:"url"`
+	Header http.Header `yaml:"header,omitempty"`
+	Body   string      `yaml:"body,omitempty"`
+}
+
+// Response 录制的响应
+type Response struct {
+	StatusCode int         `yaml:"status_code"`
+	Header     http.Header `yaml:"header,omitempty"`
+	Body       string      `yaml:"body,omitempty"`
+}
+
+// isHAR 按扩展名判断磁带格式，.har 为 HAR 1.2，其余为 YAML
+func isHAR(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), ".har")
+}
+
+// LoadCassette 读取磁带文件
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if isHAR(path) {
+		var h har
+		if err := xjson.Unmarshal(data, &h); err != nil {
+			return nil, err
+		}
+		return h.cassette()
+	}
+	var c Cassette
+	if err := xyaml.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Save 写入磁带文件，自动创建目录
+func (c *Cassette) Save(path string) error {
+	var (
+		data []byte
+		err  error
+	)
+	if isHAR(path) {
+		data, err = xjson.MarshalIndent(newHAR(c), xjson.WithIndentString("  "))
+	} else {
+		data, err = xyaml.Marshal(c)
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// har HAR 1.2 中用到的字段
+type har struct {
+	Log harLog `json:"log"`
+}
+
+type harLog struct {
+	Version string     `json:"version"`
+	Creator harCreator `json:"creator"`
+	Entries []harEntry `json:"entries"`
+}
+
+type harCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type harEntry struct {
+	StartedDateTime time.Time   `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         harRequest  `json:"request"`
+	Response        harResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         harTimings  `json:"timings"`
+}
+
+type harRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	QueryString []harNameValue `json:"queryString"`
+	Cookies     []harNameValue `json:"cookies"`
+	PostData    *harPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Headers     []harNameValue `json:"headers"`
+	Cookies     []harNameValue `json:"cookies"`
+	Content     harContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// harPostData HAR 不支持二进制请求体，使用自定义字段 _encoding 标记 base64
+type harPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+	Encoding string `json:"_encoding,omitempty"`
+}
+
+type harContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type harTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+func newHAR(c *Cassette) *har {
+	h := &har{Log: harLog{
+		Version: "1.2",
+		Creator: harCreator{Name: "github.com/monaco-io/lib/xhttp/recorder", Version: "1"},
+		Entries: make([]harEntry, 0, len(c.Interactions)),
+	}}
+	for _, i := range c.Interactions {
+		ms := float64(i.Duration) / float64(time.Millisecond)
+		entry := harEntry{
+			StartedDateTime: i.RecordedAt,
+			Time:            ms,
+			Request: harRequest{
+				Method:      i.Request.Method,
+				URL:         i.Request.URL,
+				HTTPVersion: "HTTP/1.1",
+				Headers:     harHeaders(i.Request.Header),
+				QueryString: harQuery(i.Request.URL),
+				Cookies:     []harNameValue{},
+				HeadersSize: -1,
+				BodySize:    len(i.Request.Body),
+			},
+			Response: harResponse{
+				Status:      i.Response.StatusCode,
+				StatusText:  http.StatusText(i.Response.StatusCode),
+				HTTPVersion: "HTTP/1.1",
+				Headers:     harHeaders(i.Response.Header),
+				Cookies:     []harNameValue{},
+				Content: harContent{
+					Size:     len(i.Response.Body),
+					MimeType: i.Response.Header.Get("Content-Type"),
+				},
+				RedirectURL: i.Response.Header.Get("Location"),
+				HeadersSize: -1,
+				BodySize:    len(i.Response.Body),
+			},
+			Timings: harTimings{Wait: ms},
+		}
+		if i.Request.Body != "" {
+			text, encoding := harText(i.Request.Body)
+			entry.Request.PostData = &harPostData{MimeType: i.Request.Header.Get("Content-Type"), Text: text, Encoding: encoding}
+		}
+		entry.Response.Content.Text, entry.Response.Content.Encoding = harText(i.Response.Body)
+		h.Log.Entries = append(h.Log.Entries, entry)
+	}
+	return h
+}
+
+func (h *har) cassette() (*Cassette, error) {
+	c := &Cassette{Interactions: make([]*Interaction, 0, len(h.Log.Entries))}
+	for _, e := range h.Log.Entries {
+		i := &Interaction{
+			RecordedAt: e.StartedDateTime,
+			Duration:   time.Duration(e.Time * float64(time.Millisecond)),
+			Request: Request{
+				Method: e.Request.Method,
+				URL:    e.Request.URL,
+				Header: httpHeader(e.Request.Headers),
+			},
+			Response: Response{
+				StatusCode: e.Response.Status,
+				Header:     httpHeader(e.Response.Headers),
+			},
+		}
+		if e.Request.PostData != nil {
+			body, err := fromHARText(e.Request.PostData.Text, e.Request.PostData.Encoding)
+			if err != nil {
+				return nil, err
+			}
+			i.Request.Body = body
+		}
+		body, err := fromHARText(e.Response.Content.Text, e.Response.Content.Encoding)
+		if err != nil {
+			return nil, err
+		}
+		i.Response.Body = body
+		c.Interactions = append(c.Interactions, i)
+	}
+	return c, nil
+}
+
+func harHeaders(h http.Header) []harNameValue {
+	headers := []harNameValue{}
+	for _, name := range sortedKeys(h) {
+		for _, value := range h[name] {
+			headers = append(headers, harNameValue{Name: name, Value: value})
+		}
+	}
+	return headers
+}
+
+func harQuery(rawURL string) []harNameValue {
+	query := []harNameValue{}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return query
+	}
+	values := u.Query()
+	for _, name := range sortedKeys(values) {
+		for _, value := range values[name] {
+			query = append(query, harNameValue{Name: name, Value: value})
+		}
+	}
+	return query
+}
+
+func httpHeader(headers []harNameValue) http.Header {
+	if len(headers) == 0 {
+		return nil
+	}
+	h := make(http.Header, len(headers))
+	for _, nv := range headers {
+		h.Add(nv.Name, nv.Value)
+	}
+	return h
+}
+
+// harText 非 UTF-8 内容使用 base64 编码
+func harText(body string) (text, encoding string) {
+	if utf8.ValidString(body) {
+		return body, ""
+	}
+	return base64.StdEncoding.EncodeToString([]byte(body)), "base64"
+}
+
+func fromHARText(text, encoding string) (string, error) {
+	if encoding != "base64" {
+		return text, nil
+	}
+	data, err := base64.StdEncoding.DecodeString(text)
+	return string(data), err
+}
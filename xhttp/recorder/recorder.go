@@ -0,0 +1,369 @@
+// Package recorder 录制真实 HTTP 交互到磁带文件，并在测试中离线回放
+//
+//	rec, err := recorder.New("testdata/baidu.yaml", recorder.WithRedactQuery("ak"))
+//	defer rec.Stop()
+//	xhttp.Do(ctx, url, xhttp.Transport(rec))
+package recorder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+// ErrNoInteraction 回放时没有匹配的录制记录
+var ErrNoInteraction = errors.New("lib.xhttp.recorder:no matching interaction")
+
+// Redacted 脱敏后的占位值
+const Redacted = "REDACTED"
+
+// Mode 录制模式
+type Mode int
+
+const (
+	// ModeAuto 磁带文件存在时回放，否则录制
+	ModeAuto Mode = iota
+	// ModeReplay 只回放，没有匹配记录时返回 ErrNoInteraction
+	ModeReplay
+	// ModeRecord 总是请求真实服务并覆盖磁带
+	ModeRecord
+)
+
+// DefaultRedactHeaders 默认脱敏的请求头、响应头
+var DefaultRedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// DefaultRedactForm 默认脱敏的 application/x-www-form-urlencoded 请求体字段
+var DefaultRedactForm = []string{"client_secret", "password", "refresh_token"}
+
+// Matcher 判断请求是否匹配录制的请求，body 为当前请求体
+type Matcher func(r *http.Request, body []byte, recorded Request) bool
+
+// MatchMethod 匹配请求方法
+func MatchMethod(r *http.Request, _ []byte, recorded Request) bool {
+	return r.Method == recorded.Method
+}
+
+// MatchURL 匹配完整 URL，查询参数顺序无关
+func MatchURL(r *http.Request, _ []byte, recorded Request) bool {
+	u, err := url.Parse(recorded.URL)
+	if err != nil {
+		return false
+	}
+	return r.URL.Scheme == u.Scheme && r.URL.Host == u.Host && r.URL.Path == u.Path &&
+		r.URL.Query().Encode() == u.Query().Encode()
+}
+
+// MatchBody 匹配请求体
+func MatchBody(_ *http.Request, body []byte, recorded Request) bool {
+	return string(body) == recorded.Body
+}
+
+type config struct {
+	mode          Mode
+	matchers      []Matcher
+	redactHeaders []string
+	redactQuery   []string
+	redactForm    []string
+	redactBody    func(req *http.Request, body []byte) []byte
+	next          http.RoundTripper
+}
+
+// WithMode 设置录制模式，默认为 ModeAuto
+func WithMode(mode Mode) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.mode = mode
+	}
+}
+
+// WithMatchers 设置请求匹配规则，全部满足才算匹配，默认为 MatchMethod 和 MatchURL
+func WithMatchers(matchers ...Matcher) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.matchers = matchers
+	}
+}
+
+// WithRedactHeaders 追加需要脱敏的请求头、响应头
+func WithRedactHeaders(names ...string) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.redactHeaders = append(cfg.redactHeaders, names...)
+	}
+}
+
+// WithRedactQuery 需要脱敏的查询参数，如 ak、token，回放时同样脱敏后再匹配
+func WithRedactQuery(names ...string) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.redactQuery = append(cfg.redactQuery, names...)
+	}
+}
+
+// WithRedactForm 追加需要脱敏的表单请求体字段，回放时同样脱敏后再匹配
+func WithRedactForm(names ...string) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.redactForm = append(cfg.redactForm, names...)
+	}
+}
+
+// WithRedactBody 自定义请求体脱敏，在表单字段脱敏之后执行，回放时同样脱敏后再匹配
+// 用于 JSON 等其他格式的请求体，f 不能修改 body
+func WithRedactBody(f func(req *http.Request, body []byte) []byte) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.redactBody = f
+	}
+}
+
+// WithTransport 录制时使用的真实 Transport，默认为 http.DefaultTransport
+func WithTransport(next http.RoundTripper) xopt.Option[config] {
+	return func(cfg *config) {
+		cfg.next = next
+	}
+}
+
+// Recorder 录制、回放 HTTP 交互的 RoundTripper
+type Recorder struct {
+	path      string
+	cfg       config
+	recording bool
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+var _ http.RoundTripper = (*Recorder)(nil)
+
+// New 创建 Recorder，path 以 .har 结尾时使用 HAR 格式，否则使用 YAML
+func New(path string, opts ...xopt.Option[config]) (*Recorder, error) {
+	cfg := config{
+		matchers:      []Matcher{MatchMethod, MatchURL},
+		redactHeaders: slices.Clone(DefaultRedactHeaders),
+		redactForm:    slices.Clone(DefaultRedactForm),
+		next:          http.DefaultTransport,
+	}
+	xopt.Apply(opts, &cfg)
+	r := &Recorder{path: path, cfg: cfg, cassette: &Cassette{}}
+
+	recording := cfg.mode == ModeRecord
+	if cfg.mode == ModeAuto {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			recording = true
+		}
+	}
+	r.recording = recording
+	if !recording {
+		cassette, err := LoadCassette(path)
+		if err != nil {
+			return nil, err
+		}
+		r.cassette = cassette
+		r.used = make([]bool, len(cassette.Interactions))
+	}
+	return r, nil
+}
+
+// Recording 是否处于录制状态
+func (r *Recorder) Recording() bool {
+	return r.recording
+}
+
+// Stop 录制状态下将磁带写入文件，回放状态下无操作
+func (r *Recorder) Stop() error {
+	if !r.recording {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(r.path)
+}
+
+// RoundTrip 读取并关闭请求体，录制时复制请求后转发，不修改原请求
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.recording {
+		return r.record(req, body)
+	}
+	return r.replay(req, body)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	start := time.Now()
+	out := req.Clone(req.Context())
+	if body != nil {
+		out.Body = io.NopCloser(bytes.NewReader(body))
+		out.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	resp, err := r.cfg.next.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	interaction := &Interaction{
+		RecordedAt: start.UTC(),
+		Duration:   time.Since(start),
+		Request: Request{
+			Method: req.Method,
+			URL:    r.redactURL(req.URL),
+			Header: r.redactHeader(req.Header),
+			Body:   string(r.redactRequestBody(req, body)),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     r.redactHeader(resp.Header),
+			Body:       string(respBody),
+		},
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// replay 优先返回未使用过的匹配记录，全部用过时复用最后一条匹配记录
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	u := *req.URL
+	u.RawQuery = r.redactQuery(req.URL.Query()).Encode()
+	matchReq := req.Clone(req.Context())
+	matchReq.URL = &u
+	matchBody := r.redactRequestBody(req, body)
+
+	r.mu.Lock()
+	found := -1
+	for i, interaction := range r.cassette.Interactions {
+		if !r.match(matchReq, matchBody, interaction.Request) {
+			continue
+		}
+		found = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if found < 0 {
+		r.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, u.String())
+	}
+	r.used[found] = true
+	recorded := r.cassette.Interactions[found].Response
+	r.mu.Unlock()
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+func (r *Recorder) match(req *http.Request, body []byte, recorded Request) bool {
+	for _, m := range r.cfg.matchers {
+		if !m(req, body, recorded) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Recorder) redactHeader(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	h = h.Clone()
+	for _, name := range r.cfg.redactHeaders {
+		if values := h.Values(name); len(values) > 0 {
+			h.Del(name)
+			for range values {
+				h.Add(name, Redacted)
+			}
+		}
+	}
+	return h
+}
+
+func (r *Recorder) redactQuery(query url.Values) url.Values {
+	for _, name := range r.cfg.redactQuery {
+		if values, ok := query[name]; ok {
+			for i := range values {
+				values[i] = Redacted
+			}
+		}
+	}
+	return query
+}
+
+// redactURL 脱敏查询参数和 userinfo，有密码时只脱敏密码，否则用户名可能是令牌
+func (r *Recorder) redactURL(u *url.URL) string {
+	redacted := *u
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			redacted.User = url.UserPassword(u.User.Username(), Redacted)
+		} else {
+			redacted.User = url.User(Redacted)
+		}
+	}
+	if len(r.cfg.redactQuery) > 0 {
+		redacted.RawQuery = r.redactQuery(u.Query()).Encode()
+	}
+	return redacted.String()
+}
+
+// redactRequestBody 脱敏表单字段并执行自定义脱敏，返回新的请求体
+func (r *Recorder) redactRequestBody(req *http.Request, body []byte) []byte {
+	if len(body) > 0 && len(r.cfg.redactForm) > 0 {
+		mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if form, err := url.ParseQuery(string(body)); err == nil && mediaType == "application/x-www-form-urlencoded" {
+			var changed bool
+			for _, name := range r.cfg.redactForm {
+				for i := range form[name] {
+					form[name][i] = Redacted
+					changed = true
+				}
+			}
+			if changed {
+				body = []byte(form.Encode())
+			}
+		}
+	}
+	if r.cfg.redactBody != nil {
+		body = r.cfg.redactBody(req, body)
+	}
+	return body
+}
+
+// readRequestBody 读取并关闭请求体，RoundTripper 负责关闭请求体
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	return body, err
+}
+
+func sortedKeys[M ~map[string]V, V any](m M) []string {
+	return slices.Sorted(maps.Keys(m))
+}
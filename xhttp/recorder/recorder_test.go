@@ -0,0 +1,216 @@
+package recorder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/monaco-io/lib/typing/xopt"
+	"github.com/monaco-io/lib/xhttp"
+)
+
+func newServer() *httptest.Server {
+	var calls atomic.Int32
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		w.Header().Set("Set-Cookie", "session=secret")
+		switch r.URL.Path {
+		case "/binary":
+			w.Header().Set(xhttp.ContentType, "application/octet-stream")
+			_, _ = w.Write([]byte{0xff, 0x00, 0xfe})
+		default:
+			w.Header().Set(xhttp.ContentType, xhttp.ContentTypeText)
+			_, _ = fmt.Fprintf(w, "call %d %s", n, r.URL.Query().Get("q"))
+		}
+	}))
+}
+
+func TestRecordReplay(t *testing.T) {
+	for _, ext := range []string{".yaml", ".har"} {
+		t.Run(ext, func(t *testing.T) {
+			server := newServer()
+			path := filepath.Join(t.TempDir(), "cassette"+ext)
+			ctx := context.Background()
+
+			rec, err := New(path, WithRedactQuery("ak"))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if !rec.Recording() {
+				t.Fatal("Recording() = false, want true for a missing cassette")
+			}
+			opts := []xopt.Option[xhttp.Request]{xhttp.Transport(rec), xhttp.DecoderText(), xhttp.Header("Authorization", "Bearer secret")}
+			var recorded []string
+			for _, url := range []string{"/a?q=1&ak=key", "/a?q=1&ak=key", "/binary"} {
+				resp, err := xhttp.Do(ctx, server.URL+url, opts...)
+				if err != nil {
+					t.Fatalf("Do() error = %v", err)
+				}
+				recorded = append(recorded, string(resp.Body))
+			}
+			if err := rec.Stop(); err != nil {
+				t.Fatalf("Stop() error = %v", err)
+			}
+			server.Close()
+
+			data, _ := os.ReadFile(path)
+			for _, secret := range []string{"Bearer secret", "session=secret", "ak=key"} {
+				if strings.Contains(string(data), secret) {
+					t.Errorf("cassette contains %q", secret)
+				}
+			}
+
+			rec, err = New(path, WithRedactQuery("ak"))
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if rec.Recording() {
+				t.Fatal("Recording() = true, want false for an existing cassette")
+			}
+			opts[0] = xhttp.Transport(rec)
+			// 查询参数顺序无关，脱敏参数使用任意值均可匹配
+			for i, url := range []string{"/a?ak=other&q=1", "/a?q=1&ak=key", "/binary"} {
+				resp, err := xhttp.Do(ctx, server.URL+url, opts...)
+				if err != nil {
+					t.Fatalf("replay Do(%s) error = %v", url, err)
+				}
+				if string(resp.Body) != recorded[i] {
+					t.Errorf("replay Do(%s) = %q, want %q", url, resp.Body, recorded[i])
+				}
+			}
+			// 全部用过后复用最后一条匹配记录
+			resp, err := xhttp.Do(ctx, server.URL+"/a?q=1&ak=x", opts...)
+			if err != nil {
+				t.Fatalf("replay reuse error = %v", err)
+			}
+			if string(resp.Body) != recorded[1] {
+				t.Errorf("replay reuse = %q, want %q", resp.Body, recorded[1])
+			}
+			if _, err := xhttp.Do(ctx, server.URL+"/missing", opts...); !errors.Is(err, ErrNoInteraction) {
+				t.Errorf("replay Do(/missing) error = %v, want ErrNoInteraction", err)
+			}
+		})
+	}
+}
+
+func TestMatchBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var buf strings.Builder
+		_, _ = fmt.Fprintf(&buf, "echo ")
+		b := make([]byte, 64)
+		n, _ := r.Body.Read(b)
+		buf.Write(b[:n])
+		_, _ = w.Write([]byte(buf.String()))
+	}))
+	path := filepath.Join(t.TempDir(), "body.yaml")
+	ctx := context.Background()
+
+	rec, err := New(path, WithMode(ModeRecord))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, body := range []string{"one", "two"} {
+		if _, err := xhttp.Do(ctx, server.URL, xhttp.Method(http.MethodPost), xhttp.BodyText(body), xhttp.Transport(rec)); err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	rec, err = New(path, WithMode(ModeReplay), WithMatchers(MatchMethod, MatchURL, MatchBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, body := range []string{"two", "one"} {
+		resp, err := xhttp.Do(ctx, server.URL, xhttp.Method(http.MethodPost), xhttp.BodyText(body), xhttp.Transport(rec), xhttp.DecoderText())
+		if err != nil {
+			t.Fatalf("Do() error = %v", err)
+		}
+		if string(resp.Body) != "echo "+body {
+			t.Errorf("Do(%s) = %q", body, resp.Body)
+		}
+	}
+	if _, err := New(filepath.Join(t.TempDir(), "missing.yaml"), WithMode(ModeReplay)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("New() error = %v, want os.ErrNotExist", err)
+	}
+}
+
+// closeTracker 记录请求体是否被关闭
+type closeTracker struct {
+	*strings.Reader
+	closed bool
+}
+
+func (c *closeTracker) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestRedactRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		_, _ = fmt.Fprintf(w, "grant %s", r.PostForm.Get("grant_type"))
+	}))
+	path := filepath.Join(t.TempDir(), "redact.yaml")
+	const form = "grant_type=client_credentials&client_secret=s3cret&token=t0ken"
+	newRequest := func() (*http.Request, *closeTracker) {
+		body := &closeTracker{Reader: strings.NewReader(form)}
+		u := strings.Replace(server.URL, "http://", "http://user:pa55@", 1)
+		req, _ := http.NewRequest(http.MethodPost, u+"/token", body)
+		req.Header.Set(xhttp.ContentType, xhttp.ContentTypeURLEncoded)
+		return req, body
+	}
+	redactToken := WithRedactBody(func(_ *http.Request, body []byte) []byte {
+		return []byte(strings.ReplaceAll(string(body), "t0ken", Redacted))
+	})
+
+	rec, err := New(path, WithMode(ModeRecord), redactToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, body := newRequest()
+	origBody := req.Body
+	resp, err := rec.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("RoundTrip() error = %v", err)
+	}
+	_ = resp.Body.Close()
+	// 不修改调用方的请求
+	if req.Body != origBody || req.GetBody != nil || !body.closed {
+		t.Errorf("RoundTrip() modified request body: %T, closed = %v", req.Body, body.closed)
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	server.Close()
+
+	data, _ := os.ReadFile(path)
+	for _, secret := range []string{"pa55", "s3cret", "t0ken"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, data)
+		}
+	}
+
+	rec, err = New(path, WithMode(ModeReplay), WithMatchers(MatchMethod, MatchURL, MatchBody), redactToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, body = newRequest()
+	resp, err = rec.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("replay RoundTrip() error = %v", err)
+	}
+	_ = resp.Body.Close()
+	if !body.closed {
+		t.Error("replay should close the request body")
+	}
+}
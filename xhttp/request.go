@@ -45,11 +45,9 @@ func (f *InterceptorFunc) After(resp *http.Response, req *http.Request) error {
 	return nil
 }
 
-var interceptors []Interceptor
-
-// RegisterInterceptor 注册拦截器
+// RegisterInterceptor 为 DefaultClient 注册拦截器
 func RegisterInterceptors(i ...Interceptor) {
-	interceptors = append(interceptors, i...)
+	DefaultClient.Use(i...)
 }
 
 const (
@@ -67,13 +65,23 @@ type Request struct {
 	*http.Client
 
 	decoder
+
+	interceptors []Interceptor
+	retry        *retryConfig
+	attempts     int
+	maxBodySize  int64
+	success      func(code int) bool
+	// err 选项执行时的错误，由 build 返回
+	err error
 }
 
 type decoder string
 
-const defaultDecoder = decoderJSON
+const defaultDecoder = decoderAuto
 
 const (
+	// decoderAuto 按响应的 Content-Type 选择解码方式
+	decoderAuto decoder = ""
 	decoderJSON decoder = "json"
 	decoderXML  decoder = "xml"
 	decoderYAML decoder = "yaml"
@@ -116,10 +124,47 @@ func Client(client *http.Client) xopt.Option[Request] {
 	}
 }
 
+// NativeBody 设置请求体，与 http.NewRequest 一致，内存中的 body 会设置 Content-Length 并可重放
 func NativeBody(contentType string, body io.Reader) xopt.Option[Request] {
 	return func(request *Request) {
 		request.Header.Set(ContentType, contentType)
 		request.Body = io.NopCloser(body)
+		request.GetBody = nil
+		switch v := body.(type) {
+		case *bytes.Buffer:
+			buf := v.Bytes()
+			request.ContentLength = int64(len(buf))
+			request.GetBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(buf)), nil
+			}
+		case *bytes.Reader:
+			snapshot := *v
+			request.ContentLength = int64(v.Len())
+			request.GetBody = func() (io.ReadCloser, error) {
+				r := snapshot
+				return io.NopCloser(&r), nil
+			}
+		case *strings.Reader:
+			snapshot := *v
+			request.ContentLength = int64(v.Len())
+			request.GetBody = func() (io.ReadCloser, error) {
+				r := snapshot
+				return io.NopCloser(&r), nil
+			}
+		}
+	}
+}
+
+// URL 替换请求地址，相对地址基于当前地址解析
+func URL(rawURL string) xopt.Option[Request] {
+	return func(request *Request) {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			request.err = err
+			return
+		}
+		request.URL = request.URL.ResolveReference(u)
+		request.Host = request.URL.Host
 	}
 }
 
@@ -159,40 +204,9 @@ func BodyWWWFormURLEncoded(body url.Values) xopt.Option[Request] {
 	}
 }
 
-func BodyMultipartForm(form *multipart.Form) xopt.Option[Request] {
-	return func(request *Request) {
-		var buf bytes.Buffer
-		writer := multipart.NewWriter(&buf)
-
-		// Write form values
-		for key, values := range form.Value {
-			for _, value := range values {
-				_ = writer.WriteField(key, value)
-			}
-		}
-
-		// Write form files
-		for key, files := range form.File {
-			for _, fileHeader := range files {
-				file, err := fileHeader.Open()
-				if err != nil {
-					continue
-				}
-				defer file.Close()
-
-				part, err := writer.CreateFormFile(key, fileHeader.Filename)
-				if err != nil {
-					continue
-				}
-				_, _ = io.Copy(part, file)
-			}
-		}
-
-		writer.Close()
-
-		contentType := writer.FormDataContentType()
-		NativeBody(contentType, &buf)(request)
-	}
+// BodyMultipartForm 流式发送 multipart.Form，字段按名称排序
+func BodyMultipartForm(form *multipart.Form, opts ...xopt.Option[multipartConfig]) xopt.Option[Request] {
+	return BodyMultipart(formParts(form), opts...)
 }
 
 func BasicAuth(username, password string) xopt.Option[Request] {
@@ -221,58 +235,61 @@ func Headers(headers http.Header, replace ...bool) xopt.Option[Request] {
 	}
 }
 
+// MaxBodySize 限制 Do、Sugar 读取的响应体大小，超过时返回 ErrBodyTooLarge，<=0 表示不限制
+func MaxBodySize(n int64) xopt.Option[Request] {
+	return func(request *Request) {
+		request.maxBodySize = n
+	}
+}
+
 func Transport(transport http.RoundTripper) xopt.Option[Request] {
 	return func(request *Request) {
+		request.ownClient()
 		request.Transport = transport
 	}
 }
 
 func Timeout(timeout time.Duration) xopt.Option[Request] {
 	return func(request *Request) {
+		request.ownClient()
 		request.Timeout = timeout
 	}
 }
 
 func Jar(jar http.CookieJar) xopt.Option[Request] {
 	return func(request *Request) {
+		request.ownClient()
 		request.Jar = jar
 	}
 }
 
 func CheckRedirect(f func(req *http.Request, via []*http.Request) error) xopt.Option[Request] {
 	return func(request *Request) {
+		request.ownClient()
 		request.CheckRedirect = f
 	}
 }
 
+// NewRequest 使用 DefaultClient 构造请求但不发送
+func NewRequest(ctx context.Context, url string, opts ...xopt.Option[Request]) (*Request, error) {
+	return DefaultClient.NewRequest(ctx, url, opts...)
+}
+
 func build(ctx context.Context, url string, opts ...xopt.Option[Request]) (*Request, error) {
-	request, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodGet, url, nil,
-	)
-	if err != nil {
-		return nil, err
-	}
-	xrequest := &Request{
-		Request: request,
-		Client:  http.DefaultClient,
-		decoder: defaultDecoder,
-	}
-	xopt.Apply(opts, xrequest)
-	// 执行所有拦截器的Before方法
-	for _, i := range interceptors {
-		if err := i.Before(xrequest.Request); err != nil {
-			return nil, err
-		}
-	}
-	return xrequest, nil
+	return DefaultClient.build(ctx, url, opts...)
 }
 
 // doWithInterceptors 执行请求并调用拦截器链
 func (r *Request) doWithInterceptors(req *http.Request) (*http.Response, error) {
-	resp, err := r.Do(req)
-	for _, i := range interceptors {
+	resp, err := r.send(req)
+	for _, i := range r.interceptors {
 		_ = i.After(resp, r.Request)
 	}
 	return resp, err
 }
+
+// ownClient 复制 client，避免修改共享的 http.DefaultClient 或 XClient 的 client
+func (r *Request) ownClient() {
+	client := *r.Client
+	r.Client = &client
+}
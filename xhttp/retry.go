@@ -0,0 +1,263 @@
+package xhttp
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+const (
+	RetryAfter     = "Retry-After"
+	IdempotencyKey = "Idempotency-Key"
+)
+
+const (
+	DefaultRetryAttempts = 3
+	DefaultRetryBase     = 100 * time.Millisecond
+	DefaultRetryMax      = 5 * time.Second
+	DefaultRetryJitter   = 0.2
+)
+
+// DefaultRetryStatus 默认重试的响应状态码
+var DefaultRetryStatus = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+type retryConfig struct {
+	attempts      int
+	base          time.Duration
+	max           time.Duration
+	jitter        float64
+	status        []int
+	nonIdempotent bool
+	ignoreAfter   bool
+	retryOnError  func(err error) bool
+	sleep         func(ctx context.Context, d time.Duration) error
+}
+
+// RetryAttempts 最大尝试次数（包含首次请求），默认为 3
+func RetryAttempts(n int) xopt.Option[retryConfig] {
+	return func(cfg *retryConfig) {
+		cfg.attempts = n
+	}
+}
+
+// RetryBackoff 指数退避的初始间隔和最大间隔，默认为 100ms 和 5s
+func RetryBackoff(base, max time.Duration) xopt.Option[retryConfig] {
+	return func(cfg *retryConfig) {
+		cfg.base = base
+		cfg.max = max
+	}
+}
+
+// RetryJitter 退避间隔的随机抖动比例，取值 [0, 1]，默认为 0.2
+func RetryJitter(fraction float64) xopt.Option[retryConfig] {
+	return func(cfg *retryConfig) {
+		cfg.jitter = min(max(fraction, 0), 1)
+	}
+}
+
+// RetryOnStatus 指定需要重试的响应状态码，覆盖 DefaultRetryStatus
+func RetryOnStatus(codes ...int) xopt.Option[retryConfig] {
+	return func(cfg *retryConfig) {
+		cfg.status = codes
+	}
+}
+
+// RetryOnError 自定义哪些网络错误需要重试，默认除 context 取消和熔断外的错误均重试
+func RetryOnError(f func(err error) bool) xopt.Option[retryConfig] {
+	return func(cfg *retryConfig) {
+		cfg.retryOnError = f
+	}
+}
+
+// RetryNonIdempotent 允许重试 POST、PATCH 等非幂等请求
+// 未开启时，带 Idempotency-Key 请求头的请求同样会被重试
+func RetryNonIdempotent() xopt.Option[retryConfig] {
+	return func(cfg *retryConfig) {
+		cfg.nonIdempotent = true
+	}
+}
+
+// RetryIgnoreRetryAfter 忽略响应中的 Retry-After，始终使用指数退避
+func RetryIgnoreRetryAfter() xopt.Option[retryConfig] {
+	return func(cfg *retryConfig) {
+		cfg.ignoreAfter = true
+	}
+}
+
+// Retry 为当前请求开启失败重试
+// 请求体会被缓存以便重放，Retry-After 超过最大间隔时不再重试，直接返回该响应
+func Retry(opts ...xopt.Option[retryConfig]) xopt.Option[Request] {
+	return func(request *Request) {
+		cfg := retryConfig{
+			attempts: DefaultRetryAttempts,
+			base:     DefaultRetryBase,
+			max:      DefaultRetryMax,
+			jitter:   DefaultRetryJitter,
+			status:   DefaultRetryStatus,
+			sleep:    sleepContext,
+		}
+		xopt.Apply(opts, &cfg)
+		request.retry = &cfg
+	}
+}
+
+// send 发送请求，按配置重试，返回最终响应
+func (r *Request) send(req *http.Request) (*http.Response, error) {
+	cfg := r.retry
+	if cfg == nil || cfg.attempts <= 1 || !cfg.retryable(req) {
+		r.attempts = 1
+		return r.Do(req)
+	}
+	if err := rewindableBody(req); err != nil {
+		return nil, err
+	}
+	for attempt := 1; ; attempt++ {
+		r.attempts = attempt
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+		resp, err := r.Do(req)
+		if attempt >= cfg.attempts {
+			return resp, err
+		}
+		delay, ok := cfg.next(req, resp, err, attempt)
+		if !ok {
+			return resp, err
+		}
+		if resp != nil {
+			drainBody(resp.Body)
+		}
+		if err := cfg.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// retryable 幂等方法或显式开启时才重试
+func (cfg *retryConfig) retryable(req *http.Request) bool {
+	if cfg.nonIdempotent {
+		return true
+	}
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	_, ok := req.Header[IdempotencyKey]
+	return ok
+}
+
+// next 判断是否需要重试，并返回等待时间
+func (cfg *retryConfig) next(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool) {
+	if err != nil {
+		if req.Context().Err() != nil || errors.Is(err, context.Canceled) || errors.Is(err, ErrCircuitOpen) {
+			return 0, false
+		}
+		if cfg.retryOnError != nil && !cfg.retryOnError(err) {
+			return 0, false
+		}
+		return cfg.backoff(attempt), true
+	}
+	if !slices.Contains(cfg.status, resp.StatusCode) {
+		return 0, false
+	}
+	if !cfg.ignoreAfter {
+		if after, ok := parseRetryAfter(resp.Header.Get(RetryAfter), time.Now()); ok {
+			if after > cfg.max {
+				return 0, false
+			}
+			return after, true
+		}
+	}
+	return cfg.backoff(attempt), true
+}
+
+// backoff 第 attempt 次失败后的退避间隔：base * 2^(attempt-1)，上限 max，叠加抖动
+func (cfg *retryConfig) backoff(attempt int) time.Duration {
+	d := cfg.base
+	for i := 1; i < attempt && d < cfg.max; i++ {
+		d *= 2
+	}
+	d = min(d, cfg.max)
+	if cfg.jitter > 0 && d > 0 {
+		delta := float64(d) * cfg.jitter
+		d = time.Duration(float64(d) - delta + rand.Float64()*2*delta)
+	}
+	return max(d, 0)
+}
+
+// parseRetryAfter 解析秒数或 HTTP-date 格式的 Retry-After
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	return max(t.Sub(now), 0), true
+}
+
+// rewindableBody 确保请求体可以通过 GetBody 重放
+func rewindableBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+	data, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.Body, _ = req.GetBody()
+	return nil
+}
+
+// drainBody 读取少量剩余数据后关闭，以便复用连接
+func drainBody(body io.ReadCloser) {
+	if body == nil {
+		return
+	}
+	_, _ = io.CopyN(io.Discard, body, 4<<10)
+	_ = body.Close()
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,187 @@
+package xhttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+func TestRetryStatus(t *testing.T) {
+	var calls atomic.Int32
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	resp, err := Do(context.Background(), server.URL,
+		Method(http.MethodPut),
+		BodyText("payload"),
+		Retry(RetryBackoff(time.Millisecond, 10*time.Millisecond)),
+	)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if resp.Code != http.StatusOK || string(resp.Body) != "ok" {
+		t.Errorf("Do() = %d %q, want 200 ok", resp.Code, resp.Body)
+	}
+	if resp.Attempts != 3 {
+		t.Errorf("Attempts = %d, want 3", resp.Attempts)
+	}
+	for i, body := range bodies {
+		if body != "payload" {
+			t.Errorf("attempt %d body = %q, want payload", i+1, body)
+		}
+	}
+}
+
+func TestRetryExhausted(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	resp, err := Do(context.Background(), server.URL,
+		Retry(RetryAttempts(2), RetryBackoff(time.Millisecond, time.Millisecond)),
+	)
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if resp.Code != http.StatusBadGateway || calls.Load() != 2 || resp.Attempts != 2 {
+		t.Errorf("Do() code=%d calls=%d attempts=%d, want 502/2/2", resp.Code, calls.Load(), resp.Attempts)
+	}
+}
+
+func TestRetryNonIdempotent(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name  string
+		opts  []xopt.Option[Request]
+		retry bool
+	}{
+		{"post", []xopt.Option[Request]{Retry(RetryAttempts(2), RetryBackoff(0, 0))}, false},
+		{"post with idempotency key", []xopt.Option[Request]{Header(IdempotencyKey, "k1"), Retry(RetryAttempts(2), RetryBackoff(0, 0))}, true},
+		{"post opt-in", []xopt.Option[Request]{Retry(RetryAttempts(2), RetryBackoff(0, 0), RetryNonIdempotent())}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls.Store(0)
+			opts := append([]xopt.Option[Request]{Method(http.MethodPost), BodyText("x")}, tt.opts...)
+			if _, err := Do(context.Background(), server.URL, opts...); err != nil {
+				t.Fatalf("Do() error = %v", err)
+			}
+			want := int32(1)
+			if tt.retry {
+				want = 2
+			}
+			if calls.Load() != want {
+				t.Errorf("calls = %d, want %d", calls.Load(), want)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set(RetryAfter, "120")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Retry-After 超过最大间隔，直接返回
+	resp, err := Do(context.Background(), server.URL, Retry(RetryBackoff(time.Millisecond, time.Second)))
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if resp.Code != http.StatusTooManyRequests || resp.Attempts != 1 {
+		t.Errorf("Do() code=%d attempts=%d, want 429/1", resp.Code, resp.Attempts)
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"3", 3 * time.Second, true},
+		{"-1", 0, false},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second, true},
+		{now.Add(-time.Second).Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestRetryNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := server.URL
+	server.Close()
+
+	var errs int
+	_, err := Do(context.Background(), url, Retry(
+		RetryAttempts(3),
+		RetryBackoff(0, 0),
+		RetryOnError(func(err error) bool { errs++; return true }),
+	))
+	if err == nil {
+		t.Fatal("Do() expected error")
+	}
+	if errs != 2 {
+		t.Errorf("RetryOnError called %d times, want 2", errs)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = Do(ctx, url, Retry(RetryBackoff(time.Second, time.Second)))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Do() error = %v, want context.Canceled", err)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	cfg := retryConfig{base: 100 * time.Millisecond, max: time.Second}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second, time.Second}
+	for i, w := range want {
+		if got := cfg.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+	cfg.jitter = 0.5
+	for range 100 {
+		if got := cfg.backoff(1); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("backoff with jitter = %v, want within [50ms, 150ms]", got)
+		}
+	}
+}
@@ -0,0 +1,311 @@
+package xhttp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/monaco-io/lib/typing/xjson"
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+const (
+	ContentTypeEventStream = "text/event-stream"
+	LastEventID            = "Last-Event-ID"
+)
+
+const (
+	// DefaultSSERetry 服务端未指定 retry 时的重连间隔
+	DefaultSSERetry = 3 * time.Second
+	// DefaultSSEMaxLineSize 单行最大字节数
+	DefaultSSEMaxLineSize = 1 << 20
+	// DefaultSSEMaxReconnects 两次收到事件之间的默认最大重连次数，避免服务不可用时无限重试
+	DefaultSSEMaxReconnects = 10
+)
+
+// ErrNotEventStream 响应的 Content-Type 不是 text/event-stream
+var ErrNotEventStream = errors.New("lib.xhttp:response is not an event stream")
+
+// Event Server-Sent Events 事件
+type Event struct {
+	// ID 最近一次收到的事件 ID，重连时作为 Last-Event-ID 发送
+	ID    string `json:"id,omitempty"`
+	Event string `json:"event"`
+	Data  string `json:"data"`
+	// Retry 当前事件携带的重连间隔，未携带时为 0
+	Retry time.Duration `json:"retry,omitempty"`
+}
+
+// TypedEvent data 使用 xjson 解码后的事件
+type TypedEvent[T any] struct {
+	Event
+	Value T `json:"value"`
+}
+
+type sseConfig struct {
+	retry         time.Duration
+	maxReconnects int
+	maxLineSize   int
+	options       []xopt.Option[Request]
+}
+
+// SSERetry 默认重连间隔，服务端的 retry 字段会覆盖该值
+func SSERetry(d time.Duration) xopt.Option[sseConfig] {
+	return func(cfg *sseConfig) {
+		cfg.retry = d
+	}
+}
+
+// SSEMaxReconnects 两次收到事件之间的最大重连次数，0 表示不重连，负数表示不限制
+// 默认为 DefaultSSEMaxReconnects，耗尽后返回最后一次连接错误；不限制时连接错误不会返回，只能通过 ctx 结束
+func SSEMaxReconnects(n int) xopt.Option[sseConfig] {
+	return func(cfg *sseConfig) {
+		cfg.maxReconnects = n
+	}
+}
+
+// SSEMaxLineSize 单行最大字节数，默认为 1MiB
+func SSEMaxLineSize(n int) xopt.Option[sseConfig] {
+	return func(cfg *sseConfig) {
+		cfg.maxLineSize = n
+	}
+}
+
+// SSEOptions 订阅请求使用的请求选项，请求体会被缓存以便重连时重放
+func SSEOptions(opts ...xopt.Option[Request]) xopt.Option[sseConfig] {
+	return func(cfg *sseConfig) {
+		cfg.options = append(cfg.options, opts...)
+	}
+}
+
+// Events 使用 DefaultClient 订阅事件流
+func Events(ctx context.Context, url string, opts ...xopt.Option[sseConfig]) iter.Seq2[Event, error] {
+	return DefaultClient.Events(ctx, url, opts...)
+}
+
+// Events 订阅事件流，连接断开后按 retry 间隔携带 Last-Event-ID 自动重连
+// 非 200 响应、非事件流响应、context 取消或重连次数耗尽时返回错误并结束；服务端返回 204 时正常结束
+// 收到事件后重连次数清零，服务端持续不可用时最多重连 DefaultSSEMaxReconnects 次
+func (c *XClient) Events(ctx context.Context, url string, opts ...xopt.Option[sseConfig]) iter.Seq2[Event, error] {
+	cfg := sseConfig{retry: DefaultSSERetry, maxReconnects: DefaultSSEMaxReconnects, maxLineSize: DefaultSSEMaxLineSize}
+	xopt.Apply(opts, &cfg)
+	return func(yield func(Event, error) bool) {
+		reqOpts := append([]xopt.Option[Request]{
+			Header("Accept", ContentTypeEventStream, true),
+			Header("Cache-Control", "no-cache", true),
+		}, cfg.options...)
+		// 每次连接各执行一次拦截器的 Before 和 After
+		xrequest, err := c.NewRequest(ctx, url, reqOpts...)
+		if err != nil {
+			yield(Event{}, err)
+			return
+		}
+		if err := rewindableBody(xrequest.Request); err != nil {
+			yield(Event{}, err)
+			return
+		}
+
+		s := &sseStream{retry: cfg.retry, maxLineSize: cfg.maxLineSize}
+		for reconnects := 0; ; reconnects++ {
+			if reconnects > 0 {
+				if err := sleepContext(ctx, s.retry); err != nil {
+					yield(Event{}, err)
+					return
+				}
+				if err := s.prepare(xrequest.Request); err != nil {
+					yield(Event{}, err)
+					return
+				}
+			}
+			received, stop, err := s.connect(xrequest, yield)
+			if stop {
+				return
+			}
+			if received {
+				reconnects = 0
+			}
+			if ctx.Err() != nil {
+				yield(Event{}, ctx.Err())
+				return
+			}
+			if cfg.maxReconnects >= 0 && reconnects >= cfg.maxReconnects {
+				if err != nil && !errors.Is(err, io.EOF) {
+					yield(Event{}, err)
+				}
+				return
+			}
+		}
+	}
+}
+
+// TypedEvents 使用 DefaultClient 订阅事件流并解码 data
+func TypedEvents[T any](ctx context.Context, url string, opts ...xopt.Option[sseConfig]) iter.Seq2[TypedEvent[T], error] {
+	return ClientTypedEvents[T](DefaultClient, ctx, url, opts...)
+}
+
+// ClientTypedEvents 订阅事件流并使用 xjson 解码 data
+// 解码失败时返回原始事件和错误，调用方可以选择跳过（如 "[DONE]"）继续迭代
+func ClientTypedEvents[T any](c *XClient, ctx context.Context, url string, opts ...xopt.Option[sseConfig]) iter.Seq2[TypedEvent[T], error] {
+	return func(yield func(TypedEvent[T], error) bool) {
+		for event, err := range c.Events(ctx, url, opts...) {
+			if err != nil {
+				yield(TypedEvent[T]{Event: event}, err)
+				return
+			}
+			typed := TypedEvent[T]{Event: event}
+			if err := xjson.Unmarshal([]byte(event.Data), &typed.Value); err != nil {
+				err = fmt.Errorf("TypedEvents.Decode: %w event.Data=%s", err, event.Data)
+				if !yield(typed, err) {
+					return
+				}
+				continue
+			}
+			if !yield(typed, nil) {
+				return
+			}
+		}
+	}
+}
+
+// sseStream 跨连接保存的状态
+type sseStream struct {
+	lastID      string
+	retry       time.Duration
+	maxLineSize int
+}
+
+// prepare 重连前重放请求体并设置 Last-Event-ID
+func (s *sseStream) prepare(req *http.Request) error {
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		req.Body = body
+	}
+	if s.lastID != "" {
+		req.Header.Set(LastEventID, s.lastID)
+	}
+	return nil
+}
+
+// connect 建立一次连接并分发事件
+// received 表示本次连接收到过事件，stop 表示迭代已结束，err 为可重连的错误
+func (s *sseStream) connect(xrequest *Request, yield func(Event, error) bool) (received, stop bool, err error) {
+	// 与 Do 一致，拦截器 Before 失败时不再重连
+	if err := xrequest.before(); err != nil {
+		yield(Event{}, err)
+		return false, true, nil
+	}
+	resp, err := xrequest.doWithInterceptors(xrequest.Request)
+	if err != nil {
+		return false, false, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	switch {
+	case resp.StatusCode == http.StatusNoContent:
+		return false, true, nil
+	case resp.StatusCode != http.StatusOK:
+		drainBody(resp.Body)
+		yield(Event{}, fmt.Errorf("%w: %d", ErrUnexpectedStatus, resp.StatusCode))
+		return false, true, nil
+	}
+	if mediaType, _, _ := mime.ParseMediaType(resp.Header.Get(ContentType)); mediaType != ContentTypeEventStream {
+		yield(Event{}, fmt.Errorf("%w: %s", ErrNotEventStream, resp.Header.Get(ContentType)))
+		return false, true, nil
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 4096), s.maxLineSize)
+	scanner.Split(scanSSELines)
+	var (
+		event Event
+		data  strings.Builder
+		first = true
+	)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if first {
+			line = bytes.TrimPrefix(line, []byte("\ufeff"))
+			first = false
+		}
+		if len(line) == 0 {
+			// 空行分发事件
+			if data.Len() > 0 {
+				event.ID = s.lastID
+				event.Data = strings.TrimSuffix(data.String(), "\n")
+				if event.Event == "" {
+					event.Event = "message"
+				}
+				received = true
+				if !yield(event, nil) {
+					return received, true, nil
+				}
+			}
+			event = Event{}
+			data.Reset()
+			continue
+		}
+		if line[0] == ':' {
+			continue
+		}
+		field, value, _ := bytes.Cut(line, []byte(":"))
+		value = bytes.TrimPrefix(value, []byte(" "))
+		switch string(field) {
+		case "event":
+			event.Event = string(value)
+		case "data":
+			data.Write(value)
+			data.WriteByte('\n')
+		case "id":
+			if bytes.IndexByte(value, 0) < 0 {
+				s.lastID = string(value)
+			}
+		case "retry":
+			if ms, err := strconv.ParseUint(string(value), 10, 63); err == nil {
+				s.retry = time.Duration(ms) * time.Millisecond
+				event.Retry = s.retry
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return received, false, err
+	}
+	return received, false, io.EOF
+}
+
+// scanSSELines 按 \r\n、\n 或 \r 分割行
+func scanSSELines(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+	if i := bytes.IndexAny(data, "\r\n"); i >= 0 {
+		if data[i] == '\n' {
+			return i + 1, data[:i], nil
+		}
+		if i+1 < len(data) {
+			if data[i+1] == '\n' {
+				return i + 2, data[:i], nil
+			}
+			return i + 1, data[:i], nil
+		}
+		if atEOF {
+			return i + 1, data[:i], nil
+		}
+		// \r 位于末尾，等待更多数据判断是否为 \r\n
+		return 0, nil, nil
+	}
+	if atEOF {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
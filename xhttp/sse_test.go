@@ -0,0 +1,245 @@
+package xhttp
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestEvents(t *testing.T) {
+	var (
+		conns   atomic.Int32
+		lastIDs []string
+		bodies  []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		lastIDs = append(lastIDs, r.Header.Get(LastEventID))
+		switch conns.Add(1) {
+		case 1:
+			w.Header().Set(ContentType, ContentTypeEventStream+"; charset=utf-8")
+			_, _ = io.WriteString(w, "\ufeff: comment\n\nid: 1\nretry: 10\ndata: hello\ndata:world\n\r\nevent: update\r\ndata\r\n\r")
+		case 2:
+			w.Header().Set(ContentType, ContentTypeEventStream)
+			_, _ = io.WriteString(w, "id: 2\nevent: update\ndata: {\"n\":2}\n\ndata: partial")
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	var got []Event
+	for event, err := range Events(context.Background(), server.URL,
+		SSERetry(time.Hour),
+		SSEOptions(Method(http.MethodPost), BodyText("subscribe")),
+	) {
+		if err != nil {
+			t.Fatalf("Events() error = %v", err)
+		}
+		got = append(got, event)
+	}
+	want := []Event{
+		{ID: "1", Event: "message", Data: "hello\nworld", Retry: 10 * time.Millisecond},
+		{ID: "1", Event: "update", Data: ""},
+		{ID: "2", Event: "update", Data: `{"n":2}`},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Events() = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("event[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	if strings.Join(lastIDs, ",") != ",1,2" {
+		t.Errorf("Last-Event-ID = %q, want [\"\" 1 2]", lastIDs)
+	}
+	for i, body := range bodies {
+		if body != "subscribe" {
+			t.Errorf("connection %d body = %q, want subscribe", i+1, body)
+		}
+	}
+}
+
+func TestEventsInterceptors(t *testing.T) {
+	var conns atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if conns.Add(1) > 2 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Header().Set(ContentType, ContentTypeEventStream)
+		_, _ = io.WriteString(w, "data: x\n\n")
+	}))
+	defer server.Close()
+
+	// 每次连接的 After 都有对应的 Before
+	var befores, afters atomic.Int32
+	client := NewClient(ClientInterceptors(&InterceptorFunc{
+		BeforeFunc: func(*http.Request) error {
+			if befores.Load() != afters.Load() {
+				t.Error("Before() called before the previous After()")
+			}
+			befores.Add(1)
+			return nil
+		},
+		AfterFunc: func(*http.Response, *http.Request) error {
+			afters.Add(1)
+			return nil
+		},
+	}))
+	var n int
+	for _, err := range client.Events(context.Background(), server.URL, SSERetry(time.Millisecond)) {
+		if err != nil {
+			t.Fatalf("Events() error = %v", err)
+		}
+		n++
+	}
+	if n != 2 || befores.Load() != 3 || afters.Load() != 3 {
+		t.Errorf("events = %d, Before = %d, After = %d, want 2, 3, 3", n, befores.Load(), afters.Load())
+	}
+
+	// 熔断器统计每一次重连
+	cb := NewCircuitBreaker(BreakerWindow(2, 2))
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	client = NewClient(ClientInterceptors(cb.Interceptor()))
+	var last error
+	for _, err := range client.Events(context.Background(), down.URL, SSERetry(time.Millisecond)) {
+		last = err
+	}
+	if !errors.Is(last, ErrCircuitOpen) {
+		t.Errorf("Events() error = %v, want ErrCircuitOpen", last)
+	}
+}
+
+func TestEventsErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set(ContentType, ContentTypeJSON)
+		case "/missing":
+			http.NotFound(w, r)
+		default:
+			w.Header().Set(ContentType, ContentTypeEventStream)
+			_, _ = io.WriteString(w, "data: once\n\n")
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path string
+		want error
+	}{
+		{"/json", ErrNotEventStream},
+		{"/missing", ErrUnexpectedStatus},
+	}
+	for _, tt := range tests {
+		var last error
+		for _, err := range Events(context.Background(), server.URL+tt.path) {
+			last = err
+		}
+		if !errors.Is(last, tt.want) {
+			t.Errorf("Events(%s) error = %v, want %v", tt.path, last, tt.want)
+		}
+	}
+
+	// 不重连时正常结束
+	var n int
+	for _, err := range Events(context.Background(), server.URL, SSEMaxReconnects(0)) {
+		if err != nil {
+			t.Fatalf("Events() error = %v", err)
+		}
+		n++
+	}
+	if n != 1 {
+		t.Errorf("Events() count = %d, want 1", n)
+	}
+
+	// context 取消
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var last error
+	for event, err := range Events(ctx, server.URL, SSERetry(time.Hour)) {
+		if err != nil {
+			last = err
+			break
+		}
+		if event.Data == "once" {
+			cancel()
+		}
+	}
+	if !errors.Is(last, context.Canceled) {
+		t.Errorf("Events() error = %v, want context.Canceled", last)
+	}
+
+	// 服务不可用时默认重连次数有限，最终返回连接错误
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	last = nil
+	for _, err := range Events(context.Background(), down.URL, SSERetry(time.Millisecond)) {
+		last = err
+	}
+	if last == nil {
+		t.Error("Events() expected connection error for unreachable host")
+	}
+}
+
+func TestTypedEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ContentType, ContentTypeEventStream)
+		for i := range 3 {
+			_, _ = fmt.Fprintf(w, "data: {\"token\":\"t%d\"}\n\n", i)
+		}
+		_, _ = io.WriteString(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	type chunk struct {
+		Token string `json:"token"`
+	}
+	var tokens []string
+	for event, err := range TypedEvents[chunk](context.Background(), server.URL, SSEMaxReconnects(0)) {
+		if err != nil {
+			if event.Data == "[DONE]" {
+				break
+			}
+			t.Fatalf("TypedEvents() error = %v", err)
+		}
+		tokens = append(tokens, event.Value.Token)
+	}
+	if strings.Join(tokens, ",") != "t0,t1,t2" {
+		t.Errorf("tokens = %v", tokens)
+	}
+}
+
+func TestScanSSELines(t *testing.T) {
+	input := "a\r\nb\rc\nd\r\r\ne"
+	scanner := bufio.NewScanner(&oneByteReader{r: strings.NewReader(input)})
+	scanner.Split(scanSSELines)
+	var lines []string
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if got := strings.Join(lines, "|"); got != "a|b|c|d||e" {
+		t.Errorf("lines = %q, want a|b|c|d||e", got)
+	}
+}
+
+type oneByteReader struct{ r io.Reader }
+
+func (r *oneByteReader) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	return r.r.Read(p[:1])
+}
@@ -0,0 +1,100 @@
+package xhttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/monaco-io/lib/typing/xec"
+	"github.com/monaco-io/lib/typing/xopt"
+)
+
+// StatusError 非成功响应，Body 为解码后的错误响应体
+// 可通过 errors.As 转换为 xec.Error，Code 为 HTTP 状态码
+type StatusError[E any] struct {
+	Code   int
+	Header http.Header
+	Body   E
+	// Raw 原始响应体，Body 解码失败时可用于排查
+	Raw []byte
+	// DecodeErr 错误响应体解码失败的原因
+	DecodeErr error
+}
+
+func (e *StatusError[E]) Error() string {
+	raw := e.Raw
+	if len(raw) > 256 {
+		raw = raw[:256]
+	}
+	return fmt.Sprintf("%s: %d %s response.Body=%s", ErrUnexpectedStatus, e.Code, http.StatusText(e.Code), raw)
+}
+
+// Is 匹配 ErrUnexpectedStatus
+func (e *StatusError[E]) Is(target error) bool {
+	return target == ErrUnexpectedStatus
+}
+
+func (e *StatusError[E]) Unwrap() error {
+	return e.XEC()
+}
+
+// XEC 转换为 xec.Error，Code 为 HTTP 状态码
+func (e *StatusError[E]) XEC() xec.Error {
+	err := xec.New(e.Code, http.StatusText(e.Code))
+	if e.DecodeErr != nil {
+		return err.Wrap(e.DecodeErr)
+	}
+	return err
+}
+
+// SuccessWhen 设置 SugarE 判断成功响应的条件，默认为 2xx
+func SuccessWhen(f func(code int) bool) xopt.Option[Request] {
+	return func(request *Request) {
+		request.success = f
+	}
+}
+
+func isSuccess(code int) bool {
+	return code >= 200 && code < 300
+}
+
+// SugarE 使用 DefaultClient 发起请求，成功响应解码为 T，其余解码为 E 并返回 *StatusError[E]
+func SugarE[T, E any](ctx context.Context, url string, opts ...xopt.Option[Request]) (*Response[T], error) {
+	return ClientSugarE[T, E](DefaultClient, ctx, url, opts...)
+}
+
+// ClientSugarE 使用指定客户端发起请求，成功响应解码为 T，其余解码为 E 并返回 *StatusError[E]
+//
+//	resp, err := xhttp.SugarE[User, APIError](ctx, url)
+//	var se *xhttp.StatusError[APIError]
+//	if errors.As(err, &se) { ... se.Body.Message ... }
+func ClientSugarE[T, E any](c *XClient, ctx context.Context, url string, opts ...xopt.Option[Request]) (*Response[T], error) {
+	response, err := c.Do(ctx, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	success := response.success
+	if success == nil {
+		success = isSuccess
+	}
+	if !success(response.Code) {
+		se := &StatusError[E]{Code: response.Code, Header: response.RespHeader, Raw: response.Body}
+		if len(response.Body) > 0 {
+			se.Body, se.DecodeErr = decodeBody[E](response.decoder, response.RespHeader, response.Body)
+		}
+		return nil, se
+	}
+	result, err := decodeBody[T](response.decoder, response.RespHeader, response.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &Response[T]{Body: result, Code: response.Code, RespHeader: response.RespHeader, Request: response.Request, TraceResult: response.TraceResult}, nil
+}
+
+// AsStatusError 取出 err 中的 *StatusError[E]
+func AsStatusError[E any](err error) (*StatusError[E], bool) {
+	var se *StatusError[E]
+	ok := errors.As(err, &se)
+	return se, ok
+}
@@ -0,0 +1,115 @@
+package xhttp
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/monaco-io/lib/typing/xec"
+)
+
+type apiError struct {
+	Message string `json:"message" xml:"message" yaml:"message"`
+}
+
+func newStatusServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ContentType, "application/vnd.api+json; charset=utf-8")
+		_, _ = w.Write([]byte(`{"id":1,"name":"json"}`))
+	})
+	mux.HandleFunc("/xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ContentType, "text/xml")
+		_, _ = w.Write([]byte(`<TestUser><id>2</id><name>xml</name></TestUser>`))
+	})
+	mux.HandleFunc("/yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ContentType, "application/yaml")
+		_, _ = w.Write([]byte("id: 3\nname: yaml\n"))
+	})
+	mux.HandleFunc("/notfound", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ContentType, ContentTypeJSON)
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"user not found"}`))
+	})
+	mux.HandleFunc("/html", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(ContentType, ContentTypeHTML)
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte(`<html>oops</html>`))
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestSugarAutoDecoder(t *testing.T) {
+	server := newStatusServer()
+	defer server.Close()
+	ctx := context.Background()
+
+	for path, want := range map[string]TestUser{
+		"/ok":   {ID: 1, Name: "json"},
+		"/xml":  {ID: 2, Name: "xml"},
+		"/yaml": {ID: 3, Name: "yaml"},
+	} {
+		resp, err := Sugar[TestUser](ctx, server.URL+path)
+		if err != nil {
+			t.Fatalf("Sugar(%s) error = %v", path, err)
+		}
+		if resp.Body != want {
+			t.Errorf("Sugar(%s) = %+v, want %+v", path, resp.Body, want)
+		}
+	}
+
+	text, err := Sugar[string](ctx, server.URL+"/html")
+	if err != nil || text.Body != "<html>oops</html>" {
+		t.Errorf("Sugar[string] = %v, %v", text, err)
+	}
+	// 显式指定的解码方式优先
+	if _, err := Sugar[TestUser](ctx, server.URL+"/yaml", DecoderJSON()); err == nil {
+		t.Error("Sugar() with DecoderJSON expected decode error")
+	}
+}
+
+func TestSugarE(t *testing.T) {
+	server := newStatusServer()
+	defer server.Close()
+	ctx := context.Background()
+
+	resp, err := SugarE[TestUser, apiError](ctx, server.URL+"/ok")
+	if err != nil || resp.Body.Name != "json" {
+		t.Fatalf("SugarE() = %v, %v", resp, err)
+	}
+
+	_, err = SugarE[TestUser, apiError](ctx, server.URL+"/notfound")
+	se, ok := AsStatusError[apiError](err)
+	if !ok {
+		t.Fatalf("SugarE() error = %v, want *StatusError", err)
+	}
+	if se.Code != http.StatusNotFound || se.Body.Message != "user not found" || se.DecodeErr != nil {
+		t.Errorf("StatusError = %+v", se)
+	}
+	if !errors.Is(err, ErrUnexpectedStatus) || !errors.Is(err, xec.New(http.StatusNotFound)) {
+		t.Errorf("errors.Is(%v) failed", err)
+	}
+	var xe xec.Error
+	if !errors.As(err, &xe) || xe.Code != http.StatusNotFound {
+		t.Errorf("errors.As(xec.Error) = %v", xe)
+	}
+
+	// HTML 错误页无法解码为 E 时保留原始响应体
+	_, err = SugarE[TestUser, apiError](ctx, server.URL+"/html")
+	se, ok = AsStatusError[apiError](err)
+	if !ok || se.Code != http.StatusInternalServerError || se.DecodeErr == nil || string(se.Raw) != "<html>oops</html>" {
+		t.Errorf("SugarE(/html) error = %v", err)
+	}
+	if _, ok := AsStatusError[string](err); ok {
+		t.Error("AsStatusError[string] should not match *StatusError[apiError]")
+	}
+
+	// 自定义成功条件
+	resp, err = SugarE[TestUser, apiError](ctx, server.URL+"/notfound",
+		SuccessWhen(func(code int) bool { return code < 500 }))
+	if err != nil || resp.Code != http.StatusNotFound {
+		t.Errorf("SugarE() with SuccessWhen = %v, %v", resp, err)
+	}
+}
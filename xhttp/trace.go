@@ -5,6 +5,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net/http/httptrace"
+	"sync"
 	"time"
 
 	. "github.com/monaco-io/lib/typing"
@@ -15,12 +16,15 @@ type contextTraceResultKey struct{}
 
 // TraceResult 存储追踪结果
 type TraceResult struct {
+	// TotalDuration 从发起请求到收到响应头的耗时，不包含读取响应体，Do、Sugar 与 DoStream 相同
 	TotalDuration   Counter[time.Duration] `json:"total_duration"`
 	DNSDuration     Counter[time.Duration] `json:"dns_duration"`
 	ConnectDuration Counter[time.Duration] `json:"connect_duration"`
 	TlsDuration     Counter[time.Duration] `json:"tls_duration"`
 	FirstByteDelay  Counter[time.Duration] `json:"first_byte_delay"`
+	Attempts        int                    `json:"attempts"`
 
+	mu             sync.Mutex
 	startTime      time.Time `json:"-"`
 	dnsStart       time.Time `json:"-"`
 	connectStart   time.Time `json:"-"`
@@ -30,6 +34,8 @@ type TraceResult struct {
 
 func (tr *TraceResult) FormatCounter() {
 	if tr != nil {
+		tr.mu.Lock()
+		defer tr.mu.Unlock()
 		tr.TotalDuration.Label = fmt.Sprintf("%dms", tr.TotalDuration.Value.Milliseconds())
 		tr.DNSDuration.Label = fmt.Sprintf("%dms", tr.DNSDuration.Value.Milliseconds())
 		tr.ConnectDuration.Label = fmt.Sprintf("%dms", tr.ConnectDuration.Value.Milliseconds())
@@ -38,6 +44,13 @@ func (tr *TraceResult) FormatCounter() {
 	}
 }
 
+// update 追踪回调可能在连接池的拨号协程中执行，需要加锁
+func (tr *TraceResult) update(f func()) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	f()
+}
+
 func withTraceContext(ctx context.Context) context.Context {
 	if ctx == nil {
 		ctx = context.Background()
@@ -53,17 +66,17 @@ func withTraceContext(ctx context.Context) context.Context {
 	trace := httptrace.ClientTrace{
 		// DNS解析开始
 		DNSStart: func(info httptrace.DNSStartInfo) {
-			tr.dnsStart = time.Now()
+			tr.update(func() { tr.dnsStart = time.Now() })
 		},
 
 		// DNS解析完成
 		DNSDone: func(info httptrace.DNSDoneInfo) {
-			tr.DNSDuration = Counter[time.Duration]{Value: time.Since(tr.dnsStart)}
+			tr.update(func() { tr.DNSDuration = Counter[time.Duration]{Value: time.Since(tr.dnsStart)} })
 		},
 
 		// 连接开始
 		ConnectStart: func(network, addr string) {
-			tr.connectStart = time.Now()
+			tr.update(func() { tr.connectStart = time.Now() })
 		},
 
 		// 连接完成
@@ -71,12 +84,12 @@ func withTraceContext(ctx context.Context) context.Context {
 			if err != nil {
 				return
 			}
-			tr.ConnectDuration = Counter[time.Duration]{Value: time.Since(tr.connectStart)}
+			tr.update(func() { tr.ConnectDuration = Counter[time.Duration]{Value: time.Since(tr.connectStart)} })
 		},
 
 		// TLS握手开始
 		TLSHandshakeStart: func() {
-			tr.tlsStart = time.Now()
+			tr.update(func() { tr.tlsStart = time.Now() })
 		},
 
 		// TLS握手完成
@@ -84,17 +97,17 @@ func withTraceContext(ctx context.Context) context.Context {
 			if err != nil {
 				return
 			}
-			tr.TlsDuration = Counter[time.Duration]{Value: time.Since(tr.tlsStart)}
+			tr.update(func() { tr.TlsDuration = Counter[time.Duration]{Value: time.Since(tr.tlsStart)} })
 		},
 
 		// 准备发送请求
 		WroteRequest: func(info httptrace.WroteRequestInfo) {
-			tr.firstByteStart = time.Now()
+			tr.update(func() { tr.firstByteStart = time.Now() })
 		},
 
 		// 收到第一个响应字节
 		GotFirstResponseByte: func() {
-			tr.FirstByteDelay = Counter[time.Duration]{Value: time.Since(tr.firstByteStart)}
+			tr.update(func() { tr.FirstByteDelay = Counter[time.Duration]{Value: time.Since(tr.firstByteStart)} })
 		},
 	}
 
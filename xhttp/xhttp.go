@@ -5,6 +5,10 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	. "github.com/monaco-io/lib/typing"
@@ -19,10 +23,16 @@ type requestid string
 
 const requestID requestid = "x-request-id"
 
+// ErrBodyTooLarge 响应体超过 MaxBodySize
+var ErrBodyTooLarge = errors.New("lib.xhttp:response body too large")
+
 type Response[T any] struct {
 	Body T   `json:"body" xml:"body" yaml:"body"`
 	Code int `json:"code" xml:"code" yaml:"code"`
 
+	// RespHeader 响应头，Header 为嵌入的 *Request 的请求头
+	RespHeader http.Header `json:"header,omitempty" xml:"-" yaml:"header,omitempty"`
+
 	*Request     `json:"-" xml:"-" yaml:"-"`
 	*TraceResult `json:"trace_result" xml:"trace_result" yaml:"trace_result"`
 }
@@ -31,9 +41,41 @@ func (r *Response[T]) PrettyString() string {
 	return xjson.MarshalIndentStringX(r)
 }
 
+// Do 使用 DefaultClient 发起请求
 func Do(ctx context.Context, url string, opts ...xopt.Option[Request]) (*Response[[]byte], error) {
-	ctx = withTraceContext(ctx)
-	xrequest, err := build(ctx, url, opts...)
+	return DefaultClient.Do(ctx, url, opts...)
+}
+
+// Do 发起请求，url 不含 scheme 时拼接在 BaseURL 之后
+// 与之前一样，TraceResult.TotalDuration 在收到响应头时记录，不包含读取响应体的耗时
+func (c *XClient) Do(ctx context.Context, url string, opts ...xopt.Option[Request]) (*Response[[]byte], error) {
+	response, err := c.DoStream(ctx, url, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = response.Body.Close() }()
+	body, err := readBody(response.Body, response.RespHeader, response.maxBodySize)
+	if err != nil {
+		return nil, err
+	}
+	return &Response[[]byte]{
+		Body:        body,
+		Code:        response.Code,
+		RespHeader:  response.RespHeader,
+		Request:     response.Request,
+		TraceResult: response.TraceResult,
+	}, nil
+}
+
+// DoStream 使用 DefaultClient 发起请求，不读取响应体
+func DoStream(ctx context.Context, url string, opts ...xopt.Option[Request]) (*Response[io.ReadCloser], error) {
+	return DefaultClient.DoStream(ctx, url, opts...)
+}
+
+// DoStream 发起请求，响应体由调用方读取并关闭
+// TraceResult.TotalDuration 为收到响应头的耗时
+func (c *XClient) DoStream(ctx context.Context, url string, opts ...xopt.Option[Request]) (*Response[io.ReadCloser], error) {
+	xrequest, err := c.build(ctx, url, opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -41,63 +83,119 @@ func Do(ctx context.Context, url string, opts ...xopt.Option[Request]) (*Respons
 	if err != nil {
 		return nil, err
 	}
-	tr, ok := ctx.Value(contextTraceResultKey{}).(*TraceResult)
+	tr, ok := xrequest.Context().Value(contextTraceResultKey{}).(*TraceResult)
 	if ok && tr != nil {
-		tr.TotalDuration = Counter[time.Duration]{Value: time.Since(tr.startTime)}
-		defer tr.FormatCounter()
+		tr.update(func() {
+			tr.TotalDuration = Counter[time.Duration]{Value: time.Since(tr.startTime)}
+			tr.Attempts = xrequest.attempts
+		})
+		tr.FormatCounter()
 	}
 	// trace.
 	if response == nil {
 		return nil, errors.New("response is nil")
 	}
-	body, err := io.ReadAll(response.Body)
-	if err != nil {
-		return nil, err
+	body := response.Body
+	if body == nil {
+		body = http.NoBody
 	}
-	if response.Body != nil {
-		defer func() { _ = response.Body.Close() }()
-	}
-
-	return &Response[[]byte]{
+	return &Response[io.ReadCloser]{
 		Body:        body,
 		Code:        response.StatusCode,
+		RespHeader:  response.Header,
 		Request:     xrequest,
 		TraceResult: tr,
 	}, nil
 }
 
+// readBody 读取响应体，超过 maxSize 时返回 ErrBodyTooLarge，<=0 表示不限制
+func readBody(body io.Reader, header http.Header, maxSize int64) ([]byte, error) {
+	if maxSize <= 0 {
+		return io.ReadAll(body)
+	}
+	if contentLength, err := strconv.ParseInt(header.Get(ContentLength), 10, 64); err == nil && contentLength > maxSize {
+		return nil, fmt.Errorf("%w: content-length %d exceeds %d", ErrBodyTooLarge, contentLength, maxSize)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w: exceeds %d bytes", ErrBodyTooLarge, maxSize)
+	}
+	return data, nil
+}
+
+// Sugar 使用 DefaultClient 发起请求并解码响应体
 func Sugar[T any](ctx context.Context, url string, opts ...xopt.Option[Request]) (*Response[T], error) {
-	response, err := Do(ctx, url, opts...)
+	return ClientSugar[T](DefaultClient, ctx, url, opts...)
+}
+
+// ClientSugar 使用指定客户端发起请求并解码响应体
+func ClientSugar[T any](c *XClient, ctx context.Context, url string, opts ...xopt.Option[Request]) (*Response[T], error) {
+	response, err := c.Do(ctx, url, opts...)
 	if err != nil {
 		return nil, err
 	}
+	result, err := decodeBody[T](response.decoder, response.RespHeader, response.Body)
+	if err != nil {
+		return nil, err
+	}
+	return &Response[T]{Body: result, Code: response.Code, RespHeader: response.RespHeader, Request: response.Request, TraceResult: response.TraceResult}, nil
+}
+
+// decodeBody 按 decoder 解码响应体，decoderAuto 时根据 Content-Type 选择
+func decodeBody[T any](d decoder, header http.Header, body []byte) (T, error) {
 	var result T
-	switch response.decoder {
+	if d == decoderAuto {
+		d = detectDecoder[T](header.Get(ContentType))
+	}
+	switch d {
 	case decoderJSON:
-		if err := xjson.Unmarshal(response.Body, &result); err != nil {
-			return nil, fmt.Errorf("Sugar.Decode: failed to decode JSON: %w response.Body=%s", err, response.Body)
+		if err := xjson.Unmarshal(body, &result); err != nil {
+			return result, fmt.Errorf("Sugar.Decode: failed to decode JSON: %w response.Body=%s", err, body)
 		}
 	case decoderXML:
-		if err := xxml.Unmarshal(response.Body, &result); err != nil {
-			return nil, fmt.Errorf("Sugar.Decode: failed to decode XML: %w response.Body=%s", err, response.Body)
+		if err := xxml.Unmarshal(body, &result); err != nil {
+			return result, fmt.Errorf("Sugar.Decode: failed to decode XML: %w response.Body=%s", err, body)
 		}
 	case decoderYAML:
-		if err := xyaml.Unmarshal(response.Body, &result); err != nil {
-			return nil, fmt.Errorf("Sugar.Decode: failed to decode YAML: %w response.Body=%s", err, response.Body)
+		if err := xyaml.Unmarshal(body, &result); err != nil {
+			return result, fmt.Errorf("Sugar.Decode: failed to decode YAML: %w response.Body=%s", err, body)
 		}
 	case decoderText:
 		switch any(result).(type) {
 		case string:
-			result = any(string(response.Body)).(T)
+			result = any(string(body)).(T)
 		case *string:
-			result = any(string(response.Body)).(T)
+			text := string(body)
+			result = any(&text).(T)
 		case []byte:
-			result = any(response.Body).(T)
+			result = any(body).(T)
 		case *[]byte:
-			result = any(response.Body).(T)
+			result = any(&body).(T)
 		}
 	default:
-		return nil, fmt.Errorf("Sugar.Decode: unsupported type=%T, response.Body=%s", result, response.Body)
+		return result, fmt.Errorf("Sugar.Decode: unsupported type=%T, response.Body=%s", result, body)
+	}
+	return result, nil
+}
+
+// detectDecoder 根据 Content-Type 选择解码方式，无法识别时 string、[]byte 使用文本，其余使用 JSON
+func detectDecoder[T any](contentType string) decoder {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == ContentTypeJSON || strings.HasSuffix(mediaType, "+json"):
+		return decoderJSON
+	case mediaType == ContentTypeXML || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return decoderXML
+	case mediaType == ContentTypeYAML || mediaType == "application/yaml" || mediaType == "text/yaml" ||
+		mediaType == "text/x-yaml" || strings.HasSuffix(mediaType, "+yaml"):
+		return decoderYAML
+	}
+	switch any(*new(T)).(type) {
+	case string, *string, []byte, *[]byte:
+		return decoderText
 	}
-	return &Response[T]{Body: result, Code: response.Code, Request: response.Request, TraceResult: response.TraceResult}, nil
+	return decoderJSON
 }